@@ -0,0 +1,276 @@
+package gojsonschema
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonreference"
+)
+
+// BundleOptions configures SchemaLoader.Bundle
+type BundleOptions struct {
+	// Definitions is the keyword of the root schema the referenced documents are added to,
+	// "definitions" by default. Use "$defs" for consumers of newer drafts
+	Definitions string
+}
+
+// Bundle returns root as a single self-contained schema document, see SchemaLoader.Bundle
+func Bundle(root JSONLoader, options BundleOptions) (interface{}, error) {
+	return NewSchemaLoader().Bundle(root, options)
+}
+
+// Bundle compiles root, and returns it as a single self-contained schema document. Every document
+// reachable with $ref is added to the definitions of the root, named after its file name, and every
+// $ref is rewritten to a JSON Pointer into the bundle. References are resolved like Compile resolves them,
+// in the scope of their $id, after which the $ids of embedded schemas are removed as they would change
+// the scope of the rewritten references. The $id of the root is kept.
+//
+// The bundle validates the same documents as root. A referenced document written for another draft
+// than root can't be bundled
+func (sl *SchemaLoader) Bundle(root JSONLoader, options BundleOptions) (interface{}, error) {
+	if options.Definitions == "" {
+		options.Definitions = KEY_DEFINITIONS
+	}
+
+	schema, err := sl.Compile(root)
+	if err != nil {
+		return nil, err
+	}
+	spd, err := schema.pool.GetDocument(schema.documentReference, schema.loaderFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	document := copyDocument(spd.Document)
+	rootMap, ok := document.(map[string]interface{})
+	if !ok {
+		// a boolean schema has no references
+		return document, nil
+	}
+
+	draft := sl.Draft
+	if spd.Draft != nil {
+		draft = *spd.Draft
+	}
+	b := &bundler{
+		schema:      schema,
+		draft:       draft,
+		root:        rootMap,
+		definitions: options.Definitions,
+		locations:   make(map[string]string),
+	}
+	b.locate(schema.documentReference.String(), "")
+	b.walk(rootMap, "", schema.documentReference, true)
+
+	// embedding a document may add more references
+	for i := 0; i < len(b.refs); i++ {
+		if _, ok := b.resolve(b.refs[i].ref); ok {
+			continue
+		}
+		if err := b.embed(b.refs[i].ref); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, r := range b.refs {
+		pointer, ok := b.resolve(r.ref)
+		if !ok {
+			return nil, fmt.Errorf("can't bundle the reference %s, it doesn't point to a schema", r.ref)
+		}
+		r.schema[KEY_REF] = "#" + (&url.URL{Fragment: pointer}).EscapedFragment()
+	}
+
+	// every reference of the bundle must point into it
+	check := &bundler{definitions: options.Definitions, locations: make(map[string]string)}
+	check.walk(rootMap, "", gojsonreference.JsonReference{}, true)
+	for _, r := range check.refs {
+		if ref, _ := r.schema[KEY_REF].(string); !strings.HasPrefix(ref, "#") {
+			return nil, fmt.Errorf("can't bundle the reference %s, it points outside the bundle", ref)
+		}
+	}
+	return rootMap, nil
+}
+
+// bundler collects the documents of a bundle and the references between them
+type bundler struct {
+	schema      *Schema
+	draft       Draft
+	root        map[string]interface{}
+	definitions string
+
+	// locations maps the references of the bundled documents, and of their subschemas with an $id,
+	// to the JSON Pointer of the schema in the bundle
+	locations map[string]string
+	refs      []bundleRef
+}
+
+// bundleRef is a schema with a $ref, already resolved to an absolute reference by the schema pool
+type bundleRef struct {
+	schema map[string]interface{}
+	ref    string
+}
+
+// locate records the location of the schema with the given reference, unless it is already bundled
+func (b *bundler) locate(reference string, pointer string) {
+	base, fragment := splitReference(reference)
+	if fragment != "" {
+		base += "#" + fragment
+	}
+	if _, ok := b.locations[base]; !ok {
+		b.locations[base] = pointer
+	}
+}
+
+// resolve returns the JSON Pointer in the bundle of the schema ref points to
+func (b *bundler) resolve(ref string) (string, bool) {
+	base, fragment := splitReference(ref)
+	if fragment != "" && !strings.HasPrefix(fragment, "/") {
+		// a plain name fragment, declared with an $id
+		pointer, ok := b.locations[base+"#"+fragment]
+		return pointer, ok
+	}
+	pointer, ok := b.locations[base]
+	return pointer + fragment, ok
+}
+
+// splitReference returns a reference without its fragment, and the fragment
+func splitReference(reference string) (string, string) {
+	u, err := url.Parse(reference)
+	if err != nil {
+		return reference, ""
+	}
+	fragment := u.Fragment
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String(), fragment
+}
+
+// walk finds the $ids and $refs of a schema at pointer, in the scope of base, like schemaPool.parseReferencesRecursive
+func (b *bundler) walk(document interface{}, pointer string, base gojsonreference.JsonReference, root bool) {
+	switch v := document.(type) {
+	case []interface{}:
+		for i, item := range v {
+			b.walk(item, pointer+"/"+strconv.Itoa(i), base, false)
+		}
+
+	case map[string]interface{}:
+		keyID := KEY_ID_NEW
+		if existsMapKey(v, KEY_ID) {
+			keyID = KEY_ID
+		}
+		if id, ok := v[keyID].(string); ok {
+			if idRef, err := gojsonreference.NewJsonReference(id); err == nil {
+				if scoped, err := base.Inherits(idRef); err == nil {
+					base = *scoped
+					b.locate(base.String(), pointer)
+				}
+			}
+			if !root {
+				delete(v, keyID)
+			}
+		}
+
+		if ref, ok := v[KEY_REF].(string); ok {
+			// the schema pool leaves relative the references it doesn't walk into, resolve them in their scope
+			if refRef, err := gojsonreference.NewJsonReference(ref); err == nil {
+				if absolute, err := base.Inherits(refRef); err == nil {
+					ref = absolute.String()
+				}
+			}
+			b.refs = append(b.refs, bundleRef{schema: v, ref: ref})
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			// const, enum, default and examples are values, not schemas
+			if k == KEY_CONST || k == KEY_ENUM || k == KEY_DEFAULT || k == KEY_EXAMPLES {
+				continue
+			}
+			child := pointer + "/" + escapePointerToken(k)
+			// the names of these keywords are not keywords, a definition may well be named default
+			if k == KEY_DEFINITIONS || k == b.definitions || k == KEY_PROPERTIES || k == KEY_DEPENDENCIES || k == KEY_PATTERN_PROPERTIES {
+				if schemas, ok := v[k].(map[string]interface{}); ok {
+					names := make([]string, 0, len(schemas))
+					for name := range schemas {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+					for _, name := range names {
+						b.walk(schemas[name], child+"/"+escapePointerToken(name), base, false)
+					}
+					continue
+				}
+			}
+			b.walk(v[k], child, base, false)
+		}
+	}
+}
+
+// embed adds the document ref points to to the definitions of the bundle
+func (b *bundler) embed(ref string) error {
+	address, _ := splitReference(ref)
+	reference, err := gojsonreference.NewJsonReference(address)
+	if err != nil {
+		return err
+	}
+	spd, err := b.schema.pool.GetDocument(reference, b.schema.loaderFactory)
+	if err != nil {
+		return err
+	}
+	if spd.Draft != nil && b.draft != Hybrid && *spd.Draft != b.draft {
+		return fmt.Errorf("can't bundle %s, it is written for draft %d while the root schema is draft %d", address, *spd.Draft, b.draft)
+	}
+
+	definitions, ok := b.root[b.definitions].(map[string]interface{})
+	if !ok {
+		if existsMapKey(b.root, b.definitions) {
+			return fmt.Errorf("can't bundle %s, %s of the root schema is not an object", address, b.definitions)
+		}
+		definitions = make(map[string]interface{})
+		b.root[b.definitions] = definitions
+	}
+
+	name := bundleName(reference)
+	for i := 2; existsMapKey(definitions, name); i++ {
+		name = bundleName(reference) + strconv.Itoa(i)
+	}
+
+	document := copyDocument(spd.Document)
+	definitions[name] = document
+	pointer := "/" + escapePointerToken(b.definitions) + "/" + escapePointerToken(name)
+	b.locate(address, pointer)
+	b.walk(document, pointer, reference, false)
+	return nil
+}
+
+// bundleName names a bundled document after its file name without extension, e.g. "address" for .../address.json
+func bundleName(reference gojsonreference.JsonReference) string {
+	u := reference.GetUrl()
+	name := path.Base(u.Path)
+	name = strings.TrimSuffix(name, path.Ext(name))
+
+	var sb strings.Builder
+	for _, r := range name {
+		if r == '-' || r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() == 0 {
+		return "schema"
+	}
+	return sb.String()
+}
+
+// escapePointerToken escapes a reference token of a JSON Pointer
+func escapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
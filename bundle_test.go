@@ -0,0 +1,170 @@
+package gojsonschema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSchemaFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.Nil(t, os.WriteFile(file, []byte(content), 0644))
+	}
+	return "file://" + filepath.ToSlash(dir) + "/"
+}
+
+func TestBundle(t *testing.T) {
+	base := writeSchemaFiles(t, map[string]string{
+		"root.json": `{
+			"definitions": {"address": {"type": "string"}},
+			"type": "object",
+			"properties": {
+				"home": {"$ref": "address.json"},
+				"work": {"$ref": "types/address.json#/definitions/street"},
+				"tree": {"$ref": "tree.json"},
+				"local": {"$ref": "#/definitions/address"},
+				"positive": {"$ref": "numbers.json#positive"},
+				"nested": {"$id": "nested/", "properties": {"item": {"$ref": "item.json"}}}
+			}
+		}`,
+		"address.json": `{
+			"type": "object",
+			"properties": {"zip": {"type": "string", "pattern": "^[0-9]{5}$"}, "street": {"$ref": "types/address.json#/definitions/street"}},
+			"required": ["zip"]
+		}`,
+		"types/address.json": `{"definitions": {"street": {"type": "string", "minLength": 3}}}`,
+		"tree.json":          `{"type": "object", "properties": {"value": {"type": "integer"}, "children": {"type": "array", "items": {"$ref": "#"}}}}`,
+		"numbers.json":       `{"definitions": {"positive": {"$id": "#positive", "type": "number", "minimum": 0}}}`,
+		"nested/item.json":   `{"type": "boolean"}`,
+	})
+
+	root := NewReferenceLoader(base + "root.json")
+	bundle, err := Bundle(root, BundleOptions{})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	b, err := json.Marshal(bundle)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(b), "file://")
+
+	document := bundle.(map[string]interface{})
+	definitions := document[KEY_DEFINITIONS].(map[string]interface{})
+	assert.ElementsMatch(t, []string{"address", "address2", "address3", "tree", "numbers", "item"}, mapKeys(definitions))
+
+	properties := document[KEY_PROPERTIES].(map[string]interface{})
+	ref := func(property string) interface{} {
+		return properties[property].(map[string]interface{})[KEY_REF]
+	}
+	assert.Equal(t, "#/definitions/address2", ref("home"))
+	assert.Equal(t, "#/definitions/address3/definitions/street", ref("work"))
+	assert.Equal(t, "#/definitions/tree", ref("tree"))
+	assert.Equal(t, "#/definitions/address", ref("local"))
+	assert.Equal(t, "#/definitions/numbers/definitions/positive", ref("positive"))
+	assert.Equal(t, "#/definitions/tree", definitions["tree"].(map[string]interface{})[KEY_PROPERTIES].(map[string]interface{})["children"].(map[string]interface{})[KEY_ITEMS].(map[string]interface{})[KEY_REF])
+	assert.NotContains(t, properties["nested"], KEY_ID_NEW)
+
+	original, err := NewSchema(root)
+	assert.Nil(t, err)
+	bundled, err := NewSchema(NewGoLoader(bundle))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	documents := []string{
+		`{"home": {"zip": "12345", "street": "Main"}, "work": "Elm", "local": "x", "positive": 1, "nested": {"item": true}}`,
+		`{"home": {"zip": "1234"}, "work": "E", "local": 1, "positive": -1, "nested": {"item": 1}}`,
+		`{"home": {"street": "M"}}`,
+		`{"tree": {"value": 1, "children": [{"value": 2, "children": [{"value": "3"}]}]}}`,
+	}
+	for _, d := range documents {
+		expected, err := original.Validate(NewStringLoader(d))
+		assert.Nil(t, err)
+		actual, err := bundled.Validate(NewStringLoader(d))
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, errorFields(expected), errorFields(actual), d)
+	}
+}
+
+func TestBundleOptions(t *testing.T) {
+	base := writeSchemaFiles(t, map[string]string{
+		"a.json":      `{"$id": "http://example.com/a.json", "items": {"$ref": "b.json"}}`,
+		"my b.json":   `{"type": "string"}`,
+		"draft4.json": `{"$schema": "http://json-schema.org/draft-04/schema#", "type": "string"}`,
+	})
+
+	// the $id of the root is kept and scopes its references
+	sl := NewSchemaLoader()
+	assert.Nil(t, sl.AddSchema("http://example.com/b.json", NewStringLoader(`{"type": "integer"}`)))
+	bundle, err := sl.Bundle(NewReferenceLoader(base+"a.json"), BundleOptions{Definitions: "$defs"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			KEY_ID_NEW: "http://example.com/a.json",
+			KEY_ITEMS:  map[string]interface{}{KEY_REF: "#/$defs/b"},
+			"$defs":    map[string]interface{}{"b": map[string]interface{}{KEY_TYPE: "integer"}},
+		}, bundle)
+	}
+
+	// names are escaped in the references
+	bundle, err = Bundle(NewStringLoader(`{"properties": {"a b": {"$ref": "`+base+`my%20b.json"}, "c": {"$ref": "#/properties/a%20b"}}}`), BundleOptions{})
+	if assert.Nil(t, err) {
+		b, _ := json.Marshal(bundle)
+		assert.Contains(t, string(b), `"$ref":"#/definitions/myb"`)
+		assert.Contains(t, string(b), `"$ref":"#/properties/a%20b"`)
+	}
+
+	_, err = Bundle(NewStringLoader(`{"$schema": "http://json-schema.org/draft-07/schema#", "$ref": "`+base+`draft4.json"}`), BundleOptions{})
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.HasSuffix(err.Error(), "is written for draft 4 while the root schema is draft 7"), err.Error())
+	}
+
+	bundle, err = Bundle(NewStringLoader(`true`), BundleOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, true, bundle)
+}
+
+// Definitions named like the keywords whose values aren't schemas are bundled like any other
+func TestBundleDefinitionNames(t *testing.T) {
+	base := writeSchemaFiles(t, map[string]string{
+		"a.json": `{"type": "string"}`,
+		"root.json": `{
+			"definitions": {
+				"default": {"$ref": "a.json"},
+				"examples": {"properties": {"const": {"$ref": "a.json"}}}
+			},
+			"properties": {"x": {"$ref": "#/definitions/default"}, "z": {"$ref": "#/definitions/examples"}}
+		}`,
+	})
+
+	bundle, err := Bundle(NewReferenceLoader(base+"root.json"), BundleOptions{})
+	if !assert.Nil(t, err) {
+		return
+	}
+	b, err := json.Marshal(bundle)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(b), "file://")
+	assert.Contains(t, string(b), `"default":{"$ref":"#/definitions/a"}`)
+	assert.Contains(t, string(b), `"examples":{"properties":{"const":{"$ref":"#/definitions/a"}}}`)
+
+	bundled, err := NewSchema(NewGoLoader(bundle))
+	if assert.Nil(t, err) {
+		result, err := bundled.Validate(NewStringLoader(`{"x": 1, "z": {"const": 1}}`))
+		assert.Nil(t, err)
+		assert.Len(t, result.Errors(), 2)
+	}
+}
+
+func mapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,487 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xeipuuv/gojsonreference"
+)
+
+// CBOR loader
+
+type cborLoader struct {
+	source []byte
+}
+
+func (l *cborLoader) JsonSource() interface{} {
+	return l.source
+}
+
+func (l *cborLoader) JsonReference() (gojsonreference.JsonReference, error) {
+	return gojsonreference.NewJsonReference("#")
+}
+
+func (l *cborLoader) LoaderFactory() JSONLoaderFactory {
+	return &DefaultJSONLoaderFactory{}
+}
+
+// NewCBORLoader creates a new JSONLoader, taking a single CBOR (RFC 8949) data item as source.
+// Values are decoded to those of the equivalent JSON:
+//
+//	integers, bignums (tags 2 and 3), decimal fractions (tag 4) and floats are exact json.Number
+//	byte strings are base64 strings, or base64url or base16 under tags 21 to 23, see contentEncoding
+//	date/time strings (tag 0) and epoch times (tag 1) are their content, as are URIs,
+//	base64 texts, regular expressions, MIME messages (tags 32 to 36) and self-described CBOR (tag 55799)
+//	maps must have text string keys
+//
+// Other tags, undefined, simple values and floats that aren't finite have no JSON equivalent and are a *CBORError.
+// Referenced files ending in .cbor, or served as application/cbor, are decoded as CBOR as well
+func NewCBORLoader(source []byte) JSONLoader {
+	return &cborLoader{source: source}
+}
+
+func (l *cborLoader) LoadJSON() (interface{}, error) {
+	return decodeCBOR(bytes.NewReader(l.source))
+}
+
+func (l *cborLoader) openJSON(ctx context.Context) (io.ReadCloser, error) {
+	return &documentReader{ReadCloser: ioutil.NopCloser(bytes.NewReader(l.source)), decode: decodeCBOR}, nil
+}
+
+// isCBORPath reports whether the file name or URL path name has the CBOR extension
+func isCBORPath(name string) bool {
+	return strings.ToLower(filepath.Ext(name)) == ".cbor"
+}
+
+// isCBORMediaType reports whether the Content-Type contentType is CBOR
+func isCBORMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/cbor" || strings.HasSuffix(mediaType, "+cbor"))
+}
+
+// CBORError is returned for CBOR data that can't be decoded, or that has no JSON equivalent
+type CBORError struct {
+	Offset  int
+	Message string
+}
+
+func (e *CBORError) Error() string {
+	return fmt.Sprintf("cbor: offset %d: %s", e.Offset, e.Message)
+}
+
+// binaryMaxDepth bounds the nesting of arrays and maps of binary formats, like encoding/json does
+const binaryMaxDepth = 10000
+
+// decodeCBOR decodes a CBOR data item like decodeJSONUsingNumber decodes JSON
+func decodeCBOR(r io.Reader) (interface{}, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &cborDecoder{src: b}
+	value, err := d.decodeItem()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.src) {
+		return nil, d.errorf(d.pos, "unexpected data after the item")
+	}
+	return value, nil
+}
+
+type cborDecoder struct {
+	src   []byte
+	pos   int
+	depth int
+
+	// encoding is the tag 21, 22 or 23 byte strings are written with, 0 for none
+	encoding uint64
+}
+
+func (d *cborDecoder) errorf(offset int, format string, args ...interface{}) error {
+	return &CBORError{Offset: offset, Message: fmt.Sprintf(format, args...)}
+}
+
+const (
+	cborUnsigned = iota
+	cborNegative
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+)
+
+// cborIndefinite is the additional information of items of indefinite length
+const cborIndefinite = 31
+
+// head reads the initial byte and the argument of an item
+func (d *cborDecoder) head() (major byte, info byte, argument uint64, err error) {
+	if d.pos >= len(d.src) {
+		return 0, 0, 0, d.errorf(d.pos, "unexpected end of data")
+	}
+	start := d.pos
+	major, info = d.src[d.pos]>>5, d.src[d.pos]&0x1f
+	d.pos++
+
+	switch {
+	case info < 24:
+		argument = uint64(info)
+	case info < 28:
+		n := 1 << (info - 24)
+		if d.pos+n > len(d.src) {
+			return 0, 0, 0, d.errorf(d.pos, "unexpected end of data")
+		}
+		for _, b := range d.src[d.pos : d.pos+n] {
+			argument = argument<<8 | uint64(b)
+		}
+		d.pos += n
+	case info == cborIndefinite:
+		if major == cborUnsigned || major == cborNegative || major == cborTag {
+			return 0, 0, 0, d.errorf(start, "invalid indefinite length item")
+		}
+	default:
+		return 0, 0, 0, d.errorf(start, "reserved additional information %d", info)
+	}
+	return major, info, argument, nil
+}
+
+// atBreak reports whether the current byte ends an item of indefinite length
+func (d *cborDecoder) atBreak() (bool, error) {
+	if d.pos >= len(d.src) {
+		return false, d.errorf(d.pos, "unexpected end of data")
+	}
+	if d.src[d.pos] == 0xff {
+		d.pos++
+		return true, nil
+	}
+	return false, nil
+}
+
+// checkLength rejects lengths longer than the rest of the data, before anything is allocated for them
+func (d *cborDecoder) checkLength(start int, length uint64) error {
+	if length > uint64(len(d.src)-d.pos) {
+		return d.errorf(start, "length %d is longer than the data", length)
+	}
+	return nil
+}
+
+func (d *cborDecoder) decodeItem() (interface{}, error) {
+	start := d.pos
+	major, info, argument, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborUnsigned:
+		return json.Number(strconv.FormatUint(argument, 10)), nil
+
+	case cborNegative:
+		n := new(big.Int).SetUint64(argument)
+		return json.Number(n.Not(n).String()), nil
+
+	case cborBytes:
+		b, err := d.readString(start, major, info, argument)
+		if err != nil {
+			return nil, err
+		}
+		switch d.encoding {
+		case 21:
+			return base64.RawURLEncoding.EncodeToString(b), nil
+		case 23:
+			return hex.EncodeToString(b), nil
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+
+	case cborText:
+		b, err := d.readString(start, major, info, argument)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, d.errorf(start, "invalid UTF-8 in a text string")
+		}
+		return string(b), nil
+
+	case cborArray, cborMap, cborTag:
+		d.depth++
+		defer func() { d.depth-- }()
+		if d.depth > binaryMaxDepth {
+			return nil, d.errorf(start, "exceeded max depth of %d", binaryMaxDepth)
+		}
+		switch major {
+		case cborArray:
+			return d.decodeArray(start, info, argument)
+		case cborMap:
+			return d.decodeMap(start, info, argument)
+		}
+		return d.decodeTag(start, argument)
+	}
+
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22:
+		return nil, nil
+	case 23:
+		return nil, d.errorf(start, "undefined has no JSON equivalent")
+	case 25:
+		return d.float(start, float16(uint16(argument)), 32)
+	case 26:
+		return d.float(start, float64(math.Float32frombits(uint32(argument))), 32)
+	case 27:
+		return d.float(start, math.Float64frombits(argument), 64)
+	case cborIndefinite:
+		return nil, d.errorf(start, "unexpected break")
+	}
+	return nil, d.errorf(start, "simple value %d has no JSON equivalent", argument)
+}
+
+// readString reads the content of a byte or text string, of indefinite length as well
+func (d *cborDecoder) readString(start int, major byte, info byte, length uint64) ([]byte, error) {
+	if info != cborIndefinite {
+		if err := d.checkLength(start, length); err != nil {
+			return nil, err
+		}
+		b := d.src[d.pos : d.pos+int(length)]
+		d.pos += int(length)
+		return b, nil
+	}
+
+	var b []byte
+	for {
+		if end, err := d.atBreak(); err != nil || end {
+			return b, err
+		}
+		chunkStart := d.pos
+		chunkMajor, chunkInfo, chunkLength, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == cborIndefinite {
+			return nil, d.errorf(chunkStart, "invalid chunk in a string of indefinite length")
+		}
+		chunk, err := d.readString(chunkStart, major, chunkInfo, chunkLength)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+}
+
+func (d *cborDecoder) decodeArray(start int, info byte, length uint64) (interface{}, error) {
+	if info == cborIndefinite {
+		array := []interface{}{}
+		for {
+			if end, err := d.atBreak(); err != nil || end {
+				return array, err
+			}
+			item, err := d.decodeItem()
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, item)
+		}
+	}
+
+	// every item is at least a byte
+	if err := d.checkLength(start, length); err != nil {
+		return nil, err
+	}
+	array := make([]interface{}, 0, length)
+	for i := uint64(0); i < length; i++ {
+		item, err := d.decodeItem()
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, item)
+	}
+	return array, nil
+}
+
+func (d *cborDecoder) decodeMap(start int, info byte, length uint64) (interface{}, error) {
+	// every pair is at least two bytes
+	if info != cborIndefinite && length > uint64(len(d.src)-d.pos)/2 {
+		return nil, d.errorf(start, "length %d is longer than the data", length)
+	}
+
+	object := map[string]interface{}{}
+	for i := uint64(0); info == cborIndefinite || i < length; i++ {
+		if info == cborIndefinite {
+			if end, err := d.atBreak(); err != nil || end {
+				return object, err
+			}
+		}
+
+		keyStart := d.pos
+		if d.pos < len(d.src) && d.src[d.pos]>>5 != cborText {
+			return nil, d.errorf(keyStart, "map keys must be text strings")
+		}
+		key, err := d.decodeItem()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := object[key.(string)]; ok {
+			return nil, d.errorf(keyStart, "duplicate map key %q", key)
+		}
+		value, err := d.decodeItem()
+		if err != nil {
+			return nil, err
+		}
+		object[key.(string)] = value
+	}
+	return object, nil
+}
+
+func (d *cborDecoder) decodeTag(start int, tag uint64) (interface{}, error) {
+	contentStart := d.pos
+	switch tag {
+	case 0, 32, 33, 34, 35, 36:
+		content, err := d.decodeItem()
+		if _, ok := content.(string); err == nil && !ok {
+			return nil, d.errorf(contentStart, "the content of tag %d must be a text string", tag)
+		}
+		return content, err
+
+	case 1:
+		content, err := d.decodeItem()
+		if _, ok := content.(json.Number); err == nil && !ok {
+			return nil, d.errorf(contentStart, "the content of tag 1 must be a number")
+		}
+		return content, err
+
+	case 2, 3:
+		n, err := d.decodeBignum(tag)
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(n.String()), nil
+
+	case 4:
+		return d.decodeDecimalFraction()
+
+	case 21, 22, 23:
+		encoding := d.encoding
+		d.encoding = tag
+		defer func() { d.encoding = encoding }()
+		return d.decodeItem()
+
+	case 55799:
+		return d.decodeItem()
+	}
+	return nil, d.errorf(start, "tag %d has no JSON equivalent", tag)
+}
+
+// decodeBignum decodes the byte string content of a tag 2 or 3 bignum
+func (d *cborDecoder) decodeBignum(tag uint64) (*big.Int, error) {
+	start := d.pos
+	major, info, length, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if major != cborBytes {
+		return nil, d.errorf(start, "the content of tag %d must be a byte string", tag)
+	}
+	b, err := d.readString(start, major, info, length)
+	if err != nil {
+		return nil, err
+	}
+	n := new(big.Int).SetBytes(b)
+	if tag == 3 {
+		n.Not(n)
+	}
+	return n, nil
+}
+
+// decodeDecimalFraction decodes the content of a tag 4 decimal fraction, [exponent, mantissa]
+func (d *cborDecoder) decodeDecimalFraction() (interface{}, error) {
+	start := d.pos
+	major, info, length, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if major != cborArray || info == cborIndefinite || length != 2 {
+		return nil, d.errorf(start, "the content of tag 4 must be an array of two integers")
+	}
+
+	exponentStart := d.pos
+	exponent, err := d.decodeItem()
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := exponent.(json.Number); !ok || !yamlInteger.MatchString(string(e)) {
+		return nil, d.errorf(exponentStart, "the exponent of a decimal fraction must be an integer")
+	}
+
+	mantissaStart := d.pos
+	mantissa, err := d.decodeItem()
+	if err != nil {
+		return nil, err
+	}
+	m, ok := mantissa.(json.Number)
+	if !ok || !yamlInteger.MatchString(string(m)) {
+		return nil, d.errorf(mantissaStart, "the mantissa of a decimal fraction must be an integer")
+	}
+	return json.Number(string(m) + "e" + string(exponent.(json.Number))), nil
+}
+
+// float returns a json.Number of f, written as encoding/json writes a float of the given bits
+func (d *cborDecoder) float(start int, f float64, bits int) (interface{}, error) {
+	n, err := binaryFloat(f, bits)
+	if err != nil {
+		return nil, d.errorf(start, "%s", err.Error())
+	}
+	return n, nil
+}
+
+// binaryFloat returns f as a json.Number, written as encoding/json writes a float of the given bits
+func binaryFloat(f float64, bits int) (json.Number, error) {
+	var v interface{} = f
+	if bits == 32 {
+		v = float32(f)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("%s has no JSON equivalent", strconv.FormatFloat(f, 'g', -1, bits))
+	}
+	return json.Number(b), nil
+}
+
+// float16 decodes an IEEE 754 half precision float
+func float16(h uint16) float64 {
+	exponent, mantissa := int(h>>10&0x1f), float64(h&0x3ff)
+	var f float64
+	switch exponent {
+	case 0:
+		f = math.Ldexp(mantissa, -24)
+	case 0x1f:
+		if mantissa == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mantissa+0x400, exponent-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
@@ -0,0 +1,186 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	// examples of RFC 8949 Appendix A, among others
+	cases := []struct {
+		cbor string
+		json string
+	}{
+		{"00", `0`},
+		{"17", `23`},
+		{"1818", `24`},
+		{"1b000000e8d4a51000", `1000000000000`},
+		{"1bffffffffffffffff", `18446744073709551615`},
+		{"c249010000000000000000", `18446744073709551616`},
+		{"3bffffffffffffffff", `-18446744073709551616`},
+		{"c349010000000000000000", `-18446744073709551617`},
+		{"20", `-1`},
+		{"3903e7", `-1000`},
+		{"f90000", `0`},
+		{"f93c00", `1`},
+		{"f93e00", `1.5`},
+		{"f97bff", `65504`},
+		{"f90001", `5.9604645e-8`},
+		{"fa47c35000", `100000`},
+		{"fb3ff199999999999a", `1.1`},
+		{"fbc010666666666666", `-4.1`},
+		// floats are written as encoding/json writes a float32 or a float64
+		{"fa3dcccccd", `0.1`},
+		{"fb444b1ae4d6e2ef50", `1e+21`},
+		{"fb3e7ad7f29abcaf48", `1e-7`},
+		{"c482211903e7", `999e-2`},
+		{"f4", `false`},
+		{"f5", `true`},
+		{"f6", `null`},
+		{"4401020304", `"AQIDBA=="`},
+		{"d54401020304", `"AQIDBA"`},
+		{"d74401020304", `"01020304"`},
+		{"c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+		{"c11a514b67b0", `1363896240`},
+		{"d82076687474703a2f2f7777772e6578616d706c652e636f6d", `"http://www.example.com"`},
+		{"d9d9f7f5", `true`},
+		{"6449455446", `"IETF"`},
+		{"62c3bc", `"ü"`},
+		{"64f0908591", `"𐅑"`},
+		{"83010203", `[1, 2, 3]`},
+		{"8301820203820405", `[1, [2, 3], [4, 5]]`},
+		{"a26161016162820203", `{"a": 1, "b": [2, 3]}`},
+		{"5f42010243030405ff", `"AQIDBAU="`},
+		{"7f657374726561646d696e67ff", `"streaming"`},
+		{"9f018202039f0405ffff", `[1, [2, 3], [4, 5]]`},
+		{"bf61610161629f0203ffff", `{"a": 1, "b": [2, 3]}`},
+		{"80", `[]`},
+		{"a0", `{}`},
+	}
+
+	for _, c := range cases {
+		expected, err := decodeJSONUsingNumber(strings.NewReader(c.json))
+		if !assert.Nil(t, err, c.json) {
+			continue
+		}
+		b, err := hex.DecodeString(c.cbor)
+		assert.Nil(t, err)
+		actual, err := decodeCBOR(bytes.NewReader(b))
+		if assert.Nil(t, err, c.cbor) {
+			assert.Equal(t, expected, actual, c.cbor)
+		}
+	}
+}
+
+func TestDecodeCBORErrors(t *testing.T) {
+	cases := []struct {
+		cbor    string
+		message string
+	}{
+		{"", "offset 0: unexpected end of data"},
+		{"1a0102", "offset 1: unexpected end of data"},
+		{"0000", "offset 1: unexpected data after the item"},
+		{"f7", "undefined has no JSON equivalent"},
+		{"f0", "simple value 16 has no JSON equivalent"},
+		{"f97c00", "+Inf has no JSON equivalent"},
+		{"fb7ff8000000000000", "NaN has no JSON equivalent"},
+		{"c6f6", "tag 6 has no JSON equivalent"},
+		{"c001", "the content of tag 0 must be a text string"},
+		{"d8184401020304", "tag 24 has no JSON equivalent"},
+		{"c16161", "the content of tag 1 must be a number"},
+		{"c201", "the content of tag 2 must be a byte string"},
+		{"c48201f6", "the mantissa of a decimal fraction must be an integer"},
+		{"62c328", "invalid UTF-8 in a text string"},
+		{"a1010203", "offset 1: map keys must be text strings"},
+		{"a261610161610102", "offset 4: duplicate map key \"a\""},
+		{"9bffffffffffffffff", "length 18446744073709551615 is longer than the data"},
+		{"bb7fffffffffffffff", "length 9223372036854775807 is longer than the data"},
+		{"5f01ff", "invalid chunk in a string of indefinite length"},
+		{"1f", "invalid indefinite length item"},
+		{"1c", "reserved additional information 28"},
+		{"ff", "unexpected break"},
+		{"9f01", "unexpected end of data"},
+		{strings.Repeat("81", 10001) + "00", "exceeded max depth of 10000"},
+	}
+
+	for _, c := range cases {
+		b, err := hex.DecodeString(c.cbor)
+		assert.Nil(t, err)
+		_, err = decodeCBOR(bytes.NewReader(b))
+		var cborErr *CBORError
+		if assert.True(t, errors.As(err, &cborErr), "%s: expected a CBORError, got %v", c.cbor, err) {
+			assert.Contains(t, cborErr.Error(), c.message, c.cbor)
+		}
+	}
+}
+
+// FuzzCBOR checks that decodeCBOR either fails with a CBORError or returns a JSON document
+func FuzzCBOR(f *testing.F) {
+	for _, seed := range []string{
+		"1bffffffffffffffff",
+		"c349010000000000000000",
+		"f90001",
+		"fa3dcccccd",
+		"c482211903e7",
+		"d54401020304",
+		"c11a514b67b0",
+		"7f657374726561646d696e67ff",
+		"9f018202039f0405ffff",
+		"bf61610161629f0203ffff",
+	} {
+		b, _ := hex.DecodeString(seed)
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, source []byte) {
+		document, err := decodeCBOR(bytes.NewReader(source))
+		if err != nil {
+			var cborErr *CBORError
+			if !errors.As(err, &cborErr) {
+				t.Fatalf("%x: expected a CBORError, got %v", source, err)
+			}
+			return
+		}
+		checkJSONDocument(t, source, document)
+	})
+}
+
+func TestCBORLoader(t *testing.T) {
+	dir := t.TempDir()
+	// {"type": "string"}
+	data, _ := hex.DecodeString("a1647479706566737472696e67")
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "data.cbor"), data, 0644))
+
+	schema, err := NewSchema(NewStringLoader(`{
+		"properties": {
+			"data": { "$ref": "file://` + filepath.ToSlash(filepath.Join(dir, "data.cbor")) + `" },
+			"size": { "type": "integer", "maximum": 65535 }
+		}
+	}`))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// {"data": h'01020304', "size": 4}
+	document, _ := hex.DecodeString("a2646461746144010203046473697a6504")
+	result, err := schema.Validate(NewCBORLoader(document))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid(), "%v", result.Errors())
+
+	// {"data": 1, "size": 65536}
+	document, _ = hex.DecodeString("a26464617461016473697a651a00010000")
+	result, err = schema.Validate(NewCBORLoader(document))
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"invalid_type (root).data", "number_lte (root).size"}, errorFields(result))
+
+	_, err = schema.Validate(NewCBORLoader([]byte{0xf7}))
+	var cborErr *CBORError
+	assert.True(t, errors.As(err, &cborErr))
+}
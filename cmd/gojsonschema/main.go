@@ -0,0 +1,160 @@
+// Command gojsonschema generates Go code from JSON schemas, and migrates schemas between drafts.
+//
+// It is meant to be run by go generate, for instance:
+//
+//	//go:generate go run github.com/xeipuuv/gojsonschema/cmd/gojsonschema validator -schema person.json -name ValidatePerson -o person_validator.go
+//
+// The validator command writes a validator specialized for the schema, see Schema.GenerateGo.
+// The types command writes Go types mirroring the schema, see Schema.GenerateGoTypes.
+// The migrate command rewrites a schema for another draft, see SchemaLoader.Migrate.
+// The package of the generated file defaults to the one being generated, $GOPACKAGE
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const usage = `usage: gojsonschema <command> [flags]
+
+commands:
+  validator    generate a Go validator for a schema
+  types        generate Go types for a schema
+  migrate      rewrite a schema for another draft
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "validator":
+		err = validator(os.Args[2:])
+	case "types":
+		err = types(os.Args[2:])
+	case "migrate":
+		err = migrate(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gojsonschema:", err)
+		os.Exit(1)
+	}
+}
+
+func validator(args []string) error {
+	flags := flag.NewFlagSet("validator", flag.ExitOnError)
+	schemaFlag := flags.String("schema", "", "path or URL of the schema")
+	packageFlag := flags.String("package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	nameFlag := flags.String("name", "Validate", "name of the generated function")
+	outputFlag := flags.String("o", "", "output file, the standard output by default")
+	flags.Parse(args)
+
+	schema, err := loadSchema(*schemaFlag)
+	if err != nil {
+		return err
+	}
+
+	source, err := schema.GenerateGo(gojsonschema.GoGeneratorOptions{Package: *packageFlag, Name: *nameFlag})
+	if err != nil {
+		return err
+	}
+	return output(*outputFlag, source)
+}
+
+func types(args []string) error {
+	flags := flag.NewFlagSet("types", flag.ExitOnError)
+	schemaFlag := flags.String("schema", "", "path or URL of the schema")
+	packageFlag := flags.String("package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	nameFlag := flags.String("name", "", "name of the type of the root schema, from its title by default")
+	outputFlag := flags.String("o", "", "output file, the standard output by default")
+	flags.Parse(args)
+
+	schema, err := loadSchema(*schemaFlag)
+	if err != nil {
+		return err
+	}
+
+	source, err := schema.GenerateGoTypes(gojsonschema.GoTypesOptions{Package: *packageFlag, Name: *nameFlag})
+	if err != nil {
+		return err
+	}
+	return output(*outputFlag, source)
+}
+
+func migrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	schemaFlag := flags.String("schema", "", "path or URL of the schema")
+	draftFlag := flags.Int("draft", 7, "draft to migrate to: 4, 6 or 7")
+	fromFlag := flags.Int("from", 0, "draft of the schema if it has no $schema, 4, 6 or 7")
+	definitionsFlag := flags.String("definitions", "", "keyword to rename definitions to, e.g. $defs")
+	outputFlag := flags.String("o", "", "output file, the standard output by default")
+	flags.Parse(args)
+
+	loader, err := schemaLoader(*schemaFlag)
+	if err != nil {
+		return err
+	}
+
+	sl := gojsonschema.NewSchemaLoader()
+	if *fromFlag != 0 {
+		sl.Draft = gojsonschema.Draft(*fromFlag)
+	}
+	migrated, issues, err := sl.Migrate(loader, gojsonschema.Draft(*draftFlag), gojsonschema.MigrateOptions{Definitions: *definitionsFlag})
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		fmt.Fprintln(os.Stderr, "gojsonschema:", issue)
+	}
+
+	source, err := json.MarshalIndent(migrated, "", "  ")
+	if err != nil {
+		return err
+	}
+	return output(*outputFlag, append(source, '\n'))
+}
+
+// loadSchema compiles the schema found at location, a URL or a file path
+func loadSchema(location string) (*gojsonschema.Schema, error) {
+	loader, err := schemaLoader(location)
+	if err != nil {
+		return nil, err
+	}
+	return gojsonschema.NewSchema(loader)
+}
+
+// schemaLoader returns a loader of the schema found at location, a URL or a file path
+func schemaLoader(location string) (gojsonschema.JSONLoader, error) {
+	if location == "" {
+		return nil, fmt.Errorf("missing -schema")
+	}
+	if !strings.Contains(location, "://") {
+		path, err := filepath.Abs(location)
+		if err != nil {
+			return nil, err
+		}
+		location = "file://" + filepath.ToSlash(path)
+	}
+	return gojsonschema.NewReferenceLoader(location), nil
+}
+
+func output(path string, data []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
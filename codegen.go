@@ -0,0 +1,629 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"math/big"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GoGeneratorOptions configures Schema.GenerateGo
+type GoGeneratorOptions struct {
+	// Package is the name of the package of the generated file, "main" by default
+	Package string
+
+	// Name is the name of the generated validation function, "Validate" by default.
+	// Several validators can live in the same package as long as their names differ
+	Name string
+}
+
+// GenerateGo writes the Go source of a validator specialized for the schema.
+// The generated function validates documents as decoded by encoding/json with UseNumber,
+// and a second one validates Go values such as typed structs the way NewGoLoader does.
+// Both produce the same Result, with the same errors, as Validate does
+func (d *Schema) GenerateGo(options GoGeneratorOptions) ([]byte, error) {
+	if options.Package == "" {
+		options.Package = "main"
+	}
+	if options.Name == "" {
+		options.Name = "Validate"
+	}
+	if !token.IsIdentifier(options.Package) || !token.IsIdentifier(options.Name) {
+		return nil, fmt.Errorf("invalid package or function name %q %q", options.Package, options.Name)
+	}
+
+	gen := &goGenerator{
+		prefix:  string(unicode.ToLower(rune(options.Name[0]))) + options.Name[1:],
+		index:   make(map[*subSchema]int),
+		imports: make(map[string]bool),
+	}
+
+	d.referencePool.lock.Lock()
+	gen.collect(d.rootSchema)
+	for _, s := range gen.schemas {
+		gen.schemaFunc(s)
+	}
+	d.referencePool.lock.Unlock()
+
+	var source bytes.Buffer
+	fmt.Fprintf(&source, "// Code generated by gojsonschema. DO NOT EDIT.\n\npackage %s\n\nimport (\n", options.Package)
+	imports := []string{"context"}
+	for i := range gen.imports {
+		imports = append(imports, i)
+	}
+	sort.Strings(imports)
+	for _, i := range imports {
+		fmt.Fprintf(&source, "%q\n", i)
+	}
+	fmt.Fprintf(&source, "\n\"github.com/xeipuuv/gojsonschema\"\n\"github.com/xeipuuv/gojsonschema/generated\"\n)\n")
+
+	fmt.Fprintf(&source, `
+// %[1]s validates document, a tree of map[string]interface{}, []interface{}, json.Number, string, bool and nil
+// as decoded by encoding/json with UseNumber, against the schema %[2]s
+func %[1]s(document interface{}) *gojsonschema.Result {
+	result, _ := %[1]sContext(context.Background(), document)
+	return result
+}
+
+// %[1]sContext is %[1]s aborting as soon as ctx is done.
+// When ctx is done before the validation completes, a nil Result is returned along with ctx.Err()
+func %[1]sContext(ctx context.Context, document interface{}) (*gojsonschema.Result, error) {
+	g := generated.NewValidator(ctx, %[3]d)
+	result := &gojsonschema.Result{}
+	%[4]s(g, document, result, gojsonschema.NewJsonContext(gojsonschema.STRING_CONTEXT_ROOT, nil))
+	if err := g.Done(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// %[1]sValue validates a Go value, such as a typed struct, as encoding/json would encode it
+func %[1]sValue(value interface{}) (*gojsonschema.Result, error) {
+	document, err := gojsonschema.NewGoLoader(value).LoadJSON()
+	if err != nil {
+		return nil, err
+	}
+	return %[1]s(document), nil
+}
+`, options.Name, d.documentReference.String(), d.maxDepth, gen.funcName(d.rootSchema))
+
+	if gen.vars.Len() > 0 {
+		fmt.Fprintf(&source, "\nvar (\n%s)\n", gen.vars.String())
+	}
+	source.Write(gen.funcs.Bytes())
+
+	return format.Source(source.Bytes())
+}
+
+// goGenerator writes one Go function for every subSchema reachable from the root,
+// each one doing what validateRecursive does for that subSchema
+type goGenerator struct {
+	prefix  string
+	schemas []*subSchema
+	index   map[*subSchema]int
+	imports map[string]bool
+
+	vars   bytes.Buffer
+	nbVars int
+	funcs  bytes.Buffer
+	w      *bytes.Buffer
+}
+
+// collect numbers the subSchemas, the walk must be deterministic for the output to be reproducible
+func (gen *goGenerator) collect(s *subSchema) {
+	if s == nil {
+		return
+	}
+	if _, ok := gen.index[s]; ok {
+		return
+	}
+	gen.index[s] = len(gen.schemas)
+	gen.schemas = append(gen.schemas, s)
+
+	if s.pass != nil {
+		return
+	}
+	if s.refSchema != nil {
+		gen.collect(s.refSchema)
+		return
+	}
+
+	for _, list := range [][]*subSchema{s.allOf, s.anyOf, s.oneOf} {
+		for _, c := range list {
+			gen.collect(c)
+		}
+	}
+	gen.collect(s.not)
+	gen.collect(s._if)
+	gen.collect(s._then)
+	gen.collect(s._else)
+	for _, k := range sortedKeys(s.dependencies) {
+		if c, ok := s.dependencies[k].(*subSchema); ok {
+			gen.collect(c)
+		}
+	}
+	for _, c := range sortedProperties(s) {
+		gen.collect(c)
+	}
+	for _, c := range s.itemsChildren {
+		gen.collect(c)
+	}
+	for _, k := range sortedPatterns(s.patternProperties) {
+		gen.collect(s.patternProperties[k])
+	}
+	if c, ok := s.additionalProperties.(*subSchema); ok {
+		gen.collect(c)
+	}
+	if c, ok := s.additionalItems.(*subSchema); ok {
+		gen.collect(c)
+	}
+	gen.collect(s.propertyNames)
+	gen.collect(s.contains)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedProperties returns propertiesChildren by name, their order depends on the compilation otherwise
+func sortedProperties(s *subSchema) []*subSchema {
+	properties := append([]*subSchema(nil), s.propertiesChildren...)
+	sort.Slice(properties, func(i, j int) bool { return properties[i].property < properties[j].property })
+	return properties
+}
+
+func sortedPatterns(m map[string]*subSchema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (gen *goGenerator) funcName(s *subSchema) string {
+	return fmt.Sprintf("%sSchema%d", gen.prefix, gen.index[s])
+}
+
+func (gen *goGenerator) funcList(list []*subSchema) string {
+	names := make([]string, len(list))
+	for i, s := range list {
+		names[i] = gen.funcName(s)
+	}
+	return "[]generated.Func{" + strings.Join(names, ", ") + "}"
+}
+
+// newVar declares a package level variable initialized with expression and returns its name
+func (gen *goGenerator) newVar(expression string) string {
+	name := fmt.Sprintf("%sVar%d", gen.prefix, gen.nbVars)
+	gen.nbVars++
+	fmt.Fprintf(&gen.vars, "%s = %s\n", name, expression)
+	return name
+}
+
+func (gen *goGenerator) ratVar(r *big.Rat) string {
+	gen.imports["math/big"] = true
+	if r.Num().IsInt64() && r.Denom().IsInt64() {
+		return gen.newVar(fmt.Sprintf("big.NewRat(%s, %s)", r.Num(), r.Denom()))
+	}
+	return gen.newVar(fmt.Sprintf("func() *big.Rat { r, _ := new(big.Rat).SetString(%q); return r }()", r.RatString()))
+}
+
+func (gen *goGenerator) regexpVar(pattern string) string {
+	gen.imports["regexp"] = true
+	return gen.newVar(fmt.Sprintf("regexp.MustCompile(%q)", pattern))
+}
+
+func (gen *goGenerator) line(format string, args ...interface{}) {
+	fmt.Fprintf(gen.w, format+"\n", args...)
+}
+
+func (gen *goGenerator) addError(errorType string, value string, details string) {
+	gen.line("g.AddError(result, new(gojsonschema.%s), context, %s, gojsonschema.ErrorDetails{%s})", errorType, value, details)
+}
+
+func (gen *goGenerator) schemaFunc(s *subSchema) {
+	gen.w = &gen.funcs
+	gen.line("\nfunc %s(g *generated.Validator, node interface{}, result *gojsonschema.Result, context *gojsonschema.JsonContext) {", gen.funcName(s))
+	gen.line("if !g.Enter(node, context) {\nreturn\n}\ndefer g.Leave()")
+
+	if s.pass != nil {
+		if !*s.pass {
+			gen.addError("FalseError", "node", "")
+		}
+		gen.line("}")
+		return
+	}
+	if s.refSchema != nil {
+		gen.line("%s(g, node, result, context)\n}", gen.funcName(s.refSchema))
+		return
+	}
+
+	gen.imports["encoding/json"] = true
+	var clauses bytes.Buffer
+	gen.w = &clauses
+
+	gen.line("case nil:")
+	if !gen.typeCheck(s, TYPE_NULL) {
+		gen.schemaPart(s, "node", false)
+		gen.commonPart(s, "node")
+	}
+
+	gen.line("case json.Number:")
+	if !gen.numberTypeCheck(s) {
+		gen.schemaPart(s, "instance", false)
+		gen.numberPart(s)
+		gen.commonPart(s, "instance")
+	}
+
+	gen.line("case []interface{}:")
+	if !gen.typeCheck(s, TYPE_ARRAY) {
+		gen.schemaPart(s, "instance", false)
+		gen.arrayPart(s)
+		gen.commonPart(s, "instance")
+	}
+
+	gen.line("case map[string]interface{}:")
+	if !gen.typeCheck(s, TYPE_OBJECT) {
+		gen.schemaPart(s, "instance", true)
+		gen.objectPart(s)
+		gen.commonPart(s, "instance")
+		for _, p := range sortedProperties(s) {
+			gen.line("if item, ok := instance[%q]; ok {", p.property)
+			gen.line("%s(g, item, result, gojsonschema.NewJsonContext(%q, context))\n}", gen.funcName(p), p.property)
+		}
+	}
+
+	gen.line("case bool:")
+	if !gen.typeCheck(s, TYPE_BOOLEAN) {
+		gen.schemaPart(s, "instance", false)
+		gen.commonPart(s, "instance")
+	}
+
+	gen.line("case string:")
+	if !gen.typeCheck(s, TYPE_STRING) {
+		gen.schemaPart(s, "instance", false)
+		gen.commonPart(s, "instance")
+		gen.stringPart(s)
+	}
+
+	gen.w = &gen.funcs
+	if strings.Contains(clauses.String(), "instance") {
+		gen.line("switch instance := node.(type) {")
+	} else {
+		gen.line("switch node.(type) {")
+	}
+	gen.funcs.Write(clauses.Bytes())
+	gen.line("}\ng.Pass(result)\n}")
+}
+
+// typeCheck writes the type check of a clause and reports whether it always fails
+func (gen *goGenerator) typeCheck(s *subSchema, given string) bool {
+	if !s.types.IsTyped() || s.types.Contains(given) {
+		return false
+	}
+	gen.addError("InvalidTypeError", "node", fmt.Sprintf(`"expected": %q, "given": %q`, s.types.String(), given))
+	gen.line("return")
+	return true
+}
+
+func (gen *goGenerator) numberTypeCheck(s *subSchema) bool {
+	if !s.types.IsTyped() || s.types.Contains(TYPE_NUMBER) {
+		return false
+	}
+	if s.types.Contains(TYPE_INTEGER) {
+		gen.line("if !g.IsInteger(instance) {")
+		gen.addError("InvalidTypeError", "node", fmt.Sprintf(`"expected": %q, "given": %q`, s.types.String(), TYPE_NUMBER))
+		gen.line("return\n}")
+		return false
+	}
+	gen.line("given := %q\nif !g.IsInteger(instance) {\ngiven = %q\n}", TYPE_INTEGER, TYPE_NUMBER)
+	gen.addError("InvalidTypeError", "node", fmt.Sprintf(`"expected": %q, "given": given`, s.types.String()))
+	gen.line("return")
+	return true
+}
+
+// schemaPart mirrors validateSchema
+func (gen *goGenerator) schemaPart(s *subSchema, value string, isObject bool) {
+	if len(s.anyOf) > 0 {
+		gen.line("{\nvalidated := false\nvar best *gojsonschema.Result")
+		gen.line("for _, f := range %s {", gen.funcList(s.anyOf))
+		gen.line("r := g.Sub(f, %s, context)\nif r.Valid() {\nvalidated = true\nbreak\n}", value)
+		gen.line("if g.Closer(r, best) {\nbest = r\n}\n}")
+		gen.line("if !validated {")
+		gen.addError("NumberAnyOfError", value, "")
+		gen.line("if best != nil {\ng.Merge(result, best)\n}\n}\n}")
+	}
+
+	if len(s.oneOf) > 0 {
+		gen.line("{\nnbValidated := 0\nvar best *gojsonschema.Result")
+		gen.line("for _, f := range %s {", gen.funcList(s.oneOf))
+		gen.line("r := g.Sub(f, %s, context)\nif r.Valid() {\nnbValidated++\n} else if nbValidated == 0 && g.Closer(r, best) {\nbest = r\n}\n}", value)
+		gen.line("if nbValidated != 1 {")
+		gen.addError("NumberOneOfError", value, "")
+		gen.line("if nbValidated == 0 {\ng.Merge(result, best)\n}\n}\n}")
+	}
+
+	if len(s.allOf) > 0 {
+		gen.line("{\nnbValidated := 0")
+		gen.line("for _, f := range %s {", gen.funcList(s.allOf))
+		gen.line("r := g.Sub(f, %s, context)\nif r.Valid() {\nnbValidated++\n}\ng.Merge(result, r)\n}", value)
+		gen.line("if nbValidated != %d {", len(s.allOf))
+		gen.addError("NumberAllOfError", value, "")
+		gen.line("}\n}")
+	}
+
+	if s.not != nil {
+		gen.line("if g.Sub(%s, %s, context).Valid() {", gen.funcName(s.not), value)
+		gen.addError("NumberNotError", value, "")
+		gen.line("}")
+	}
+
+	if isObject && len(s.dependencies) > 0 {
+		gen.line("for key := range instance {\nswitch key {")
+		for _, k := range sortedKeys(s.dependencies) {
+			gen.line("case %q:", k)
+			switch dependency := s.dependencies[k].(type) {
+			case []string:
+				for _, dependOnKey := range dependency {
+					gen.line("if _, ok := instance[%q]; !ok {", dependOnKey)
+					gen.addError("MissingDependencyError", "instance", fmt.Sprintf(`"dependency": %q`, dependOnKey))
+					gen.line("}")
+				}
+			case *subSchema:
+				gen.line("%s(g, instance, result, context)", gen.funcName(dependency))
+			}
+		}
+		gen.line("}\n}")
+	}
+
+	if s._if != nil {
+		gen.line("{\nifResult := g.Sub(%s, %s, context)\n_ = ifResult", gen.funcName(s._if), value)
+		if s._then != nil {
+			gen.line("if ifResult.Valid() {\nif r := g.Sub(%s, %s, context); !r.Valid() {", gen.funcName(s._then), value)
+			gen.addError("ConditionThenError", value, "")
+			gen.line("g.Merge(result, r)\n}\n}")
+		}
+		if s._else != nil {
+			gen.line("if !ifResult.Valid() {\nif r := g.Sub(%s, %s, context); !r.Valid() {", gen.funcName(s._else), value)
+			gen.addError("ConditionElseError", value, "")
+			gen.line("g.Merge(result, r)\n}\n}")
+		}
+		gen.line("}")
+	}
+
+	gen.line("g.Pass(result)")
+}
+
+// commonPart mirrors validateCommon
+func (gen *goGenerator) commonPart(s *subSchema, value string) {
+	if s._const != nil {
+		gen.line("if canonical, err := g.Canonical(%s); err != nil {", value)
+		gen.addError("InternalError", value, `"error": err`)
+		gen.line("} else if canonical != %q {", *s._const)
+		gen.addError("ConstError", value, fmt.Sprintf(`"allowed": %q`, *s._const))
+		gen.line("}")
+	}
+
+	if len(s.enum) > 0 {
+		var set strings.Builder
+		set.WriteString("map[string]struct{}{")
+		for _, e := range s.enum {
+			fmt.Fprintf(&set, "%q: {}, ", e)
+		}
+		set.WriteString("}")
+		enum := gen.newVar(set.String())
+
+		gen.line("if canonical, err := g.Canonical(%s); err != nil {", value)
+		gen.addError("InternalError", value, `"error": err`)
+		gen.line("} else if _, ok := %s[canonical]; !ok {", enum)
+		gen.addError("EnumError", value, fmt.Sprintf(`"allowed": %q`, strings.Join(s.enum, ", ")))
+		gen.line("}")
+	}
+
+	if s.format != "" {
+		gen.line("if !gojsonschema.FormatCheckers.IsFormat(%q, %s) {", s.format, value)
+		gen.addError("DoesNotMatchFormatError", value, fmt.Sprintf(`"format": %q`, s.format))
+		gen.line("}")
+	}
+
+	gen.line("g.Pass(result)")
+}
+
+// numberPart mirrors validateNumber
+func (gen *goGenerator) numberPart(s *subSchema) {
+	checks := []struct {
+		bound     *big.Rat
+		errorType string
+		condition string
+		detail    string
+	}{
+		{s.maximum, "NumberLTEError", "number.Cmp(%s) == 1", "max"},
+		{s.exclusiveMaximum, "NumberLTError", "number.Cmp(%s) >= 0", "max"},
+		{s.minimum, "NumberGTEError", "number.Cmp(%s) == -1", "min"},
+		{s.exclusiveMinimum, "NumberGTError", "number.Cmp(%s) <= 0", "min"},
+	}
+
+	if s.multipleOf != nil || s.maximum != nil || s.exclusiveMaximum != nil || s.minimum != nil || s.exclusiveMinimum != nil {
+		gen.line("number, _ := new(big.Rat).SetString(string(instance))")
+	}
+
+	if s.multipleOf != nil {
+		multipleOf := gen.ratVar(s.multipleOf)
+		gen.line("if !new(big.Rat).Quo(number, %s).IsInt() {", multipleOf)
+		gen.addError("MultipleOfError", "instance", fmt.Sprintf(`"multiple": new(big.Float).SetRat(%s)`, multipleOf))
+		gen.line("}")
+	}
+
+	for _, check := range checks {
+		if check.bound == nil {
+			continue
+		}
+		bound := gen.ratVar(check.bound)
+		gen.line("if "+check.condition+" {", bound)
+		gen.addError(check.errorType, "instance", fmt.Sprintf(`%q: new(big.Float).SetRat(%s)`, check.detail, bound))
+		gen.line("}")
+	}
+
+	gen.line("g.Pass(result)")
+}
+
+// stringPart mirrors validateString
+func (gen *goGenerator) stringPart(s *subSchema) {
+	if s.minLength != nil {
+		gen.imports["unicode/utf8"] = true
+		gen.line("if utf8.RuneCountInString(instance) < %d {", *s.minLength)
+		gen.addError("StringLengthGTEError", "instance", fmt.Sprintf(`"min": %d`, *s.minLength))
+		gen.line("}")
+	}
+	if s.maxLength != nil {
+		gen.imports["unicode/utf8"] = true
+		gen.line("if utf8.RuneCountInString(instance) > %d {", *s.maxLength)
+		gen.addError("StringLengthLTEError", "instance", fmt.Sprintf(`"max": %d`, *s.maxLength))
+		gen.line("}")
+	}
+	if s.pattern != nil {
+		pattern := gen.regexpVar(s.pattern.String())
+		gen.line("if !%s.MatchString(instance) {", pattern)
+		gen.addError("DoesNotMatchPatternError", "instance", fmt.Sprintf(`"pattern": %s`, pattern))
+		gen.line("}")
+	}
+
+	gen.line("g.Pass(result)")
+}
+
+// arrayPart mirrors validateArray
+func (gen *goGenerator) arrayPart(s *subSchema) {
+	itemContext := "gojsonschema.NewJsonContext(strconv.Itoa(i), context)"
+
+	if s.itemsChildrenIsSingleSchema {
+		gen.imports["strconv"] = true
+		gen.line("for i, item := range instance {\ng.Merge(result, g.Sub(%s, item, %s))\n}", gen.funcName(s.itemsChildren[0]), itemContext)
+	} else if len(s.itemsChildren) > 0 {
+		gen.imports["strconv"] = true
+		nbItems := len(s.itemsChildren)
+		gen.line("for i, f := range %s {\nif i == len(instance) {\nbreak\n}", gen.funcList(s.itemsChildren))
+		gen.line("g.Merge(result, g.Sub(f, instance[i], %s))\n}", itemContext)
+
+		switch additionalItems := s.additionalItems.(type) {
+		case bool:
+			if !additionalItems {
+				gen.line("if len(instance) > %d {", nbItems)
+				gen.addError("ArrayNoAdditionalItemsError", "instance", "")
+				gen.line("}")
+			}
+		case *subSchema:
+			gen.line("for i := %d; i < len(instance); i++ {", nbItems)
+			gen.line("g.Merge(result, g.Sub(%s, instance[i], %s))\n}", gen.funcName(additionalItems), itemContext)
+		}
+	}
+
+	if s.minItems != nil {
+		gen.line("if len(instance) < %d {", *s.minItems)
+		gen.addError("ArrayMinItemsError", "instance", fmt.Sprintf(`"min": %d`, *s.minItems))
+		gen.line("}")
+	}
+	if s.maxItems != nil {
+		gen.line("if len(instance) > %d {", *s.maxItems)
+		gen.addError("ArrayMaxItemsError", "instance", fmt.Sprintf(`"max": %d`, *s.maxItems))
+		gen.line("}")
+	}
+
+	if s.uniqueItems {
+		gen.line("{\nseen := make(map[string]int)\nfor j, item := range instance {")
+		gen.line("canonical, err := g.Canonical(item)\nif err != nil {")
+		gen.addError("InternalError", "instance", `"err": err`)
+		gen.line("}\nif i, ok := seen[canonical]; ok {")
+		gen.addError("ItemsMustBeUniqueError", "instance", fmt.Sprintf(`"type": %q, "i": i, "j": j`, TYPE_ARRAY))
+		gen.line("}\nseen[canonical] = j\n}\n}")
+	}
+
+	if s.contains != nil {
+		gen.imports["strconv"] = true
+		gen.line("{\nvalidated := false\nvar best *gojsonschema.Result\nfor i, item := range instance {")
+		gen.line("r := g.Sub(%s, item, %s)\nif r.Valid() {\nvalidated = true\nbreak\n}", gen.funcName(s.contains), itemContext)
+		gen.line("if g.Closer(r, best) {\nbest = r\n}\n}\nif !validated {")
+		gen.addError("ArrayContainsError", "instance", "")
+		gen.line("if best != nil {\ng.Merge(result, best)\n}\n}\n}")
+	}
+
+	gen.line("g.Pass(result)")
+}
+
+// objectPart mirrors validateObject
+func (gen *goGenerator) objectPart(s *subSchema) {
+	if s.minProperties != nil {
+		gen.line("if len(instance) < %d {", *s.minProperties)
+		gen.addError("ArrayMinPropertiesError", "instance", fmt.Sprintf(`"min": %d`, *s.minProperties))
+		gen.line("}")
+	}
+	if s.maxProperties != nil {
+		gen.line("if len(instance) > %d {", *s.maxProperties)
+		gen.addError("ArrayMaxPropertiesError", "instance", fmt.Sprintf(`"max": %d`, *s.maxProperties))
+		gen.line("}")
+	}
+
+	for _, property := range s.required {
+		gen.line("if _, ok := instance[%q]; ok {\ng.Pass(result)\n} else {", property)
+		gen.addError("RequiredError", "instance", fmt.Sprintf(`"property": %q`, property))
+		gen.line("}")
+	}
+
+	additional := false
+	switch ap := s.additionalProperties.(type) {
+	case bool:
+		additional = !ap
+	case *subSchema:
+		additional = true
+	}
+	patterns := sortedPatterns(s.patternProperties)
+
+	if additional || len(patterns) > 0 {
+		gen.line("for key, item := range instance {")
+		if len(patterns) > 0 {
+			gen.line("matched := false")
+			for _, p := range patterns {
+				gen.line("if %s.MatchString(key) {\nmatched = true", gen.regexpVar(p))
+				gen.line("g.Merge(result, g.Sub(%s, item, gojsonschema.NewJsonContext(key, context)))\n}", gen.funcName(s.patternProperties[p]))
+			}
+			gen.line("if matched {\ng.Pass(result)")
+			if additional {
+				gen.line("continue")
+			}
+			gen.line("}")
+		}
+		if additional {
+			if len(s.propertiesChildren) > 0 {
+				names := make([]string, len(s.propertiesChildren))
+				for i, p := range sortedProperties(s) {
+					names[i] = fmt.Sprintf("%q", p.property)
+				}
+				gen.line("switch key {\ncase %s:\ncontinue\n}", strings.Join(names, ", "))
+			}
+			switch ap := s.additionalProperties.(type) {
+			case bool:
+				gen.addError("AdditionalPropertyNotAllowedError", "item", `"property": key`)
+			case *subSchema:
+				gen.line("g.Merge(result, g.Sub(%s, item, gojsonschema.NewJsonContext(key, context)))", gen.funcName(ap))
+			}
+		}
+		gen.line("}")
+	}
+
+	if s.propertyNames != nil {
+		gen.line("for key := range instance {\nif r := g.Sub(%s, key, context); !r.Valid() {", gen.funcName(s.propertyNames))
+		gen.addError("InvalidPropertyNameError", "instance", `"property": key`)
+		gen.line("g.Merge(result, r)\n}\n}")
+	}
+
+	gen.line("g.Pass(result)")
+}
@@ -0,0 +1,183 @@
+package gojsonschema
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// generatedCase is a case of the test suite, along with the errors Validate found
+type generatedCase struct {
+	schema   *Schema
+	document interface{}
+	expected []string
+}
+
+const generatedMain = `package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sort"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func main() {
+	encoder := json.NewEncoder(os.Stdout)
+	for _, c := range cases {
+		decoder := json.NewDecoder(bytes.NewBufferString(c.document))
+		decoder.UseNumber()
+		var document interface{}
+		if err := decoder.Decode(&document); err != nil {
+			panic(err)
+		}
+		errors := []string{}
+		for _, err := range c.validate(document).Errors() {
+			errors = append(errors, err.Type()+" "+err.String())
+		}
+		sort.Strings(errors)
+		encoder.Encode(errors)
+	}
+}
+
+var cases = []struct {
+	validate func(interface{}) *gojsonschema.Result
+	document string
+}{
+`
+
+// suiteGeneratedCases validates the cases of the test suite, to validate them again with the generated validators
+func suiteGeneratedCases(t *testing.T) []generatedCase {
+	var cases []generatedCase
+	err := walkSuite(func(path string) error {
+		tests, draft := readTests(t, path)
+		for _, test := range tests {
+			if test.Disabled {
+				continue
+			}
+			sl := NewSchemaLoader()
+			sl.Draft = draft
+			schema, err := sl.Compile(NewRawLoader(test.Schema))
+			if err != nil {
+				continue
+			}
+			for _, testCase := range test.Tests {
+				result, err := schema.Validate(NewRawLoader(testCase.Data))
+				if assert.Nil(t, err) {
+					cases = append(cases, generatedCase{schema, testCase.Data, errorStrings(result)})
+				}
+			}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	return cases
+}
+
+// TestGenerateGoSuite generates a validator for every schema of the test suite and runs them
+// on the suite, the errors must be the same as the ones found by Validate
+func TestGenerateGoSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the generated validators are compiled and run with the go tool")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not available, the generated validators are not checked")
+	}
+	moduleDir, err := filepath.Abs(".")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// The generated code lives in a module of its own, using this one through a workspace
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module generatedsuite\n\ngo 1.21\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "go.work"), []byte(fmt.Sprintf("go 1.21\n\nuse (\n\t.\n\t%q\n)\n", moduleDir)), 0644))
+
+	cases := suiteGeneratedCases(t)
+
+	var main bytes.Buffer
+	main.WriteString(generatedMain)
+	names := make(map[*Schema]string)
+	for _, c := range cases {
+		name, ok := names[c.schema]
+		if !ok {
+			name = fmt.Sprintf("Validate%d", len(names))
+			names[c.schema] = name
+			source, err := c.schema.GenerateGo(GoGeneratorOptions{Name: name})
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.Nil(t, os.WriteFile(filepath.Join(dir, strings.ToLower(name)+".go"), source, 0644))
+		}
+		document, err := json.Marshal(c.document)
+		assert.Nil(t, err)
+		fmt.Fprintf(&main, "{%s, %q},\n", name, document)
+	}
+	main.WriteString("}\n")
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "main.go"), main.Bytes(), 0644))
+
+	command := exec.Command(goTool, "run", ".")
+	command.Dir = dir
+	command.Env = append(os.Environ(), "GOWORK="+filepath.Join(dir, "go.work"), "GOFLAGS=")
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	output, err := command.Output()
+	if !assert.Nil(t, err, stderr.String()) {
+		return
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(nil, 1<<24)
+	for i, c := range cases {
+		if !assert.True(t, scanner.Scan(), "missing output of case %d", i) {
+			return
+		}
+		var errors []string
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &errors))
+		if len(c.expected) == 0 {
+			c.expected = []string{}
+		}
+		assert.Equal(t, c.expected, errors, "case %d of %s", i, names[c.schema])
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"type" : "object",
+		"properties" : {
+			"a" : { "type" : "integer", "minimum" : 1 },
+			"b" : { "type" : "string", "pattern" : "^b" },
+			"c" : { "$ref" : "#" }
+		},
+		"patternProperties" : { "^x" : { "enum" : [1, "x"] }, "^y" : true },
+		"additionalProperties" : false
+	}`))
+	assert.Nil(t, err)
+
+	// The output is the same for every run
+	source, err := schema.GenerateGo(GoGeneratorOptions{Package: "person", Name: "ValidatePerson"})
+	assert.Nil(t, err)
+	again, err := schema.GenerateGo(GoGeneratorOptions{Package: "person", Name: "ValidatePerson"})
+	assert.Nil(t, err)
+	assert.Equal(t, string(source), string(again))
+
+	assert.Contains(t, string(source), "package person")
+	assert.Contains(t, string(source), "func ValidatePerson(document interface{}) *gojsonschema.Result")
+	assert.Contains(t, string(source), "func ValidatePersonValue(value interface{}) (*gojsonschema.Result, error)")
+	// A cancelled validation returns no Result, like ValidateContext
+	assert.Contains(t, string(source), "if err := g.Done(result); err != nil {\n\t\treturn nil, err\n\t}")
+
+	_, err = schema.GenerateGo(GoGeneratorOptions{Name: "not a name"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,324 @@
+package gojsonschema
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValidateCoerce validates a document whose values may have the wrong type, such as query
+// parameters or environment variables which are all strings. Values are first converted to the
+// types the schema expects where they are, with the rules of ajv's coerceTypes "array" mode:
+//
+//	to number or integer: numeric strings, booleans as 0 and 1, and null as 0. Strings that are
+//	JSON numbers are kept as written, so large integers aren't rounded to a float64
+//	to string: numbers, booleans, and null as ""
+//	to boolean: "true", "false", 1, 0, and null as false
+//	to null: "", 0 and false
+//	to array: any other value is wrapped in an array, and an array of one value is unwrapped for other types
+//
+// Conversions follow properties, patternProperties, additionalProperties, items, additionalItems,
+// $ref and allOf. Branches of anyOf, oneOf, not and if are not used, as they only apply if they match.
+// The converted document is validated and returned along with the Result, the loaded document is not modified
+func (v *Schema) ValidateCoerce(l JSONLoader) (*Result, interface{}, error) {
+	return v.ValidateCoerceContext(context.Background(), l)
+}
+
+// ValidateCoerceContext is ValidateCoerce aborting as soon as ctx is done, see ValidateContext
+func (v *Schema) ValidateCoerceContext(ctx context.Context, l JSONLoader) (*Result, interface{}, error) {
+	root, err := loadJSONLimited(ctx, l, v.limits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := &coercer{maxDepth: v.maxDepth}
+	coerced := c.coerce(v.rootSchema, root)
+
+	result, err := v.validateDocumentContext(ctx, coerced)
+	return result, coerced, err
+}
+
+// coercer converts a document to the types of a schema
+type coercer struct {
+	// maxDepth stops the conversion of schemas nesting deeper than the validation would go
+	maxDepth int
+	depth    int
+}
+
+func (c *coercer) coerce(s *subSchema, node interface{}) interface{} {
+	if s == nil || s.pass != nil {
+		return node
+	}
+
+	c.depth++
+	defer func() { c.depth-- }()
+	if c.maxDepth > 0 && c.depth > c.maxDepth {
+		return node
+	}
+
+	if s.refSchema != nil {
+		return c.coerce(s.refSchema, node)
+	}
+
+	node = coerceType(s.types, node)
+
+	switch value := node.(type) {
+	case map[string]interface{}:
+		node = c.coerceObject(s, value)
+	case []interface{}:
+		node = c.coerceArray(s, value)
+	}
+
+	for _, a := range s.allOf {
+		node = c.coerce(a, node)
+	}
+	return node
+}
+
+func (c *coercer) coerceObject(s *subSchema, value map[string]interface{}) map[string]interface{} {
+	if len(s.propertiesChildren) == 0 && len(s.patternProperties) == 0 && s.additionalProperties == nil {
+		return value
+	}
+
+	coerced := make(map[string]interface{}, len(value))
+	for key, item := range value {
+		described := false
+		for _, p := range s.propertiesChildren {
+			if p.property == key {
+				described = true
+				item = c.coerce(p, item)
+			}
+		}
+		for pattern, p := range s.patternProperties {
+			if matches, _ := regexp.MatchString(pattern, key); matches {
+				described = true
+				item = c.coerce(p, item)
+			}
+		}
+		if ap, ok := s.additionalProperties.(*subSchema); ok && !described {
+			item = c.coerce(ap, item)
+		}
+		coerced[key] = item
+	}
+	return coerced
+}
+
+func (c *coercer) coerceArray(s *subSchema, value []interface{}) []interface{} {
+	if len(s.itemsChildren) == 0 {
+		return value
+	}
+
+	coerced := make([]interface{}, len(value))
+	for i, item := range value {
+		switch {
+		case s.itemsChildrenIsSingleSchema:
+			item = c.coerce(s.itemsChildren[0], item)
+		case i < len(s.itemsChildren):
+			item = c.coerce(s.itemsChildren[i], item)
+		default:
+			if ai, ok := s.additionalItems.(*subSchema); ok {
+				item = c.coerce(ai, item)
+			}
+		}
+		coerced[i] = item
+	}
+	return coerced
+}
+
+// coercibleTypes are the types values are converted to, in this order
+var coercibleTypes = []string{TYPE_STRING, TYPE_NUMBER, TYPE_INTEGER, TYPE_BOOLEAN, TYPE_NULL, TYPE_ARRAY}
+
+// coerceType converts node to one of types if it is none of them already.
+// The first of the schema's types node can be converted to is used
+func coerceType(types jsonSchemaType, node interface{}) interface{} {
+	if !types.IsTyped() || hasJSONType(types, node) {
+		return node
+	}
+
+	// A single value is taken out of its array, it may already have a valid type
+	if list, ok := node.([]interface{}); ok && len(list) == 1 && !types.Contains(TYPE_ARRAY) {
+		node = list[0]
+		if hasJSONType(types, node) {
+			return node
+		}
+	}
+
+	for _, t := range types.types {
+		if !isStringInSlice(coercibleTypes, t) {
+			continue
+		}
+		if coerced, ok := coerceTo(t, node); ok {
+			return coerced
+		}
+	}
+	return node
+}
+
+// hasJSONType reports whether node is of one of types
+func hasJSONType(types jsonSchemaType, node interface{}) bool {
+	switch value := node.(type) {
+	case nil:
+		return types.Contains(TYPE_NULL)
+	case json.Number:
+		return types.Contains(TYPE_NUMBER) || (types.Contains(TYPE_INTEGER) && checkJSONInteger(value))
+	case string:
+		return types.Contains(TYPE_STRING)
+	case bool:
+		return types.Contains(TYPE_BOOLEAN)
+	case []interface{}:
+		return types.Contains(TYPE_ARRAY)
+	case map[string]interface{}:
+		return types.Contains(TYPE_OBJECT)
+	}
+	return isKind(node, reflect.Map) && types.Contains(TYPE_OBJECT) || isKind(node, reflect.Slice) && types.Contains(TYPE_ARRAY)
+}
+
+// coerceTo converts node to the type t, if ajv would
+func coerceTo(t string, node interface{}) (interface{}, bool) {
+	switch t {
+	case TYPE_STRING:
+		switch value := node.(type) {
+		case json.Number:
+			if f, ok := jsNumber(value); ok {
+				if s, ok := formatJSNumber(f); ok {
+					return string(s.(json.Number)), true
+				}
+			}
+		case bool:
+			return strconv.FormatBool(value), true
+		case nil:
+			return "", true
+		}
+
+	case TYPE_NUMBER, TYPE_INTEGER:
+		var f float64
+		switch value := node.(type) {
+		case bool:
+			if value {
+				f = 1
+			}
+		case nil:
+		case string:
+			// JSON numbers are kept as they are written, JavaScript's syntaxes go through float64
+			if trimmed := strings.TrimFunc(value, isJSWhitespace); jsonNumber.MatchString(trimmed) {
+				n := json.Number(trimmed)
+				if t == TYPE_INTEGER && !checkJSONInteger(n) {
+					return nil, false
+				}
+				return n, true
+			}
+			var ok bool
+			if f, ok = parseJSNumber(value); !ok || value == "" {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+		if t == TYPE_INTEGER && f != math.Trunc(f) {
+			return nil, false
+		}
+		return formatJSNumber(f)
+
+	case TYPE_BOOLEAN:
+		switch value := node.(type) {
+		case string:
+			if value == "true" || value == "false" {
+				return value == "true", true
+			}
+		case json.Number:
+			if f, ok := jsNumber(value); ok && (f == 0 || f == 1) {
+				return f == 1, true
+			}
+		case nil:
+			return false, true
+		}
+
+	case TYPE_NULL:
+		switch value := node.(type) {
+		case string:
+			if value == "" {
+				return nil, true
+			}
+		case json.Number:
+			if f, ok := jsNumber(value); ok && f == 0 {
+				return nil, true
+			}
+		case bool:
+			if !value {
+				return nil, true
+			}
+		}
+
+	case TYPE_ARRAY:
+		switch node.(type) {
+		case string, json.Number, bool, nil:
+			return []interface{}{node}, true
+		}
+	}
+	return nil, false
+}
+
+func jsNumber(n json.Number) (float64, bool) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	return f, err == nil
+}
+
+// formatJSNumber returns f as a JSON number, written as JavaScript writes numbers
+func formatJSNumber(f float64) (interface{}, bool) {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, false
+	}
+	return json.Number(b), true
+}
+
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9]\d*)(\.\d+)?([eE][+-]?\d+)?$`)
+
+var jsDecimal = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// parseJSNumber parses s as JavaScript's Number(s) does, blank strings are 0
+func parseJSNumber(s string) (float64, bool) {
+	s = strings.TrimFunc(s, isJSWhitespace)
+	if s == "" {
+		return 0, true
+	}
+
+	if len(s) > 2 && s[0] == '0' {
+		base := 0
+		switch s[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 0 {
+			i, ok := new(big.Int).SetString(s[2:], base)
+			if !ok {
+				return 0, false
+			}
+			f, _ := new(big.Float).SetInt(i).Float64()
+			return f, !math.IsInf(f, 0)
+		}
+	}
+
+	if !jsDecimal.MatchString(s) {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+func isJSWhitespace(r rune) bool {
+	switch r {
+	case '\t', '\n', '\v', '\f', '\r', ' ', '\u00a0', '\u1680', '\u2028', '\u2029', '\u202f', '\u205f', '\u3000', '\ufeff':
+		return true
+	}
+	return r >= '\u2000' && r <= '\u200a'
+}
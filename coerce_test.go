@@ -0,0 +1,119 @@
+package gojsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoerceTo(t *testing.T) {
+	cases := []struct {
+		to       string
+		value    interface{}
+		expected interface{}
+		ok       bool
+	}{
+		{TYPE_NUMBER, "42", json.Number("42"), true},
+		{TYPE_NUMBER, " 1.50 ", json.Number("1.50"), true},
+		{TYPE_NUMBER, "1e3", json.Number("1e3"), true},
+		{TYPE_NUMBER, "+1e3", json.Number("1000"), true},
+		{TYPE_NUMBER, "+1e21", json.Number("1e+21"), true},
+		{TYPE_NUMBER, "0x10", json.Number("16"), true},
+		{TYPE_NUMBER, ".5", json.Number("0.5"), true},
+		{TYPE_NUMBER, " ", json.Number("0"), true},
+		{TYPE_NUMBER, "", nil, false},
+		{TYPE_NUMBER, "12abc", nil, false},
+		{TYPE_NUMBER, "Infinity", nil, false},
+		{TYPE_NUMBER, true, json.Number("1"), true},
+		{TYPE_NUMBER, nil, json.Number("0"), true},
+		{TYPE_INTEGER, "42", json.Number("42"), true},
+		{TYPE_INTEGER, "4.2", nil, false},
+		{TYPE_INTEGER, "12345678901234567891", json.Number("12345678901234567891"), true},
+		{TYPE_INTEGER, "4.0", json.Number("4.0"), true},
+		{TYPE_STRING, json.Number("1.50"), "1.5", true},
+		{TYPE_STRING, json.Number("1e21"), "1e+21", true},
+		{TYPE_STRING, json.Number("0.0000001"), "1e-7", true},
+		{TYPE_STRING, false, "false", true},
+		{TYPE_STRING, nil, "", true},
+		{TYPE_BOOLEAN, "true", true, true},
+		{TYPE_BOOLEAN, "false", false, true},
+		{TYPE_BOOLEAN, "yes", nil, false},
+		{TYPE_BOOLEAN, json.Number("1"), true, true},
+		{TYPE_BOOLEAN, json.Number("2"), nil, false},
+		{TYPE_BOOLEAN, nil, false, true},
+		{TYPE_NULL, "", nil, true},
+		{TYPE_NULL, json.Number("0"), nil, true},
+		{TYPE_NULL, false, nil, true},
+		{TYPE_NULL, "null", nil, false},
+		{TYPE_ARRAY, "a", []interface{}{"a"}, true},
+		{TYPE_ARRAY, map[string]interface{}{}, nil, false},
+	}
+
+	for _, c := range cases {
+		coerced, ok := coerceTo(c.to, c.value)
+		assert.Equal(t, c.ok, ok, "%s %#v", c.to, c.value)
+		if c.ok {
+			assert.Equal(t, c.expected, coerced, "%s %#v", c.to, c.value)
+		}
+	}
+}
+
+func TestValidateCoerce(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"type" : "object",
+		"properties" : {
+			"page" : { "type" : "integer", "minimum" : 1 },
+			"debug" : { "type" : "boolean" },
+			"ratio" : { "type" : ["null", "number"] },
+			"tags" : { "type" : "array", "items" : { "type" : "integer" } },
+			"sort" : { "type" : "string" },
+			"filter" : { "$ref" : "#/definitions/filter" }
+		},
+		"patternProperties" : { "^x-" : { "type" : "boolean" } },
+		"additionalProperties" : { "type" : "number" },
+		"definitions" : {
+			"filter" : { "allOf" : [{ "properties" : { "min" : { "type" : "number" } } }] }
+		}
+	}`))
+	assert.Nil(t, err)
+
+	document := map[string]interface{}{
+		"page":   "2",
+		"debug":  "true",
+		"ratio":  "",
+		"tags":   "7",
+		"sort":   []interface{}{"name"},
+		"filter": map[string]interface{}{"min": "1.5"},
+		"x-raw":  "false",
+		"other":  "3",
+	}
+	result, coerced, err := schema.ValidateCoerce(NewGoLoader(document))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid(), "%v", result.Errors())
+	assert.Equal(t, map[string]interface{}{
+		"page":   json.Number("2"),
+		"debug":  true,
+		"ratio":  nil,
+		"tags":   []interface{}{json.Number("7")},
+		"sort":   "name",
+		"filter": map[string]interface{}{"min": json.Number("1.5")},
+		"x-raw":  false,
+		"other":  json.Number("3"),
+	}, coerced)
+
+	// The loaded document is not modified
+	assert.Equal(t, "2", document["page"])
+
+	// Values that can't be converted are reported as usual
+	result, coerced, err = schema.ValidateCoerce(NewStringLoader(`{"page" : "0", "debug" : "yes"}`))
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"number_gte (root).page", "invalid_type (root).debug"}, errorFields(result))
+	assert.Equal(t, map[string]interface{}{"page": json.Number("0"), "debug": "yes"}, coerced)
+
+	// Integers past the precision of float64 are kept exactly
+	result, coerced, err = schema.ValidateCoerce(NewStringLoader(`{"page" : "12345678901234567891"}`))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid(), "%v", result.Errors())
+	assert.Equal(t, map[string]interface{}{"page": json.Number("12345678901234567891")}, coerced)
+}
@@ -0,0 +1,792 @@
+package gojsonschema
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Compatibility tells which documents remain valid after a schema changes
+type Compatibility string
+
+const (
+	// CompatibilityFull is a change after which the documents valid under either version are valid under both
+	CompatibilityFull Compatibility = "full"
+	// CompatibilityBackward is a change that loosens the schema: documents valid under the old version are valid under the new one
+	CompatibilityBackward Compatibility = "backward"
+	// CompatibilityForward is a change that tightens the schema: documents valid under the new version are valid under the old one
+	CompatibilityForward Compatibility = "forward"
+	// CompatibilityNone is a change after which neither version accepts all the documents of the other
+	CompatibilityNone Compatibility = "none"
+)
+
+func compatibility(backward bool, forward bool) Compatibility {
+	switch {
+	case backward && forward:
+		return CompatibilityFull
+	case backward:
+		return CompatibilityBackward
+	case forward:
+		return CompatibilityForward
+	}
+	return CompatibilityNone
+}
+
+// Backward reports whether documents valid under the old version are valid under the new one
+func (c Compatibility) Backward() bool {
+	return c == CompatibilityFull || c == CompatibilityBackward
+}
+
+// Forward reports whether documents valid under the new version are valid under the old one
+func (c Compatibility) Forward() bool {
+	return c == CompatibilityFull || c == CompatibilityForward
+}
+
+// SchemaChange is a difference between two versions of a schema, found by CompareSchemas
+type SchemaChange struct {
+	// Keyword is the keyword that changed, e.g. "required" or "properties", empty for a boolean schema
+	Keyword       string
+	Compatibility Compatibility
+	// OldLocation and NewLocation are the absolute locations of the compared schemas, e.g. "file:///event.json#/properties/id".
+	// A $ref is followed to the location of its target
+	OldLocation string
+	NewLocation string
+	Message     string
+}
+
+func (c SchemaChange) String() string {
+	return fmt.Sprintf("%s: %s [%s] %s", c.NewLocation, c.Compatibility, c.Keyword, c.Message)
+}
+
+// SchemaChanges are the changes between two versions of a schema
+type SchemaChanges []SchemaChange
+
+// Compatibility returns the compatibility of all the changes together, CompatibilityFull if there are none
+func (changes SchemaChanges) Compatibility() Compatibility {
+	backward, forward := true, true
+	for _, c := range changes {
+		backward = backward && c.Compatibility.Backward()
+		forward = forward && c.Compatibility.Forward()
+	}
+	return compatibility(backward, forward)
+}
+
+// CompareSchemas compares two compiled versions of a schema keyword by keyword, following the $refs of each
+// through its own schema pool, and returns how they differ: added required properties, narrowed enums,
+// tightened bounds, closed additionalProperties, changed types, removed properties and so on.
+//
+// The comparison is structural, so it errs on the safe side: a change is never classified as more compatible
+// than it is, but a rewritten schema that validates the same documents may be reported as changed.
+// Changes inside not have the opposite effect, changes inside oneOf and if have an unknown one and are
+// classified CompatibilityNone. A subschema reached through several $refs is compared once
+func CompareSchemas(oldSchema *Schema, newSchema *Schema) SchemaChanges {
+	c := newSchemaComparer(compareNormal)
+	c.compare(oldSchema.rootSchema, newSchema.rootSchema, schemaLocation(oldSchema.documentReference.String()), schemaLocation(newSchema.documentReference.String()))
+	return c.changes
+}
+
+// compareMode is how a change of a subschema affects the schema containing it
+type compareMode int
+
+const (
+	compareNormal compareMode = iota
+	compareInverted
+	compareUnknown
+)
+
+// effect is how a change affects the documents a schema accepts
+type effect int
+
+const (
+	unchanged effect = iota
+	tightened
+	loosened
+	changed
+)
+
+type schemaComparer struct {
+	mode    compareMode
+	changes SchemaChanges
+	visited map[comparison]bool
+}
+
+// comparison is a pair of compared subschemas
+type comparison struct {
+	a, b *subSchema
+	mode compareMode
+}
+
+func newSchemaComparer(mode compareMode) *schemaComparer {
+	return &schemaComparer{mode: mode, visited: make(map[comparison]bool)}
+}
+
+func (c *schemaComparer) report(keyword string, e effect, oldLocation string, newLocation string, format string, args ...interface{}) {
+	switch {
+	case c.mode == compareUnknown:
+		e = changed
+	case c.mode == compareInverted && e == tightened:
+		e = loosened
+	case c.mode == compareInverted && e == loosened:
+		e = tightened
+	}
+
+	compat := CompatibilityNone
+	switch e {
+	case unchanged:
+		compat = CompatibilityFull
+	case tightened:
+		compat = CompatibilityForward
+	case loosened:
+		compat = CompatibilityBackward
+	}
+	c.changes = append(c.changes, SchemaChange{
+		Keyword:       keyword,
+		Compatibility: compat,
+		OldLocation:   oldLocation,
+		NewLocation:   newLocation,
+		Message:       fmt.Sprintf(format, args...),
+	})
+}
+
+// nested compares a and b in mode, relative to the mode of c
+func (c *schemaComparer) nested(mode compareMode, a *subSchema, b *subSchema, oldLocation string, newLocation string) {
+	switch {
+	case c.mode == compareUnknown || mode == compareUnknown:
+		mode = compareUnknown
+	case c.mode == compareInverted && mode == compareInverted:
+		mode = compareNormal
+	case c.mode == compareInverted:
+		mode = compareInverted
+	}
+	previous := c.mode
+	c.mode = mode
+	c.compare(a, b, oldLocation, newLocation)
+	c.mode = previous
+}
+
+// aggregate returns the combined effect of the changes between a and b, for schemas that take each other's place.
+// The pairs c has visited are not compared again, which ends the comparison of recursive schemas
+func (c *schemaComparer) aggregate(a *subSchema, b *subSchema, oldLocation string, newLocation string) effect {
+	sub := &schemaComparer{mode: compareNormal, visited: c.visited}
+	sub.compare(a, b, oldLocation, newLocation)
+	switch sub.changes.Compatibility() {
+	case CompatibilityFull:
+		return unchanged
+	case CompatibilityForward:
+		return tightened
+	case CompatibilityBackward:
+		return loosened
+	}
+	return changed
+}
+
+// schemaLocation returns reference as a location with a fragment, to append JSON Pointer tokens to
+func schemaLocation(reference string) string {
+	if !strings.Contains(reference, "#") {
+		return reference + "#"
+	}
+	return reference
+}
+
+var (
+	trueSchema  = &subSchema{pass: newBool(true)}
+	falseSchema = &subSchema{pass: newBool(false)}
+)
+
+func newBool(b bool) *bool {
+	return &b
+}
+
+// additionalSchema returns the schema of an additionalProperties or additionalItems keyword
+func additionalSchema(additional interface{}) *subSchema {
+	switch a := additional.(type) {
+	case bool:
+		if !a {
+			return falseSchema
+		}
+	case *subSchema:
+		return a
+	}
+	return trueSchema
+}
+
+// compare reports the changes from a to b, the schemas at oldLocation and newLocation
+func (c *schemaComparer) compare(a *subSchema, b *subSchema, oldLocation string, newLocation string) {
+	for a.refSchema != nil {
+		oldLocation = schemaLocation(a.ref.String())
+		a = a.refSchema
+	}
+	for b.refSchema != nil {
+		newLocation = schemaLocation(b.ref.String())
+		b = b.refSchema
+	}
+
+	key := comparison{a, b, c.mode}
+	if c.visited[key] {
+		return
+	}
+	c.visited[key] = true
+
+	aFalse := a.pass != nil && !*a.pass
+	bFalse := b.pass != nil && !*b.pass
+	switch {
+	case aFalse && bFalse:
+		return
+	case bFalse:
+		c.report("", tightened, oldLocation, newLocation, "the schema no longer accepts any value")
+		return
+	case aFalse:
+		c.report("", loosened, oldLocation, newLocation, "the schema accepts values, where it accepted none")
+		return
+	}
+
+	l := &locations{c: c, old: oldLocation, new: newLocation}
+	c.compareTypes(a, b, l)
+	c.compareValues(a, b, l)
+	c.compareNumbers(a, b, l)
+	c.compareStrings(a, b, l)
+	c.compareObjects(a, b, l)
+	c.compareArrays(a, b, l)
+	c.compareCompositions(a, b, l)
+}
+
+// locations are the locations of the compared schemas
+type locations struct {
+	c   *schemaComparer
+	old string
+	new string
+}
+
+func (l *locations) report(keyword string, e effect, format string, args ...interface{}) {
+	l.c.report(keyword, e, l.old, l.new, format, args...)
+}
+
+// child returns the locations of the subschemas at the JSON Pointer tokens
+func (l *locations) child(tokens ...string) (string, string) {
+	pointer := ""
+	for _, token := range tokens {
+		pointer += "/" + escapePointerToken(token)
+	}
+	return l.old + pointer, l.new + pointer
+}
+
+// compareTypes compares the type keywords of a and b
+func (c *schemaComparer) compareTypes(a *subSchema, b *subSchema, l *locations) {
+	covers := func(types jsonSchemaType, t string) bool {
+		return !types.IsTyped() || types.Contains(t) || t == TYPE_INTEGER && types.Contains(TYPE_NUMBER)
+	}
+	widened, narrowed := false, false
+	if !b.types.IsTyped() {
+		widened = a.types.IsTyped()
+	}
+	if !a.types.IsTyped() {
+		narrowed = b.types.IsTyped()
+	}
+	for _, t := range a.types.types {
+		if !covers(b.types, t) {
+			narrowed = true
+		}
+	}
+	for _, t := range b.types.types {
+		if !covers(a.types, t) {
+			widened = true
+		}
+	}
+
+	typeString := func(types jsonSchemaType) string {
+		if !types.IsTyped() {
+			return "any"
+		}
+		return types.String()
+	}
+	switch {
+	case widened && narrowed:
+		l.report(KEY_TYPE, changed, "the type changed from %s to %s", typeString(a.types), typeString(b.types))
+	case widened:
+		l.report(KEY_TYPE, loosened, "the type widened from %s to %s", typeString(a.types), typeString(b.types))
+	case narrowed:
+		l.report(KEY_TYPE, tightened, "the type narrowed from %s to %s", typeString(a.types), typeString(b.types))
+	}
+}
+
+// allowedValues returns the values a schema accepts by its enum and const, nil if they accept any value
+func allowedValues(s *subSchema) []string {
+	switch {
+	case s.enum != nil && s._const != nil:
+		if isStringInSlice(s.enum, *s._const) {
+			return []string{*s._const}
+		}
+		return []string{}
+	case s._const != nil:
+		return []string{*s._const}
+	}
+	return s.enum
+}
+
+// compareValues compares the enum and const keywords of a and b
+func (c *schemaComparer) compareValues(a *subSchema, b *subSchema, l *locations) {
+	keyword := KEY_ENUM
+	if b._const != nil || b.enum == nil && a._const != nil {
+		keyword = KEY_CONST
+	}
+
+	x, y := allowedValues(a), allowedValues(b)
+	switch {
+	case x == nil && y == nil:
+		return
+	case x == nil:
+		l.report(keyword, tightened, "the values are restricted to %s", strings.Join(y, ", "))
+		return
+	case y == nil:
+		l.report(keyword, loosened, "the values are no longer restricted to %s", strings.Join(x, ", "))
+		return
+	}
+
+	var removed, added []string
+	for _, v := range x {
+		if !isStringInSlice(y, v) {
+			removed = append(removed, v)
+		}
+	}
+	for _, v := range y {
+		if !isStringInSlice(x, v) {
+			added = append(added, v)
+		}
+	}
+	switch {
+	case len(removed) > 0 && len(added) > 0:
+		l.report(keyword, changed, "the values %s were removed and %s added", strings.Join(removed, ", "), strings.Join(added, ", "))
+	case len(removed) > 0:
+		l.report(keyword, tightened, "the values %s were removed", strings.Join(removed, ", "))
+	case len(added) > 0:
+		l.report(keyword, loosened, "the values %s were added", strings.Join(added, ", "))
+	}
+}
+
+// numberBound returns the tighter of a bound and an exclusive bound, and its keyword
+func numberBound(value *big.Rat, keyword string, exclusiveValue *big.Rat, exclusiveKeyword string, lower bool) (*big.Rat, bool, string) {
+	switch {
+	case value == nil && exclusiveValue == nil:
+		return nil, false, keyword
+	case value == nil:
+		return exclusiveValue, true, exclusiveKeyword
+	case exclusiveValue == nil:
+		return value, false, keyword
+	}
+	if c := exclusiveValue.Cmp(value); c == 0 || (c > 0) == lower {
+		return exclusiveValue, true, exclusiveKeyword
+	}
+	return value, false, keyword
+}
+
+// compareNumbers compares the number keywords of a and b
+func (c *schemaComparer) compareNumbers(a *subSchema, b *subSchema, l *locations) {
+	for _, lower := range []bool{true, false} {
+		var x, y *big.Rat
+		var xExclusive, yExclusive bool
+		var xKeyword, yKeyword string
+		if lower {
+			x, xExclusive, xKeyword = numberBound(a.minimum, KEY_MINIMUM, a.exclusiveMinimum, KEY_EXCLUSIVE_MINIMUM, true)
+			y, yExclusive, yKeyword = numberBound(b.minimum, KEY_MINIMUM, b.exclusiveMinimum, KEY_EXCLUSIVE_MINIMUM, true)
+		} else {
+			x, xExclusive, xKeyword = numberBound(a.maximum, KEY_MAXIMUM, a.exclusiveMaximum, KEY_EXCLUSIVE_MAXIMUM, false)
+			y, yExclusive, yKeyword = numberBound(b.maximum, KEY_MAXIMUM, b.exclusiveMaximum, KEY_EXCLUSIVE_MAXIMUM, false)
+		}
+
+		switch {
+		case x == nil && y == nil:
+		case x == nil:
+			l.report(yKeyword, tightened, "%s %s was added", yKeyword, y.RatString())
+		case y == nil:
+			l.report(xKeyword, loosened, "%s %s was removed", xKeyword, x.RatString())
+		default:
+			cmp := y.Cmp(x)
+			if !lower {
+				cmp = -cmp
+			}
+			if cmp == 0 && xExclusive != yExclusive {
+				cmp = -1
+				if yExclusive {
+					cmp = 1
+				}
+			}
+			if cmp > 0 {
+				l.report(yKeyword, tightened, "%s %s was tightened to %s %s", xKeyword, x.RatString(), yKeyword, y.RatString())
+			} else if cmp < 0 {
+				l.report(yKeyword, loosened, "%s %s was relaxed to %s %s", xKeyword, x.RatString(), yKeyword, y.RatString())
+			}
+		}
+	}
+
+	x, y := a.multipleOf, b.multipleOf
+	switch {
+	case x == nil && y == nil:
+	case x == nil:
+		l.report(KEY_MULTIPLE_OF, tightened, "multipleOf %s was added", y.RatString())
+	case y == nil:
+		l.report(KEY_MULTIPLE_OF, loosened, "multipleOf %s was removed", x.RatString())
+	case x.Cmp(y) != 0:
+		switch {
+		case new(big.Rat).Quo(y, x).IsInt():
+			l.report(KEY_MULTIPLE_OF, tightened, "multipleOf changed from %s to %s", x.RatString(), y.RatString())
+		case new(big.Rat).Quo(x, y).IsInt():
+			l.report(KEY_MULTIPLE_OF, loosened, "multipleOf changed from %s to %s", x.RatString(), y.RatString())
+		default:
+			l.report(KEY_MULTIPLE_OF, changed, "multipleOf changed from %s to %s", x.RatString(), y.RatString())
+		}
+	}
+}
+
+// compareCount compares a minimum or maximum count like minLength or maxItems
+func (l *locations) compareCount(keyword string, x *int, y *int, lower bool) {
+	switch {
+	case x == nil && y == nil:
+	case x == nil:
+		if !lower || *y > 0 {
+			l.report(keyword, tightened, "%s %d was added", keyword, *y)
+		}
+	case y == nil:
+		if !lower || *x > 0 {
+			l.report(keyword, loosened, "%s %d was removed", keyword, *x)
+		}
+	case *x != *y:
+		if (*y > *x) == lower {
+			l.report(keyword, tightened, "%s was tightened from %d to %d", keyword, *x, *y)
+		} else {
+			l.report(keyword, loosened, "%s was relaxed from %d to %d", keyword, *x, *y)
+		}
+	}
+}
+
+// compareStrings compares the string keywords of a and b
+func (c *schemaComparer) compareStrings(a *subSchema, b *subSchema, l *locations) {
+	l.compareCount(KEY_MIN_LENGTH, a.minLength, b.minLength, true)
+	l.compareCount(KEY_MAX_LENGTH, a.maxLength, b.maxLength, false)
+
+	pattern := func(s *subSchema) string {
+		if s.pattern == nil {
+			return ""
+		}
+		return s.pattern.String()
+	}
+	l.compareString(KEY_PATTERN, pattern(a), pattern(b))
+	l.compareString(KEY_FORMAT, a.format, b.format)
+}
+
+// compareString compares a keyword like pattern, that can only be compared for equality
+func (l *locations) compareString(keyword string, x string, y string) {
+	switch {
+	case x == y:
+	case x == "":
+		l.report(keyword, tightened, "%s %q was added", keyword, y)
+	case y == "":
+		l.report(keyword, loosened, "%s %q was removed", keyword, x)
+	default:
+		l.report(keyword, changed, "%s changed from %q to %q", keyword, x, y)
+	}
+}
+
+// propertySchema returns the schema s applies to the property name, and the location of that schema
+func propertySchema(s *subSchema, name string, location string) (*subSchema, string) {
+	if p, ok := s.propertiesByName(name); ok {
+		return p, location + "/" + KEY_PROPERTIES + "/" + escapePointerToken(name)
+	}
+	patterns := make([]string, 0, len(s.patternProperties))
+	for pattern := range s.patternProperties {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if matches, _ := regexp.MatchString(pattern, name); matches {
+			return s.patternProperties[pattern], location + "/" + KEY_PATTERN_PROPERTIES + "/" + escapePointerToken(pattern)
+		}
+	}
+	return additionalSchema(s.additionalProperties), location + "/" + KEY_ADDITIONAL_PROPERTIES
+}
+
+// compareObjects compares the object keywords of a and b
+func (c *schemaComparer) compareObjects(a *subSchema, b *subSchema, l *locations) {
+	l.compareCount(KEY_MIN_PROPERTIES, a.minProperties, b.minProperties, true)
+	l.compareCount(KEY_MAX_PROPERTIES, a.maxProperties, b.maxProperties, false)
+
+	for _, name := range b.required {
+		if !isStringInSlice(a.required, name) {
+			l.report(KEY_REQUIRED, tightened, "the property %q is now required", name)
+		}
+	}
+	for _, name := range a.required {
+		if !isStringInSlice(b.required, name) {
+			l.report(KEY_REQUIRED, loosened, "the property %q is no longer required", name)
+		}
+	}
+
+	// properties
+	names := make(map[string]interface{})
+	for _, p := range a.propertiesChildren {
+		names[p.property] = true
+	}
+	for _, p := range b.propertiesChildren {
+		names[p.property] = true
+	}
+	for _, name := range sortedKeys(names) {
+		x, oldLocation := propertySchema(a, name, l.old)
+		y, newLocation := propertySchema(b, name, l.new)
+		_, inOld := a.propertiesByName(name)
+		_, inNew := b.propertiesByName(name)
+		if inOld && inNew {
+			c.compare(x, y, oldLocation, newLocation)
+			continue
+		}
+		// the property is now validated by patternProperties or additionalProperties, or was
+		e := c.aggregate(x, y, oldLocation, newLocation)
+		if inOld {
+			l.report(KEY_PROPERTIES, e, "the property %q was removed", name)
+		} else {
+			l.report(KEY_PROPERTIES, e, "the property %q was added", name)
+		}
+	}
+
+	// patternProperties
+	patterns := make(map[string]interface{})
+	for pattern := range a.patternProperties {
+		patterns[pattern] = true
+	}
+	for pattern := range b.patternProperties {
+		patterns[pattern] = true
+	}
+	for _, pattern := range sortedKeys(patterns) {
+		oldLocation, newLocation := l.child(KEY_PATTERN_PROPERTIES, pattern)
+		x, inOld := a.patternProperties[pattern]
+		y, inNew := b.patternProperties[pattern]
+		if inOld && inNew {
+			c.compare(x, y, oldLocation, newLocation)
+			continue
+		}
+		if !inOld {
+			x, oldLocation = additionalSchema(a.additionalProperties), l.old+"/"+KEY_ADDITIONAL_PROPERTIES
+		} else {
+			y, newLocation = additionalSchema(b.additionalProperties), l.new+"/"+KEY_ADDITIONAL_PROPERTIES
+		}
+		e := c.aggregate(x, y, oldLocation, newLocation)
+		if inOld {
+			l.report(KEY_PATTERN_PROPERTIES, e, "the pattern %q was removed", pattern)
+		} else {
+			l.report(KEY_PATTERN_PROPERTIES, e, "the pattern %q was added", pattern)
+		}
+	}
+
+	// additionalProperties
+	x, y := additionalSchema(a.additionalProperties), additionalSchema(b.additionalProperties)
+	switch {
+	case y == falseSchema && x != falseSchema:
+		l.report(KEY_ADDITIONAL_PROPERTIES, tightened, "additional properties are no longer allowed")
+	case x == falseSchema && y != falseSchema:
+		l.report(KEY_ADDITIONAL_PROPERTIES, loosened, "additional properties are allowed")
+	default:
+		oldLocation, newLocation := l.child(KEY_ADDITIONAL_PROPERTIES)
+		c.compare(x, y, oldLocation, newLocation)
+	}
+
+	// propertyNames
+	x, y = trueSchema, trueSchema
+	if a.propertyNames != nil {
+		x = a.propertyNames
+	}
+	if b.propertyNames != nil {
+		y = b.propertyNames
+	}
+	oldLocation, newLocation := l.child(KEY_PROPERTY_NAMES)
+	c.compare(x, y, oldLocation, newLocation)
+
+	c.compareDependencies(a, b, l)
+}
+
+// propertiesByName returns the schema of the property name, declared in properties
+func (v *subSchema) propertiesByName(name string) (*subSchema, bool) {
+	for _, p := range v.propertiesChildren {
+		if p.property == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// compareDependencies compares the dependencies keywords of a and b
+func (c *schemaComparer) compareDependencies(a *subSchema, b *subSchema, l *locations) {
+	names := make(map[string]interface{})
+	for name := range a.dependencies {
+		names[name] = true
+	}
+	for name := range b.dependencies {
+		names[name] = true
+	}
+
+	for _, name := range sortedKeys(names) {
+		x, inOld := a.dependencies[name]
+		y, inNew := b.dependencies[name]
+		switch {
+		case !inOld:
+			l.report(KEY_DEPENDENCIES, tightened, "a dependency of the property %q was added", name)
+			continue
+		case !inNew:
+			l.report(KEY_DEPENDENCIES, loosened, "the dependency of the property %q was removed", name)
+			continue
+		}
+
+		xSchema, xIsSchema := x.(*subSchema)
+		ySchema, yIsSchema := y.(*subSchema)
+		xNames, _ := x.([]string)
+		yNames, _ := y.([]string)
+		switch {
+		case xIsSchema && yIsSchema:
+			oldLocation, newLocation := l.child(KEY_DEPENDENCIES, name)
+			c.compare(xSchema, ySchema, oldLocation, newLocation)
+		case xIsSchema || yIsSchema:
+			l.report(KEY_DEPENDENCIES, changed, "the dependency of the property %q changed between properties and a schema", name)
+		default:
+			for _, n := range yNames {
+				if !isStringInSlice(xNames, n) {
+					l.report(KEY_DEPENDENCIES, tightened, "the property %q now requires %q", name, n)
+				}
+			}
+			for _, n := range xNames {
+				if !isStringInSlice(yNames, n) {
+					l.report(KEY_DEPENDENCIES, loosened, "the property %q no longer requires %q", name, n)
+				}
+			}
+		}
+	}
+}
+
+// itemSchema returns the schema s applies to the item at index, and the location of that schema
+func itemSchema(s *subSchema, index int, location string) (*subSchema, string) {
+	switch {
+	case s.itemsChildrenIsSingleSchema:
+		return s.itemsChildren[0], location + "/" + KEY_ITEMS
+	case index < len(s.itemsChildren):
+		return s.itemsChildren[index], location + "/" + KEY_ITEMS + "/" + strconv.Itoa(index)
+	case len(s.itemsChildren) == 0:
+		// additionalItems has no effect without items
+		return trueSchema, location + "/" + KEY_ITEMS
+	}
+	return additionalSchema(s.additionalItems), location + "/" + KEY_ADDITIONAL_ITEMS
+}
+
+// compareArrays compares the array keywords of a and b
+func (c *schemaComparer) compareArrays(a *subSchema, b *subSchema, l *locations) {
+	l.compareCount(KEY_MIN_ITEMS, a.minItems, b.minItems, true)
+	l.compareCount(KEY_MAX_ITEMS, a.maxItems, b.maxItems, false)
+
+	if a.uniqueItems != b.uniqueItems {
+		if b.uniqueItems {
+			l.report(KEY_UNIQUE_ITEMS, tightened, "the items must be unique")
+		} else {
+			l.report(KEY_UNIQUE_ITEMS, loosened, "the items no longer need to be unique")
+		}
+	}
+
+	// items, by index up to the longest tuple, and then the items after the tuples
+	n := 0
+	for _, s := range []*subSchema{a, b} {
+		if !s.itemsChildrenIsSingleSchema && len(s.itemsChildren) > n {
+			n = len(s.itemsChildren)
+		}
+	}
+	for i := 0; i <= n; i++ {
+		x, oldLocation := itemSchema(a, i, l.old)
+		y, newLocation := itemSchema(b, i, l.new)
+		c.compare(x, y, oldLocation, newLocation)
+	}
+
+	switch {
+	case a.contains == nil && b.contains == nil:
+	case a.contains == nil:
+		l.report(KEY_CONTAINS, tightened, "contains was added")
+	case b.contains == nil:
+		l.report(KEY_CONTAINS, loosened, "contains was removed")
+	default:
+		oldLocation, newLocation := l.child(KEY_CONTAINS)
+		c.compare(a.contains, b.contains, oldLocation, newLocation)
+	}
+}
+
+// compareCompositions compares allOf, anyOf, oneOf, not and if, then and else of a and b
+func (c *schemaComparer) compareCompositions(a *subSchema, b *subSchema, l *locations) {
+	for _, composition := range []struct {
+		keyword string
+		x, y    []*subSchema
+		mode    compareMode
+		// more is the effect of adding a branch
+		more effect
+	}{
+		{KEY_ALL_OF, a.allOf, b.allOf, compareNormal, tightened},
+		{KEY_ANY_OF, a.anyOf, b.anyOf, compareNormal, loosened},
+		{KEY_ONE_OF, a.oneOf, b.oneOf, compareUnknown, changed},
+	} {
+		x, y := composition.x, composition.y
+		for i := 0; i < len(x) && i < len(y); i++ {
+			oldLocation, newLocation := l.child(composition.keyword, strconv.Itoa(i))
+			c.nested(composition.mode, x[i], y[i], oldLocation, newLocation)
+		}
+		switch {
+		case len(x) == 0 && len(y) > 0 && composition.keyword != KEY_ALL_OF:
+			// any and one of no branches is not a valid schema, adding one restricts the schema to it
+			l.report(composition.keyword, tightened, "%s was added", composition.keyword)
+		case len(y) == 0 && len(x) > 0 && composition.keyword != KEY_ALL_OF:
+			l.report(composition.keyword, loosened, "%s was removed", composition.keyword)
+		case len(y) > len(x):
+			l.report(composition.keyword, composition.more, "%d branches were added to %s", len(y)-len(x), composition.keyword)
+		case len(x) > len(y):
+			e := composition.more
+			switch e {
+			case tightened:
+				e = loosened
+			case loosened:
+				e = tightened
+			}
+			l.report(composition.keyword, e, "%d branches were removed from %s", len(x)-len(y), composition.keyword)
+		}
+	}
+
+	switch {
+	case a.not == nil && b.not == nil:
+	case a.not == nil:
+		l.report(KEY_NOT, tightened, "not was added")
+	case b.not == nil:
+		l.report(KEY_NOT, loosened, "not was removed")
+	default:
+		oldLocation, newLocation := l.child(KEY_NOT)
+		c.nested(compareInverted, a.not, b.not, oldLocation, newLocation)
+	}
+
+	switch {
+	case a._if == nil && b._if == nil:
+	case a._if == nil:
+		l.report(KEY_IF, changed, "if was added")
+	case b._if == nil:
+		l.report(KEY_IF, changed, "if was removed")
+	default:
+		oldLocation, newLocation := l.child(KEY_IF)
+		c.nested(compareUnknown, a._if, b._if, oldLocation, newLocation)
+		for _, branch := range []struct {
+			keyword string
+			x, y    *subSchema
+		}{
+			{KEY_THEN, a._then, b._then},
+			{KEY_ELSE, a._else, b._else},
+		} {
+			x, y := branch.x, branch.y
+			if x == nil {
+				x = trueSchema
+			}
+			if y == nil {
+				y = trueSchema
+			}
+			oldLocation, newLocation := l.child(branch.keyword)
+			c.compare(x, y, oldLocation, newLocation)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package gojsonschema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSchemas(t *testing.T) {
+	base := writeSchemaFiles(t, map[string]string{
+		"v1/event.json": `{
+			"type": "object",
+			"properties": {
+				"id": {"type": "string", "maxLength": 10},
+				"kind": {"enum": ["a", "b", "c"]},
+				"count": {"$ref": "types.json#/definitions/count"},
+				"legacy": {"type": "string"},
+				"tags": {"type": "array", "items": {"type": "string"}},
+				"tree": {"$ref": "#/definitions/tree"}
+			},
+			"required": ["id"],
+			"definitions": {"tree": {"properties": {"children": {"items": {"$ref": "#/definitions/tree"}}}}}
+		}`,
+		"v1/types.json": `{"definitions": {"count": {"type": "integer", "minimum": 0}}}`,
+		"v2/event.json": `{
+			"type": "object",
+			"properties": {
+				"id": {"type": "string", "maxLength": 8},
+				"kind": {"enum": ["a", "b"]},
+				"count": {"$ref": "types.json#/definitions/count"},
+				"tags": {"type": "array", "items": {"type": ["string", "null"]}},
+				"tree": {"$ref": "#/definitions/tree"}
+			},
+			"required": ["id", "kind"],
+			"additionalProperties": false,
+			"definitions": {"tree": {"properties": {"children": {"items": {"$ref": "#/definitions/tree"}}, "name": {"type": "string"}}}}
+		}`,
+		"v2/types.json": `{"definitions": {"count": {"type": "integer", "exclusiveMinimum": 0}}}`,
+	})
+
+	oldSchema, err := NewSchema(NewReferenceLoader(base + "v1/event.json"))
+	assert.Nil(t, err)
+	newSchema, err := NewSchema(NewReferenceLoader(base + "v2/event.json"))
+	assert.Nil(t, err)
+
+	changes := CompareSchemas(oldSchema, newSchema)
+	var actual []string
+	for _, c := range changes {
+		actual = append(actual, strings.ReplaceAll(c.String(), base, ""))
+	}
+	assert.Equal(t, []string{
+		`v2/event.json#: forward [required] the property "kind" is now required`,
+		"v2/types.json#/definitions/count: forward [exclusiveMinimum] minimum 0 was tightened to exclusiveMinimum 0",
+		"v2/event.json#/properties/id: forward [maxLength] maxLength was tightened from 10 to 8",
+		`v2/event.json#/properties/kind: forward [enum] the values "c" were removed`,
+		`v2/event.json#: forward [properties] the property "legacy" was removed`,
+		`v2/event.json#/properties/tags/items: backward [type] the type widened from string to [string,null]`,
+		`v2/event.json#/definitions/tree: forward [properties] the property "name" was added`,
+		"v2/event.json#: forward [additionalProperties] additional properties are no longer allowed",
+	}, actual)
+	assert.Equal(t, CompatibilityNone, changes.Compatibility())
+
+	assert.Empty(t, CompareSchemas(oldSchema, oldSchema))
+}
+
+func TestCompareSchemasCompatibility(t *testing.T) {
+	cases := []struct {
+		old, new string
+		expected Compatibility
+	}{
+		{`{"type": "integer"}`, `{"type": "number"}`, CompatibilityBackward},
+		{`{"type": "number"}`, `{"type": "integer"}`, CompatibilityForward},
+		{`{"type": "string"}`, `{"type": "integer"}`, CompatibilityNone},
+		{`{"type": "string"}`, `{"type": "string", "title": "changed"}`, CompatibilityFull},
+		{`{"minimum": 1, "maximum": 5}`, `{"minimum": 0, "maximum": 6}`, CompatibilityBackward},
+		{`{"maximum": 5}`, `{"maximum": 5, "exclusiveMaximum": true}`, CompatibilityForward},
+		{`{"multipleOf": 2}`, `{"multipleOf": 4}`, CompatibilityForward},
+		{`{"multipleOf": 2}`, `{"multipleOf": 3}`, CompatibilityNone},
+		{`{"minLength": 0}`, `{}`, CompatibilityFull},
+		{`{"const": "a"}`, `{"enum": ["a", "b"]}`, CompatibilityBackward},
+		{`{"pattern": "^a"}`, `{"pattern": "^b"}`, CompatibilityNone},
+		{`{"required": ["a", "b"]}`, `{"required": ["a"]}`, CompatibilityBackward},
+		{`{"properties": {"a": {}}}`, `{}`, CompatibilityFull},
+		{`{"properties": {"a": {"type": "string"}}, "additionalProperties": {"type": "string"}}`, `{"additionalProperties": {"type": "string"}}`, CompatibilityFull},
+		{`{"additionalProperties": false}`, `{"properties": {"a": {"type": "string"}}, "additionalProperties": false}`, CompatibilityBackward},
+		{`{"patternProperties": {"^x-": {"type": "string"}}}`, `{}`, CompatibilityBackward},
+		{`{"items": [{"type": "string"}], "additionalItems": false}`, `{"items": [{"type": "string"}, {"type": "integer"}], "additionalItems": false}`, CompatibilityBackward},
+		{`{"items": {"type": "string"}}`, `{"items": [{"type": "string"}], "additionalItems": false}`, CompatibilityForward},
+		{`{"uniqueItems": true}`, `{}`, CompatibilityBackward},
+		{`{"not": {"type": "string"}}`, `{"not": {"type": ["string", "null"]}}`, CompatibilityForward},
+		{`{"anyOf": [{"type": "string"}]}`, `{"anyOf": [{"type": "string"}, {"type": "null"}]}`, CompatibilityBackward},
+		{`{"allOf": [{"minimum": 1}]}`, `{"allOf": [{"minimum": 2}]}`, CompatibilityForward},
+		{`{"oneOf": [{"minimum": 1}, {"maximum": 0}]}`, `{"oneOf": [{"minimum": 2}, {"maximum": 0}]}`, CompatibilityNone},
+		{`{"if": {"minimum": 1}, "then": {"multipleOf": 2}}`, `{"if": {"minimum": 1}, "then": {"multipleOf": 4}}`, CompatibilityForward},
+		{`{"dependencies": {"a": ["b"]}}`, `{"dependencies": {"a": ["b", "c"]}}`, CompatibilityForward},
+		{`true`, `false`, CompatibilityForward},
+		{`false`, `{"type": "string"}`, CompatibilityBackward},
+	}
+
+	for _, c := range cases {
+		oldSchema, err := NewSchema(NewStringLoader(c.old))
+		assert.Nil(t, err)
+		newSchema, err := NewSchema(NewStringLoader(c.new))
+		assert.Nil(t, err)
+		changes := CompareSchemas(oldSchema, newSchema)
+		assert.Equal(t, c.expected, changes.Compatibility(), "%s -> %s: %v", c.old, c.new, changes)
+	}
+}
+
+// The comparison of recursive schemas ends, also when a property is added or removed
+func TestCompareSchemasRecursive(t *testing.T) {
+	cases := []struct {
+		old, new string
+		expected Compatibility
+	}{
+		{`{"properties": {"next": {"$ref": "#"}}}`, `{"properties": {"next": {"$ref": "#"}, "other": {"$ref": "#"}}}`, CompatibilityFull},
+		{`{"properties": {"next": {"$ref": "#"}}, "additionalProperties": false}`, `{"properties": {"next": {"$ref": "#"}, "other": {"$ref": "#"}}, "additionalProperties": false}`, CompatibilityBackward},
+		{`{"properties": {"next": {"$ref": "#"}, "other": {"$ref": "#"}}, "additionalProperties": false}`, `{"properties": {"next": {"$ref": "#"}}, "additionalProperties": false}`, CompatibilityForward},
+	}
+
+	for _, c := range cases {
+		oldSchema, err := NewSchema(NewStringLoader(c.old))
+		assert.Nil(t, err)
+		newSchema, err := NewSchema(NewStringLoader(c.new))
+		assert.Nil(t, err)
+		changes := CompareSchemas(oldSchema, newSchema)
+		assert.Equal(t, c.expected, changes.Compatibility(), "%s -> %s: %v", c.old, c.new, changes)
+	}
+}
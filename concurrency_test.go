@@ -0,0 +1,107 @@
+package gojsonschema
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// These tests are most useful when run with the race detector: go test -race
+
+const concurrencyGoroutines = 16
+
+func TestConcurrentSchemaLoader(t *testing.T) {
+	sl := NewSchemaLoader()
+	sl.Validate = true
+	err := sl.AddSchema("http://localhost:1234/concurrency/base.json", NewStringLoader(`{
+		"$schema" : "http://json-schema.org/draft-07/schema#",
+		"definitions" : { "name" : { "type" : "string", "minLength" : 1 } }
+	}`))
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrencyGoroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Schemas without a reference, which all live at the empty reference
+			schema, err := sl.Compile(NewStringLoader(fmt.Sprintf(`{
+				"$schema" : "http://json-schema.org/draft-07/schema#",
+				"properties" : {
+					"name" : { "$ref" : "http://localhost:1234/concurrency/base.json#/definitions/name" },
+					"index" : { "$ref" : "#/definitions/index" }
+				},
+				"definitions" : { "index" : { "const" : %d } }
+			}`, i)))
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			result, err := schema.Validate(NewStringLoader(fmt.Sprintf(`{"name" : "a", "index" : %d}`, i)))
+			assert.Nil(t, err)
+			assert.True(t, result.Valid(), "%v", result.Errors())
+
+			// Schemas added and compiled by reference
+			url := fmt.Sprintf("http://localhost:1234/concurrency/%d.json", i)
+			err = sl.AddSchemas(NewStringLoader(fmt.Sprintf(`{
+				"$id" : %q,
+				"$ref" : "http://localhost:1234/concurrency/base.json#/definitions/name"
+			}`, url)))
+			if !assert.Nil(t, err) {
+				return
+			}
+			schema, err = sl.Compile(NewReferenceLoader(url))
+			if !assert.Nil(t, err) {
+				return
+			}
+			result, err = schema.Validate(NewStringLoader(`""`))
+			assert.Nil(t, err)
+			assert.False(t, result.Valid())
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestConcurrentValidation(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"type" : "array",
+		"items" : {
+			"type" : "object",
+			"properties" : {
+				"id" : { "type" : "integer", "minimum" : 0 },
+				"tags" : { "type" : "array", "items" : { "type" : "string", "pattern" : "^[a-z]+$" }, "uniqueItems" : true },
+				"child" : { "$ref" : "#/items" }
+			},
+			"required" : ["id"],
+			"oneOf" : [ { "required" : ["tags"] }, { "required" : ["child"] } ]
+		}
+	}`))
+	assert.Nil(t, err)
+
+	valid := NewStringLoader(`[{"id" : 1, "tags" : ["a", "b"]}, {"id" : 2, "child" : {"id" : 3, "tags" : []}}]`)
+	invalid := NewStringLoader(`[{"id" : -1, "tags" : ["a", "a", "B"]}, {"id" : 2}]`)
+
+	expected, err := schema.Validate(invalid)
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrencyGoroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				result, err := schema.Validate(valid)
+				assert.Nil(t, err)
+				assert.True(t, result.Valid())
+
+				result, err = schema.Validate(invalid)
+				assert.Nil(t, err)
+				assert.Equal(t, len(expected.Errors()), len(result.Errors()))
+			}
+		}()
+	}
+	wg.Wait()
+}
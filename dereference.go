@@ -0,0 +1,491 @@
+package gojsonschema
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonpointer"
+)
+
+// DereferenceOptions configures SchemaLoader.Dereference
+type DereferenceOptions struct {
+	// MergeAllOf merges the branches of allOf into the schema containing them, where that
+	// doesn't change the documents it validates. Branches that can't be merged are kept in allOf
+	MergeAllOf bool
+}
+
+// RecursiveReference is a $ref that Dereference left in place, as inlining its target would never end
+type RecursiveReference struct {
+	// Pointer is the location of the $ref in the dereferenced schema
+	Pointer string
+	// Ref is the $ref, a JSON Pointer into the dereferenced schema
+	Ref string
+}
+
+// Dereference returns root with every $ref replaced by the schema it points to, see SchemaLoader.Dereference
+func Dereference(root JSONLoader, options DereferenceOptions) (interface{}, []RecursiveReference, error) {
+	return NewSchemaLoader().Dereference(root, options)
+}
+
+// Dereference compiles root, and returns it with every $ref replaced by a copy of the schema it points to,
+// for tools that can't follow references. References are resolved like Bundle resolves them.
+// Keywords next to a $ref are ignored by draft 7 and older, so they are dropped along with it.
+//
+// A recursive $ref, pointing to a schema that contains it, is kept as a JSON Pointer and reported.
+// The definitions these pointers need are kept as well, every other definition is removed
+func (sl *SchemaLoader) Dereference(root JSONLoader, options DereferenceOptions) (interface{}, []RecursiveReference, error) {
+	bundle, err := sl.Bundle(root, BundleOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	bundleMap, ok := bundle.(map[string]interface{})
+	if !ok {
+		return bundle, nil, nil
+	}
+
+	d := &dereferencer{bundle: bundleMap, kept: make(map[string]bool)}
+	result, err := d.schema(bundleMap, "", "", []string{""})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// add the definitions recursive references point into, and the ones those point into
+	definitions, _ := bundleMap[KEY_DEFINITIONS].(map[string]interface{})
+	keptDefinitions := make(map[string]interface{})
+	for {
+		names := d.keptDefinitions(definitions, keptDefinitions)
+		if len(names) == 0 {
+			break
+		}
+		for _, name := range names {
+			pointer := "/" + KEY_DEFINITIONS + "/" + escapePointerToken(name)
+			schema, err := d.schema(definitions[name], pointer, pointer, []string{"", pointer})
+			if err != nil {
+				return nil, nil, err
+			}
+			keptDefinitions[name] = schema
+		}
+	}
+
+	if resultMap, ok := result.(map[string]interface{}); ok && len(keptDefinitions) > 0 {
+		resultMap[KEY_DEFINITIONS] = keptDefinitions
+	}
+
+	// merging comes last, once every recursive reference is known
+	if options.MergeAllOf {
+		d.merge(result, "")
+	}
+	return result, d.recursive, nil
+}
+
+// dereferencer inlines the references of a bundle
+type dereferencer struct {
+	bundle    map[string]interface{}
+	recursive []RecursiveReference
+	// kept are the pointers of the recursive references
+	kept map[string]bool
+}
+
+// schema returns a copy of the schema at location in the bundle, with its references inlined.
+// path is the location of the copy in the result, and ancestors are the locations of the schemas containing it
+func (d *dereferencer) schema(node interface{}, location string, path string, ancestors []string) (interface{}, error) {
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		return node, nil
+	}
+
+	if ref, ok := m[KEY_REF].(string); ok {
+		_, pointer := splitReference(ref)
+		for _, ancestor := range ancestors {
+			if ancestor == pointer {
+				d.recursive = append(d.recursive, RecursiveReference{Pointer: path, Ref: ref})
+				d.kept[pointer] = true
+				return map[string]interface{}{KEY_REF: ref}, nil
+			}
+		}
+		jsonPointer, err := gojsonpointer.NewJsonPointer(pointer)
+		if err != nil {
+			return nil, err
+		}
+		target, _, err := jsonPointer.Get(d.bundle)
+		if err != nil {
+			return nil, err
+		}
+		return d.schema(target, pointer, path, append(ancestors, pointer))
+	}
+
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		childLocation := location + "/" + escapePointerToken(k)
+		childPath := path + "/" + escapePointerToken(k)
+		var err error
+
+		switch k {
+		case KEY_DEFINITIONS:
+			// only the definitions recursive references point into are kept, by Dereference
+			if location == "" {
+				continue
+			}
+			result[k], err = d.schemaMap(v, childLocation, childPath, ancestors)
+		case KEY_PROPERTIES, KEY_PATTERN_PROPERTIES, KEY_DEPENDENCIES:
+			result[k], err = d.schemaMap(v, childLocation, childPath, ancestors)
+		case KEY_ITEMS, KEY_ALL_OF, KEY_ANY_OF, KEY_ONE_OF:
+			if list, ok := v.([]interface{}); ok {
+				items := make([]interface{}, len(list))
+				for i, item := range list {
+					index := "/" + strconv.Itoa(i)
+					if items[i], err = d.schema(item, childLocation+index, childPath+index, append(ancestors, childLocation+index)); err != nil {
+						return nil, err
+					}
+				}
+				result[k] = items
+				continue
+			}
+			result[k], err = d.schema(v, childLocation, childPath, append(ancestors, childLocation))
+		case KEY_ADDITIONAL_ITEMS, KEY_ADDITIONAL_PROPERTIES, KEY_PROPERTY_NAMES, KEY_CONTAINS, KEY_NOT, KEY_IF, KEY_THEN, KEY_ELSE:
+			result[k], err = d.schema(v, childLocation, childPath, append(ancestors, childLocation))
+		default:
+			result[k] = copyDocument(v)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// schemaMap dereferences the schemas of a keyword like properties, whose values are schemas
+func (d *dereferencer) schemaMap(node interface{}, location string, path string, ancestors []string) (interface{}, error) {
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		return copyDocument(node), nil
+	}
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		childLocation := location + "/" + escapePointerToken(k)
+		var err error
+		if result[k], err = d.schema(v, childLocation, path+"/"+escapePointerToken(k), append(ancestors, childLocation)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// keptDefinitions returns the sorted names of the definitions the recursive references point into, that aren't in done
+func (d *dereferencer) keptDefinitions(definitions map[string]interface{}, done map[string]interface{}) []string {
+	var names []string
+	prefix := "/" + KEY_DEFINITIONS + "/"
+	for pointer := range d.kept {
+		if !strings.HasPrefix(pointer, prefix) {
+			continue
+		}
+		name := strings.SplitN(pointer[len(prefix):], "/", 2)[0]
+		name = strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+		if _, ok := done[name]; ok || !existsMapKey(definitions, name) {
+			continue
+		}
+		done[name] = nil
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// merge merges the allOf branches of the dereferenced schema at path, and of the schemas it contains.
+// An allOf a recursive reference points into is left as it is, as the reference would no longer resolve
+func (d *dereferencer) merge(node interface{}, path string) {
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for k, v := range m {
+		childPath := path + "/" + escapePointerToken(k)
+		switch k {
+		case KEY_DEFINITIONS, KEY_PROPERTIES, KEY_PATTERN_PROPERTIES, KEY_DEPENDENCIES:
+			if schemas, ok := v.(map[string]interface{}); ok {
+				for name, schema := range schemas {
+					d.merge(schema, childPath+"/"+escapePointerToken(name))
+				}
+			}
+		case KEY_ITEMS, KEY_ALL_OF, KEY_ANY_OF, KEY_ONE_OF:
+			if list, ok := v.([]interface{}); ok {
+				for i, item := range list {
+					d.merge(item, childPath+"/"+strconv.Itoa(i))
+				}
+				continue
+			}
+			d.merge(v, childPath)
+		case KEY_ADDITIONAL_ITEMS, KEY_ADDITIONAL_PROPERTIES, KEY_PROPERTY_NAMES, KEY_CONTAINS, KEY_NOT, KEY_IF, KEY_THEN, KEY_ELSE:
+			d.merge(v, childPath)
+		}
+	}
+
+	prefix := path + "/" + KEY_ALL_OF + "/"
+	for pointer := range d.kept {
+		if strings.HasPrefix(pointer, prefix) {
+			return
+		}
+	}
+	if indexes := mergeAllOf(m); indexes != nil {
+		d.moveRecursive(prefix, path, indexes)
+	}
+}
+
+// moveRecursive updates the pointers of the recursive references in the allOf branches at prefix,
+// after mergeAllOf moved them: a merged branch is now part of the schema at path
+func (d *dereferencer) moveRecursive(prefix string, path string, indexes []int) {
+	seen := make(map[RecursiveReference]bool)
+	recursive := d.recursive[:0]
+	for _, r := range d.recursive {
+		if strings.HasPrefix(r.Pointer, prefix) {
+			tokens := strings.SplitN(r.Pointer[len(prefix):], "/", 2)
+			rest := ""
+			if len(tokens) > 1 {
+				rest = "/" + tokens[1]
+			}
+			if i, err := strconv.Atoi(tokens[0]); err == nil && i < len(indexes) {
+				if indexes[i] < 0 {
+					r.Pointer = path + rest
+				} else {
+					r.Pointer = prefix + strconv.Itoa(indexes[i]) + rest
+				}
+			}
+		}
+		// a branch equal to the schema it is merged into had the same references
+		if !seen[r] {
+			seen[r] = true
+			recursive = append(recursive, r)
+		}
+	}
+	d.recursive = recursive
+}
+
+// mergeAllOf merges the branches of the allOf of schema into it, where that doesn't change what it validates.
+// It returns the new index of every branch, -1 for the merged ones, or nil if schema has no allOf
+func mergeAllOf(schema map[string]interface{}) []int {
+	branches, ok := schema[KEY_ALL_OF].([]interface{})
+	if !ok || existsMapKey(schema, KEY_REF) {
+		return nil
+	}
+	delete(schema, KEY_ALL_OF)
+
+	var kept []interface{}
+	indexes := make([]int, len(branches))
+	for i, branch := range branches {
+		indexes[i] = -1
+		if b, ok := branch.(bool); ok && b {
+			continue
+		}
+		b, ok := branch.(map[string]interface{})
+		if !ok || !mergeSchema(schema, b) {
+			indexes[i] = len(kept)
+			kept = append(kept, branch)
+		}
+	}
+	if len(kept) > 0 {
+		schema[KEY_ALL_OF] = kept
+	}
+	return indexes
+}
+
+// keywordGroups are keywords that depend on each other, so they can only be merged from a branch
+// if the schema has none of them
+var keywordGroups = [][]string{
+	{KEY_PROPERTIES, KEY_PATTERN_PROPERTIES, KEY_ADDITIONAL_PROPERTIES},
+	{KEY_ITEMS, KEY_ADDITIONAL_ITEMS},
+	{KEY_IF, KEY_THEN, KEY_ELSE},
+	{KEY_MINIMUM, KEY_MAXIMUM, KEY_EXCLUSIVE_MINIMUM, KEY_EXCLUSIVE_MAXIMUM},
+}
+
+// mergeSchema merges branch into schema, if the result validates the same documents as schema and branch together.
+// It returns false, leaving schema unchanged, if that is not the case
+func mergeSchema(schema map[string]interface{}, branch map[string]interface{}) bool {
+	if existsMapKey(branch, KEY_REF) || existsMapKey(branch, KEY_ID) || existsMapKey(branch, KEY_ID_NEW) {
+		return false
+	}
+
+	for _, group := range keywordGroups {
+		inSchema, inBranch := false, false
+		for _, k := range group {
+			inSchema = inSchema || existsMapKey(schema, k)
+			inBranch = inBranch || existsMapKey(branch, k)
+		}
+		if inSchema && inBranch && !mergeableGroup(group, schema, branch) {
+			return false
+		}
+	}
+
+	merged := make(map[string]interface{}, len(branch))
+	for k, v := range branch {
+		current, ok := schema[k]
+		if !ok || reflect.DeepEqual(current, v) {
+			merged[k] = v
+			continue
+		}
+		value, ok := mergeKeyword(k, current, v)
+		if !ok {
+			return false
+		}
+		merged[k] = value
+	}
+
+	for k, v := range merged {
+		schema[k] = v
+	}
+	return true
+}
+
+// mergeableGroup reports whether the keywords of a group can be merged one by one
+func mergeableGroup(group []string, schema map[string]interface{}, branch map[string]interface{}) bool {
+	switch group[0] {
+	case KEY_PROPERTIES:
+		// additionalProperties depends on the properties and patternProperties next to it
+		for _, k := range group {
+			if !reflect.DeepEqual(schema[k], branch[k]) && (k == KEY_ADDITIONAL_PROPERTIES || existsMapKey(schema, KEY_ADDITIONAL_PROPERTIES) || existsMapKey(branch, KEY_ADDITIONAL_PROPERTIES)) {
+				return false
+			}
+		}
+		return true
+	case KEY_MINIMUM:
+		// the bounds of draft 4 have boolean exclusive flags
+		for _, k := range []string{KEY_EXCLUSIVE_MINIMUM, KEY_EXCLUSIVE_MAXIMUM} {
+			if isKind(schema[k], reflect.Bool) || isKind(branch[k], reflect.Bool) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, k := range group {
+		if !reflect.DeepEqual(schema[k], branch[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeKeyword returns the value of keyword k that validates the same as both a and b
+func mergeKeyword(k string, a interface{}, b interface{}) (interface{}, bool) {
+	switch k {
+	case KEY_MINIMUM, KEY_EXCLUSIVE_MINIMUM, KEY_MIN_LENGTH, KEY_MIN_ITEMS, KEY_MIN_PROPERTIES:
+		return boundKeyword(a, b, true)
+	case KEY_MAXIMUM, KEY_EXCLUSIVE_MAXIMUM, KEY_MAX_LENGTH, KEY_MAX_ITEMS, KEY_MAX_PROPERTIES:
+		return boundKeyword(a, b, false)
+
+	case KEY_REQUIRED:
+		x, okA := a.([]interface{})
+		y, okB := b.([]interface{})
+		if !okA || !okB {
+			return nil, false
+		}
+		required := append([]interface{}{}, x...)
+		for _, name := range y {
+			if !containsValue(required, name) {
+				required = append(required, name)
+			}
+		}
+		return required, true
+
+	case KEY_TYPE:
+		return intersectTypes(a, b)
+
+	case KEY_UNIQUE_ITEMS:
+		x, okA := a.(bool)
+		y, okB := b.(bool)
+		return x || y, okA && okB
+
+	case KEY_PROPERTIES, KEY_PATTERN_PROPERTIES, KEY_DEPENDENCIES, KEY_DEFINITIONS:
+		// schemas of different names apply independently
+		x, okA := a.(map[string]interface{})
+		y, okB := b.(map[string]interface{})
+		if !okA || !okB {
+			return nil, false
+		}
+		m := make(map[string]interface{}, len(x)+len(y))
+		for name, v := range x {
+			m[name] = v
+		}
+		for name, v := range y {
+			if current, ok := m[name]; ok && !reflect.DeepEqual(current, v) {
+				return nil, false
+			}
+			m[name] = v
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+// boundKeyword returns the tighter of two numeric bounds, the larger one for lower bounds
+func boundKeyword(a interface{}, b interface{}, lower bool) (interface{}, bool) {
+	x, y := mustBeNumber(a), mustBeNumber(b)
+	if x == nil || y == nil {
+		return nil, false
+	}
+	if (x.Cmp(y) > 0) == lower {
+		return a, true
+	}
+	return b, true
+}
+
+// intersectTypes returns the types of both a and b, false if there are none
+func intersectTypes(a interface{}, b interface{}) (interface{}, bool) {
+	x, y := typeList(a), typeList(b)
+	if x == nil || y == nil {
+		return nil, false
+	}
+
+	var types []interface{}
+	add := func(t string) {
+		if !containsValue(types, t) {
+			types = append(types, t)
+		}
+	}
+	for _, s := range x {
+		for _, t := range y {
+			switch {
+			case s == t:
+				add(s)
+			case s == TYPE_NUMBER && t == TYPE_INTEGER, s == TYPE_INTEGER && t == TYPE_NUMBER:
+				add(TYPE_INTEGER)
+			}
+		}
+	}
+	switch len(types) {
+	case 0:
+		return nil, false
+	case 1:
+		return types[0], true
+	}
+	return types, true
+}
+
+// typeList returns the types of a type keyword
+func typeList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		types := make([]string, 0, len(v))
+		for _, t := range v {
+			s, ok := t.(string)
+			if !ok {
+				return nil
+			}
+			types = append(types, s)
+		}
+		return types
+	}
+	return nil
+}
+
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package gojsonschema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDereference(t *testing.T) {
+	base := writeSchemaFiles(t, map[string]string{
+		"root.json": `{
+			"definitions": {"unused": {"type": "null"}},
+			"type": "object",
+			"properties": {
+				"home": {"$ref": "address.json", "description": "ignored next to $ref"},
+				"tree": {"$ref": "tree.json"},
+				"parent": {"$ref": "#"}
+			}
+		}`,
+		"address.json": `{"type": "object", "properties": {"street": {"$ref": "types.json#/definitions/street"}}}`,
+		"types.json":   `{"definitions": {"street": {"type": "string", "minLength": 3}}}`,
+		"tree.json":    `{"type": "object", "properties": {"value": {"type": "integer"}, "children": {"type": "array", "items": {"$ref": "#"}}}}`,
+	})
+
+	root := NewReferenceLoader(base + "root.json")
+	dereferenced, recursive, err := Dereference(root, DereferenceOptions{})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	expected, err := decodeJSONUsingNumber(strings.NewReader(`{
+		"type": "object",
+		"properties": {
+			"home": {"type": "object", "properties": {"street": {"type": "string", "minLength": 3}}},
+			"tree": {"type": "object", "properties": {"value": {"type": "integer"}, "children": {"type": "array", "items": {"$ref": "#/definitions/tree"}}}},
+			"parent": {"$ref": "#"}
+		},
+		"definitions": {
+			"tree": {"type": "object", "properties": {"value": {"type": "integer"}, "children": {"type": "array", "items": {"$ref": "#/definitions/tree"}}}}
+		}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, dereferenced)
+	assert.ElementsMatch(t, []RecursiveReference{
+		{Pointer: "/properties/tree/properties/children/items", Ref: "#/definitions/tree"},
+		{Pointer: "/properties/parent", Ref: "#"},
+		{Pointer: "/definitions/tree/properties/children/items", Ref: "#/definitions/tree"},
+	}, recursive)
+
+	original, err := NewSchema(root)
+	assert.Nil(t, err)
+	inlined, err := NewSchema(NewGoLoader(dereferenced))
+	if !assert.Nil(t, err) {
+		return
+	}
+	documents := []string{
+		`{"home": {"street": "Main"}, "tree": {"value": 1, "children": [{"value": 2}]}, "parent": {"home": {}}}`,
+		`{"home": {"street": "M"}, "tree": {"children": [{"value": "2"}]}, "parent": {"parent": {"home": 1}}}`,
+	}
+	for _, d := range documents {
+		expected, err := original.Validate(NewStringLoader(d))
+		assert.Nil(t, err)
+		actual, err := inlined.Validate(NewStringLoader(d))
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, errorFields(expected), errorFields(actual), d)
+	}
+}
+
+func TestDereferenceMergeAllOf(t *testing.T) {
+	cases := []struct {
+		schema string
+		merged string
+	}{
+		{
+			`{"allOf": [
+				{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a"]},
+				{"properties": {"b": {"type": "integer"}}, "required": ["b", "a"], "minProperties": 1},
+				{"additionalProperties": false},
+				{"$ref": "#/definitions/size"}
+			], "definitions": {"size": {"minProperties": 2, "maxProperties": 5}}}`,
+			`{"type": "object", "properties": {"a": {"type": "string"}, "b": {"type": "integer"}}, "required": ["a", "b"],
+				"minProperties": 2, "maxProperties": 5, "allOf": [{"additionalProperties": false}]}`,
+		},
+		{
+			`{"type": ["string", "integer"], "allOf": [{"type": "number", "minimum": 1}, {"minimum": 0, "maximum": 10}, true]}`,
+			`{"type": "integer", "minimum": 1, "maximum": 10}`,
+		},
+		{
+			`{"properties": {"a": {"allOf": [{"maxLength": 3}, {"maxLength": 2}]}}, "allOf": [{"properties": {"a": {"minLength": 1}}}]}`,
+			`{"properties": {"a": {"maxLength": 2}}, "allOf": [{"properties": {"a": {"minLength": 1}}}]}`,
+		},
+		{
+			`{"maximum": 5, "exclusiveMaximum": true, "allOf": [{"maximum": 4}, {"type": "string"}, {"type": "integer"}, false]}`,
+			`{"maximum": 5, "exclusiveMaximum": true, "type": "string", "allOf": [{"maximum": 4}, {"type": "integer"}, false]}`,
+		},
+	}
+
+	for _, c := range cases {
+		expected, err := decodeJSONUsingNumber(strings.NewReader(c.merged))
+		assert.Nil(t, err)
+		merged, recursive, err := Dereference(NewStringLoader(c.schema), DereferenceOptions{MergeAllOf: true})
+		if assert.Nil(t, err, c.schema) {
+			assert.Equal(t, expected, merged, c.schema)
+			assert.Empty(t, recursive)
+		}
+	}
+}
+
+func TestDereferenceMergeAllOfRecursive(t *testing.T) {
+	cases := []struct {
+		schema    string
+		merged    string
+		recursive []RecursiveReference
+	}{
+		{
+			// the branch a recursive reference points to is kept
+			`{"allOf": [{"type": "object", "properties": {"child": {"$ref": "#/allOf/0"}}}]}`,
+			`{"allOf": [{"type": "object", "properties": {"child": {"$ref": "#/allOf/0"}}}]}`,
+			[]RecursiveReference{{Pointer: "/allOf/0/properties/child", Ref: "#/allOf/0"}},
+		},
+		{
+			// a merged branch moves its recursive references
+			`{"allOf": [{"type": "object"}, {"properties": {"child": {"$ref": "#"}}}]}`,
+			`{"type": "object", "properties": {"child": {"$ref": "#"}}}`,
+			[]RecursiveReference{{Pointer: "/properties/child", Ref: "#"}},
+		},
+		{
+			// as does a kept branch after a merged one
+			`{"items": {"type": "string"}, "allOf": [{"minItems": 1}, {"items": {"$ref": "#"}}]}`,
+			`{"items": {"type": "string"}, "minItems": 1, "allOf": [{"items": {"$ref": "#"}}]}`,
+			[]RecursiveReference{{Pointer: "/allOf/0/items", Ref: "#"}},
+		},
+	}
+
+	for _, c := range cases {
+		expected, err := decodeJSONUsingNumber(strings.NewReader(c.merged))
+		assert.Nil(t, err)
+		merged, recursive, err := Dereference(NewStringLoader(c.schema), DereferenceOptions{MergeAllOf: true})
+		if !assert.Nil(t, err, c.schema) {
+			continue
+		}
+		assert.Equal(t, expected, merged, c.schema)
+		assert.Equal(t, c.recursive, recursive, c.schema)
+
+		// the merged schema compiles, and its recursive references resolve
+		_, err = NewSchema(NewGoLoader(merged))
+		assert.Nil(t, err, c.schema)
+	}
+}
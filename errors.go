@@ -207,6 +207,12 @@ type (
 	ConditionElseError struct {
 		ResultErrorFields
 	}
+
+	// MaxDepthError is produced if the validation nests deeper than the maximum depth of the Schema
+	// ErrorDetails: max
+	MaxDepthError struct {
+		ResultErrorFields
+	}
 )
 
 // newError takes a ResultError type and sets the type, context, description, details, value, and field
@@ -310,6 +316,9 @@ func newError(err ResultError, context *JsonContext, value interface{}, locale l
 	case *ConditionElseError:
 		t = "condition_else"
 		d = locale.ConditionElse()
+	case *MaxDepthError:
+		t = "max_depth"
+		d = locale.MaxDepth()
 	}
 
 	err.SetType(t)
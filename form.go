@@ -0,0 +1,223 @@
+package gojsonschema
+
+import (
+	"context"
+	"mime/multipart"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateForm validates HTML form data or query parameters. The values are first arranged in a
+// document the way the schema describes it:
+//
+//	a=1&a=2     is {"a": ["1", "2"]} if a is an array, or a single string otherwise
+//	a[]=1       is {"a": ["1"]}
+//	a[b]=1      is {"a": {"b": "1"}}, as is a.b=1 unless the schema has a property named "a.b"
+//	a[0][b]=1   is {"a": [{"b": "1"}]} if a is an array, or {"a": {"0": {"b": "1"}}} otherwise
+//
+// The document is then converted and validated with ValidateCoerce, so numbers, booleans and the
+// like need no special handling. The converted document is returned along with the Result
+func (v *Schema) ValidateForm(values url.Values) (*Result, interface{}, error) {
+	return v.ValidateFormContext(context.Background(), values)
+}
+
+// ValidateFormContext is ValidateForm aborting as soon as ctx is done, see ValidateContext
+func (v *Schema) ValidateFormContext(ctx context.Context, values url.Values) (*Result, interface{}, error) {
+	return v.ValidateCoerceContext(ctx, NewGoLoader(v.formDocument(values)))
+}
+
+// ValidateMultipartForm validates a parsed multipart form like ValidateForm. An uploaded file is
+// given by its file name, so that a schema can require or restrict uploads
+func (v *Schema) ValidateMultipartForm(form *multipart.Form) (*Result, interface{}, error) {
+	values := url.Values{}
+	for key, list := range form.Value {
+		values[key] = append(values[key], list...)
+	}
+	for key, files := range form.File {
+		for _, file := range files {
+			values.Add(key, file.Filename)
+		}
+	}
+	return v.ValidateForm(values)
+}
+
+// formDocument arranges values in a document described by the root schema
+func (v *Schema) formDocument(values url.Values) map[string]interface{} {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	document := map[string]interface{}{}
+	for _, key := range keys {
+		insertFormValue(document, v.rootSchema, splitFormKey(key), values[key])
+	}
+	return document
+}
+
+// splitFormKey splits a[b][c] into a, b and c. Dots are left to insertFormValue
+func splitFormKey(key string) []string {
+	open := strings.IndexByte(key, '[')
+	if open <= 0 || !strings.HasSuffix(key, "]") {
+		return []string{key}
+	}
+
+	segments := []string{key[:open]}
+	rest := key[open:]
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			// Not brackets all the way, e.g. a[b]c], the key is a name of its own
+			return []string{key}
+		}
+		segments = append(segments, rest[1:end])
+		rest = rest[end+1:]
+	}
+	return segments
+}
+
+// insertFormValue sets the values of the key segments in object, described by s
+func insertFormValue(object map[string]interface{}, s *subSchema, segments []string, values []string) {
+	name, rest := segments[0], segments[1:]
+
+	// a.b is a nested property, unless the schema has a property of that name
+	if dot := strings.IndexByte(name, '.'); dot > 0 && dot < len(name)-1 && formProperty(s, name) == nil {
+		name, rest = name[:dot], append([]string{name[dot+1:]}, rest...)
+	}
+	property := formProperty(s, name)
+
+	switch {
+	case len(rest) == 0:
+		object[name] = formLeaf(property, values)
+
+	case rest[0] == "" && len(rest) == 1:
+		list, _ := object[name].([]interface{})
+		for _, value := range values {
+			list = append(list, value)
+		}
+		object[name] = list
+
+	case rest[0] != "" && isFormArray(property):
+		index, err := strconv.Atoi(rest[0])
+		if err != nil || index < 0 || index > formMaxIndex {
+			object[name] = insertFormObject(object[name], property, rest, values)
+			return
+		}
+		list, _ := object[name].([]interface{})
+		for len(list) <= index {
+			list = append(list, nil)
+		}
+		item := formItem(property, index)
+		if len(rest) == 1 {
+			list[index] = formLeaf(item, values)
+		} else {
+			list[index] = insertFormObject(list[index], item, rest[1:], values)
+		}
+		object[name] = list
+
+	default:
+		object[name] = insertFormObject(object[name], property, rest, values)
+	}
+}
+
+// formMaxIndex bounds array indexes in keys, a[1000000]=1 must not allocate a million items
+const formMaxIndex = 1000
+
+func insertFormObject(node interface{}, s *subSchema, segments []string, values []string) map[string]interface{} {
+	object, ok := node.(map[string]interface{})
+	if !ok {
+		object = map[string]interface{}{}
+	}
+	insertFormValue(object, s, segments, values)
+	return object
+}
+
+// formLeaf is the value of a key: a list if s is an array or the key is repeated, a string otherwise
+func formLeaf(s *subSchema, values []string) interface{} {
+	if len(values) == 1 && !isFormArray(s) {
+		return values[0]
+	}
+	list := make([]interface{}, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	return list
+}
+
+// formSchemas returns s and the schemas that apply along with it, through $ref and allOf
+func formSchemas(s *subSchema) []*subSchema {
+	var schemas []*subSchema
+	seen := map[*subSchema]bool{}
+	var collect func(s *subSchema)
+	collect = func(s *subSchema) {
+		if s == nil || seen[s] {
+			return
+		}
+		seen[s] = true
+		if s.refSchema != nil {
+			collect(s.refSchema)
+			return
+		}
+		schemas = append(schemas, s)
+		for _, a := range s.allOf {
+			collect(a)
+		}
+	}
+	collect(s)
+	return schemas
+}
+
+func isFormArray(s *subSchema) bool {
+	for _, schema := range formSchemas(s) {
+		if schema.types.Contains(TYPE_ARRAY) {
+			return true
+		}
+	}
+	return false
+}
+
+// formProperty returns the schema of the property name of an object described by s
+func formProperty(s *subSchema, name string) *subSchema {
+	schemas := formSchemas(s)
+	for _, schema := range schemas {
+		for _, p := range schema.propertiesChildren {
+			if p.property == name {
+				return p
+			}
+		}
+	}
+	for _, schema := range schemas {
+		for pattern, p := range schema.patternProperties {
+			if matches, _ := regexp.MatchString(pattern, name); matches {
+				return p
+			}
+		}
+	}
+	for _, schema := range schemas {
+		if ap, ok := schema.additionalProperties.(*subSchema); ok {
+			return ap
+		}
+	}
+	return nil
+}
+
+// formItem returns the schema of the item at index of an array described by s
+func formItem(s *subSchema, index int) *subSchema {
+	for _, schema := range formSchemas(s) {
+		switch {
+		case schema.itemsChildrenIsSingleSchema:
+			return schema.itemsChildren[0]
+		case index < len(schema.itemsChildren):
+			return schema.itemsChildren[index]
+		case len(schema.itemsChildren) > 0:
+			if ai, ok := schema.additionalItems.(*subSchema); ok {
+				return ai
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package gojsonschema
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitFormKey(t *testing.T) {
+	assert.Equal(t, []string{"a"}, splitFormKey("a"))
+	assert.Equal(t, []string{"a", "b", "c"}, splitFormKey("a[b][c]"))
+	assert.Equal(t, []string{"a", ""}, splitFormKey("a[]"))
+	assert.Equal(t, []string{"a.b", "0"}, splitFormKey("a.b[0]"))
+	assert.Equal(t, []string{"[a]"}, splitFormKey("[a]"))
+	assert.Equal(t, []string{"a[b]c]"}, splitFormKey("a[b]c]"))
+	assert.Equal(t, []string{"a[b"}, splitFormKey("a[b"))
+}
+
+func TestValidateForm(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"type" : "object",
+		"properties" : {
+			"page" : { "type" : "integer" },
+			"tags" : { "type" : "array", "items" : { "type" : "string" } },
+			"ids" : { "type" : "array", "items" : { "type" : "integer" } },
+			"filter" : { "$ref" : "#/definitions/filter" },
+			"rows" : {
+				"type" : "array",
+				"items" : { "type" : "object", "properties" : { "n" : { "type" : "number" } } }
+			},
+			"x.y" : { "type" : "boolean" }
+		},
+		"definitions" : {
+			"filter" : {
+				"type" : "object",
+				"properties" : {
+					"min" : { "type" : "number" },
+					"range" : { "properties" : { "to" : { "type" : "integer" } } }
+				}
+			}
+		}
+	}`))
+	assert.Nil(t, err)
+
+	values, err := url.ParseQuery("page=2&tags=a&ids[]=1&ids[]=2&filter[min]=1.5&filter.range.to=9&rows[0][n]=3&x.y=true&name=a&name=b")
+	assert.Nil(t, err)
+	result, document, err := schema.ValidateForm(values)
+	assert.Nil(t, err)
+	assert.True(t, result.Valid(), "%v", result.Errors())
+	assert.Equal(t, map[string]interface{}{
+		"page": json.Number("2"),
+		"tags": []interface{}{"a"},
+		"ids":  []interface{}{json.Number("1"), json.Number("2")},
+		"filter": map[string]interface{}{
+			"min":   json.Number("1.5"),
+			"range": map[string]interface{}{"to": json.Number("9")},
+		},
+		"rows": []interface{}{map[string]interface{}{"n": json.Number("3")}},
+		"x.y":  true,
+		"name": []interface{}{"a", "b"},
+	}, document)
+
+	// A repeated key of a single value is an error
+	result, _, err = schema.ValidateForm(url.Values{"page": {"1", "2"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"invalid_type (root).page"}, errorFields(result))
+
+	// Out of range indexes are object keys
+	result, document, err = schema.ValidateForm(url.Values{"rows[5000]": {"1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"rows": map[string]interface{}{"5000": "1"}}, document)
+	assert.Equal(t, []string{"invalid_type (root).rows"}, errorFields(result))
+}
+
+func TestValidateMultipartForm(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"required" : ["title", "upload"],
+		"properties" : {
+			"title" : { "type" : "string", "minLength" : 1 },
+			"upload" : { "type" : "string", "pattern" : "\\.png$" }
+		}
+	}`))
+	assert.Nil(t, err)
+
+	form := &multipart.Form{
+		Value: map[string][]string{"title": {"Logo"}},
+		File:  map[string][]*multipart.FileHeader{"upload": {{Filename: "logo.png"}}},
+	}
+	result, _, err := schema.ValidateMultipartForm(form)
+	assert.Nil(t, err)
+	assert.True(t, result.Valid(), "%v", result.Errors())
+
+	form.File = nil
+	result, _, err = schema.ValidateMultipartForm(form)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"required (root)"}, errorFields(result))
+}
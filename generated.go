@@ -0,0 +1,65 @@
+// Copyright 2015 xeipuuv ( https://github.com/xeipuuv )
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gojsonschema
+
+import "encoding/json"
+
+// The validators written by GenerateGo use package generated, which builds their errors and scores
+// their results with the functions below, the ones Validate uses. They are not meant for other uses
+
+// NewValidationError sets the type, context, value, details and description of err, as Validate does
+func NewValidationError(err ResultError, context *JsonContext, value interface{}, details ErrorDetails) {
+	newError(err, context, value, Locale, details)
+}
+
+// AddValidationError adds err, found at context for value, to the result and scores it, as Validate does
+func (v *Result) AddValidationError(err ResultError, context *JsonContext, value interface{}, details ErrorDetails) {
+	v.addInternalError(err, context, value, details)
+}
+
+// AppendError adds err, already filled by NewValidationError, to the result without scoring it
+func (v *Result) AppendError(err ResultError) {
+	v.errors = append(v.errors, err)
+}
+
+// Merge copies the errors and the score of other, the result of a subschema, into the result
+func (v *Result) Merge(other *Result) {
+	v.mergeErrors(other)
+}
+
+// Pass records a successful check in the result
+func (v *Result) Pass() {
+	v.incrementScore()
+}
+
+// Score returns how well the document matched the schema of the result, which picks the errors
+// of the closest branch of anyOf and oneOf
+func (v *Result) Score() int {
+	return v.score
+}
+
+// CanonicalJSON returns the JSON encoding of value used to compare values for const, enum and uniqueItems
+func CanonicalJSON(value interface{}) (string, error) {
+	s, err := marshalWithoutNumber(value)
+	if err != nil {
+		return "", err
+	}
+	return *s, nil
+}
+
+// IsJSONInteger reports whether number is an integer, 1.0 included
+func IsJSONInteger(number json.Number) bool {
+	return checkJSONInteger(number)
+}
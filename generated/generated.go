@@ -0,0 +1,125 @@
+// Copyright 2015 xeipuuv ( https://github.com/xeipuuv )
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package generated is the runtime support of the validators written by gojsonschema's Schema.GenerateGo.
+// It is only meant to be used by generated code, its API may change along with the generator
+package generated
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Validator holds the state of a single validation run, exactly like the interpreter does, so that
+// generated code builds the same errors and scores the results the same way
+type Validator struct {
+	ctx context.Context
+
+	// maxDepth limits depth, the number of nested subschemas. 0 means no limit
+	maxDepth   int
+	depth      int
+	depthError gojsonschema.ResultError
+
+	// interrupted is set when the run stopped early because ctx was done
+	interrupted bool
+}
+
+// Func is the signature of the function generated for every subschema
+type Func func(g *Validator, node interface{}, result *gojsonschema.Result, context *gojsonschema.JsonContext)
+
+// NewValidator starts a validation run aborting as soon as ctx is done.
+// maxDepth limits the number of nested subschemas, 0 means no limit
+func NewValidator(ctx context.Context, maxDepth int) *Validator {
+	return &Validator{ctx: ctx, maxDepth: maxDepth}
+}
+
+// Enter is called when a subschema starts validating node, it returns false when the subschema
+// must be skipped because the run was cancelled or is too deep.
+// Every successful Enter is followed by a Leave
+func (g *Validator) Enter(node interface{}, context *gojsonschema.JsonContext) bool {
+	if g.depthError != nil {
+		return false
+	}
+	select {
+	case <-g.ctx.Done():
+		g.interrupted = true
+		return false
+	default:
+	}
+
+	g.depth++
+	if g.maxDepth > 0 && g.depth > g.maxDepth {
+		g.depth--
+		g.depthError = new(gojsonschema.MaxDepthError)
+		gojsonschema.NewValidationError(g.depthError, context, node, gojsonschema.ErrorDetails{"max": g.maxDepth})
+		return false
+	}
+	return true
+}
+
+// Leave is called when a subschema is done validating
+func (g *Validator) Leave() {
+	g.depth--
+}
+
+// Done ends the run, the depth error may have been swallowed by anyOf or oneOf and it must always fail the validation.
+// It returns the error of ctx if the run was cancelled before it completed, nil otherwise
+func (g *Validator) Done(result *gojsonschema.Result) error {
+	if g.depthError != nil {
+		result.AppendError(g.depthError)
+	}
+	if g.interrupted {
+		return g.ctx.Err()
+	}
+	return nil
+}
+
+// AddError adds err, found at context for value, to result
+func (g *Validator) AddError(result *gojsonschema.Result, err gojsonschema.ResultError, context *gojsonschema.JsonContext, value interface{}, details gojsonschema.ErrorDetails) {
+	result.AddValidationError(err, context, value, details)
+}
+
+// Merge copies the errors and the score of other into result
+func (g *Validator) Merge(result *gojsonschema.Result, other *gojsonschema.Result) {
+	result.Merge(other)
+}
+
+// Pass records a successful check in result
+func (g *Validator) Pass(result *gojsonschema.Result) {
+	result.Pass()
+}
+
+// Closer reports whether result matches its schema better than best, which may be nil
+func (g *Validator) Closer(result *gojsonschema.Result, best *gojsonschema.Result) bool {
+	return best == nil || result.Score() > best.Score()
+}
+
+// Sub validates node against f in a new Result
+func (g *Validator) Sub(f Func, node interface{}, context *gojsonschema.JsonContext) *gojsonschema.Result {
+	result := &gojsonschema.Result{}
+	f(g, node, result, context)
+	return result
+}
+
+// Canonical returns the JSON encoding of value used to compare values for const, enum and uniqueItems
+func (g *Validator) Canonical(value interface{}) (string, error) {
+	return gojsonschema.CanonicalJSON(value)
+}
+
+// IsInteger reports whether number is an integer
+func (g *Validator) IsInteger(number json.Number) bool {
+	return gojsonschema.IsJSONInteger(number)
+}
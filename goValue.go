@@ -0,0 +1,196 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// goToDocument converts a Go value to the document json.Marshal followed by
+// decodeJSONUsingNumber would produce. Maps with string keys, slices, arrays, pointers and scalars
+// are walked with reflection instead of being written as JSON text and parsed again.
+// Values holding structs and the values encoding/json writes in a way of their own, e.g. with
+// a MarshalJSON method, are encoded by encoding/json, so its rules for struct fields apply as they are.
+//
+// The result is still a copy of value as maps, slices and scalars, which validation walks
+func goToDocument(value interface{}) (interface{}, error) {
+	return goValueToDocument(reflect.ValueOf(value), 0)
+}
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	numberType        = reflect.TypeOf(json.Number(""))
+)
+
+// goMaxDepth is the nesting past which the rest of a value is encoded by encoding/json,
+// which reports the cycles the walk would otherwise loop on
+const goMaxDepth = 1000
+
+func goValueToDocument(v reflect.Value, depth int) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if depth > goMaxDepth || encodesItself(v) || hasGoStruct(v.Type()) {
+		return marshalGoValue(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(v.Int(), 10)), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return json.Number(strconv.FormatUint(v.Uint(), 10)), nil
+
+	case reflect.Float32:
+		b, err := json.Marshal(float32(v.Float()))
+		return json.Number(b), err
+
+	case reflect.Float64:
+		b, err := json.Marshal(v.Float())
+		return json.Number(b), err
+
+	case reflect.String:
+		s := v.String()
+		if v.Type() == numberType {
+			if s == "" {
+				return json.Number("0"), nil
+			}
+			if !isValidNumber(s) {
+				return marshalGoValue(v)
+			}
+			return json.Number(s), nil
+		}
+		if !utf8.ValidString(s) {
+			// encoding/json replaces the invalid bytes
+			return marshalGoValue(v)
+		}
+		return s, nil
+
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return goValueToDocument(v.Elem(), depth+1)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if isByteSlice(v.Type()) {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+		fallthrough
+
+	case reflect.Array:
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			item, err := goValueToDocument(v.Index(i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = item
+		}
+		return list, nil
+
+	case reflect.Map:
+		if key := v.Type().Key(); key.Kind() != reflect.String || key.Implements(textMarshalerType) {
+			// integer and encoding.TextMarshaler keys are written by encoding/json
+			return marshalGoValue(v)
+		}
+		if v.IsNil() {
+			return nil, nil
+		}
+		object := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if !utf8.ValidString(key) {
+				return marshalGoValue(v)
+			}
+			value, err := goValueToDocument(iter.Value(), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = value
+		}
+		return object, nil
+	}
+
+	return nil, &json.UnsupportedTypeError{Type: v.Type()}
+}
+
+var goStructCache sync.Map // map[reflect.Type]bool
+
+// hasGoStruct reports whether values of type t may hold a struct without going through an interface.
+// Which fields encoding/json writes, and how, is left to it, and it is faster to marshal
+// a slice of structs at once than each of them
+func hasGoStruct(t reflect.Type) bool {
+	if has, ok := goStructCache.Load(t); ok {
+		return has.(bool)
+	}
+	has := hasGoStructVisited(t, make(map[reflect.Type]bool))
+	goStructCache.Store(t, has)
+	return has
+}
+
+func hasGoStructVisited(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasGoStructVisited(t.Elem(), visited)
+	case reflect.Map:
+		return hasGoStructVisited(t.Key(), visited) || hasGoStructVisited(t.Elem(), visited)
+	}
+	return false
+}
+
+// encodesItself reports whether encoding/json encodes v with its MarshalJSON or MarshalText method
+func encodesItself(v reflect.Value) bool {
+	t := v.Type()
+	if t.Kind() != reflect.Ptr && v.CanAddr() {
+		t = reflect.PointerTo(t)
+	}
+	return t.Implements(marshalerType) || t.Implements(textMarshalerType)
+}
+
+// marshalGoValue encodes v with encoding/json and decodes the JSON text
+func marshalGoValue(v reflect.Value) (interface{}, error) {
+	value := v.Interface()
+	if v.Kind() != reflect.Ptr && v.CanAddr() {
+		// the pointer methods are called on addressable values
+		value = v.Addr().Interface()
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONUsingNumber(bytes.NewReader(b))
+}
+
+func isValidNumber(s string) bool {
+	var n json.Number
+	return json.Unmarshal([]byte(s), &n) == nil && string(n) == s
+}
+
+func isByteSlice(t reflect.Type) bool {
+	if t.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	p := reflect.PointerTo(t.Elem())
+	return !p.Implements(marshalerType) && !p.Implements(textMarshalerType)
+}
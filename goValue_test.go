@@ -0,0 +1,227 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type goTestEmbedded struct {
+	Shared   string
+	Embedded int `json:"embedded,omitempty"`
+}
+
+type goTestOther struct {
+	Shared string
+}
+
+type goTestMarshaler struct {
+	value int
+}
+
+func (m goTestMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"marshaled" : ` + string(rune('0'+m.value)) + `}`), nil
+}
+
+type goTestPointerMarshaler struct{}
+
+func (m *goTestPointerMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`"pointer"`), nil
+}
+
+type goTestText string
+
+func (t goTestText) MarshalText() ([]byte, error) {
+	return []byte("text:" + string(t)), nil
+}
+
+type goTestZero struct {
+	set bool
+}
+
+func (z goTestZero) IsZero() bool {
+	return !z.set
+}
+
+type goTestStruct struct {
+	goTestEmbedded
+	*goTestOther
+	unexported int
+
+	Name     string             `json:"name"`
+	Skipped  string             `json:"-"`
+	Dash     string             `json:"-,"`
+	Empty    string             `json:",omitempty"`
+	Zero     goTestZero         `json:"zero,omitzero"`
+	ZeroSet  goTestZero         `json:"zeroSet,omitzero"`
+	Quoted   int64              `json:"quoted,string"`
+	QuotedS  string             `json:"quotedS,string"`
+	Float    float64            `json:"float"`
+	Small    float32            `json:"small"`
+	Huge     float64            `json:"huge"`
+	NegZero  float64            `json:"negZero,omitempty"`
+	Bytes    []byte             `json:"bytes"`
+	NilSlice []int              `json:"nilSlice"`
+	Array    [2]bool            `json:"array"`
+	Map      map[int]string     `json:"map"`
+	TextMap  map[goTestText]int `json:"textMap"`
+	Any      interface{}        `json:"any"`
+	Ptr      *int               `json:"ptr"`
+	Nil      *int               `json:"nil,omitempty"`
+	Marshal  goTestMarshaler    `json:"marshal"`
+	PtrMarsh goTestPointerMarshaler
+	Text     goTestText      `json:"text"`
+	Time     time.Time       `json:"time"`
+	IP       net.IP          `json:"ip"`
+	Number   json.Number     `json:"number"`
+	Raw      json.RawMessage `json:"raw"`
+	Invalid  string          `json:"invalid"`
+	HTML     string          `json:"<html>"`
+}
+
+func roundTrip(value interface{}) (interface{}, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONUsingNumber(bytes.NewReader(b))
+}
+
+func TestGoToDocument(t *testing.T) {
+	seven := 7
+	value := goTestStruct{
+		goTestEmbedded: goTestEmbedded{Shared: "hidden", Embedded: 3},
+		goTestOther:    &goTestOther{Shared: "hidden too"},
+		Name:           "name",
+		Skipped:        "skipped",
+		Dash:           "dash",
+		ZeroSet:        goTestZero{set: true},
+		Quoted:         -42,
+		QuotedS:        "a<b",
+		Float:          1.5,
+		Small:          0.1,
+		Huge:           1e21,
+		NegZero:        math.Copysign(0, -1),
+		Bytes:          []byte("bytes"),
+		Array:          [2]bool{true, false},
+		Map:            map[int]string{1: "one", -2: "two"},
+		TextMap:        map[goTestText]int{"key": 1},
+		Any:            []interface{}{1, "a", nil, map[string]interface{}{"b": 2.5}},
+		Ptr:            &seven,
+		Marshal:        goTestMarshaler{value: 5},
+		Text:           "value",
+		Time:           time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC),
+		IP:             net.ParseIP("127.0.0.1"),
+		Number:         "1e400",
+		Raw:            json.RawMessage(`[1, {"x" : true}]`),
+		Invalid:        "a\xffb",
+	}
+
+	for _, v := range []interface{}{
+		value,
+		&value,
+		[]goTestStruct{value, {}},
+		map[string]interface{}{"nested": &value, "list": []uint8{1, 2}},
+		nil,
+		"string",
+		3.25,
+		float32(1e-7),
+		uint64(math.MaxUint64),
+		[]interface{}{json.Number("")},
+	} {
+		expected, err := roundTrip(v)
+		assert.Nil(t, err)
+		document, err := goToDocument(v)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, document)
+	}
+
+	for _, v := range []interface{}{
+		math.Inf(1),
+		map[bool]int{true: 1},
+		make(chan int),
+		json.Number("abc"),
+	} {
+		_, expected := roundTrip(v)
+		_, err := goToDocument(v)
+		assert.NotNil(t, expected)
+		assert.NotNil(t, err, "%#v", v)
+	}
+
+	type cycle struct {
+		Next *cycle
+	}
+	c := &cycle{}
+	c.Next = c
+	_, err := goToDocument(c)
+	var unsupported *json.UnsupportedValueError
+	assert.True(t, errors.As(err, &unsupported))
+}
+
+func TestGoLoaderValidation(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"properties" : {
+			"name" : { "minLength" : 5 },
+			"quoted" : { "type" : "string" },
+			"float" : { "multipleOf" : 1 },
+			"embedded" : { "maximum" : 2 }
+		},
+		"required" : ["zero"]
+	}`))
+	assert.Nil(t, err)
+
+	value := goTestStruct{Name: "name", Float: 1.5, goTestEmbedded: goTestEmbedded{Embedded: 3}}
+	expected, err := roundTrip(value)
+	assert.Nil(t, err)
+
+	result, err := schema.Validate(NewGoLoader(value))
+	assert.Nil(t, err)
+	expectedResult, err := schema.Validate(NewRawLoader(expected))
+	assert.Nil(t, err)
+
+	assert.False(t, result.Valid())
+	assert.Equal(t, errorStrings(expectedResult), errorStrings(result))
+	assert.Len(t, result.Errors(), 4)
+}
+
+// BenchmarkGoToDocument compares goToDocument with the json.Marshal round-trip it replaces.
+// Maps and slices are walked without JSON text, structs are marshalled either way
+func BenchmarkGoToDocument(b *testing.B) {
+	items := make([]interface{}, 1000)
+	structs := make([]goTestEmbedded, 1000)
+	for i := range items {
+		items[i] = map[string]interface{}{"id": i, "name": "item", "tags": []string{"a", "b"}, "ratio": 0.5}
+		structs[i] = goTestEmbedded{Shared: "item", Embedded: i}
+	}
+
+	for _, c := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"maps", items},
+		{"structs", structs},
+	} {
+		b.Run(c.name+"/reflect", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := goToDocument(c.value); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(c.name+"/round-trip", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := roundTrip(c.value); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
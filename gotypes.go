@@ -0,0 +1,528 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/xeipuuv/gojsonpointer"
+	"github.com/xeipuuv/gojsonreference"
+)
+
+// GoTypesOptions configures Schema.GenerateGoTypes
+type GoTypesOptions struct {
+	// Package is the name of the package of the generated file, "main" by default
+	Package string
+
+	// Name is the name of the type of the root schema. By default it comes from the title of the schema, or is "Root"
+	Name string
+}
+
+// GenerateGoTypes writes Go type declarations mirroring the schema.
+// Objects with properties become structs with json tags, and subschemas that are referenced with $ref
+// or listed in definitions become named types. Subschemas are resolved by the schema pool, so a $ref
+// to the same subschema, from this document or another one, always gives the same type
+func (d *Schema) GenerateGoTypes(options GoTypesOptions) ([]byte, error) {
+	if options.Package == "" {
+		options.Package = "main"
+	}
+	if !token.IsIdentifier(options.Package) || (options.Name != "" && !token.IsIdentifier(options.Name)) {
+		return nil, fmt.Errorf("invalid package or type name %q %q", options.Package, options.Name)
+	}
+
+	definitions, err := d.definitions()
+	if err != nil {
+		return nil, err
+	}
+
+	gen := &goTypesGenerator{
+		names:     make(map[*subSchema]string),
+		refs:      make(map[string]string),
+		used:      make(map[string]bool),
+		declaring: make(map[string]bool),
+	}
+
+	d.referencePool.lock.Lock()
+	root := d.rootSchema
+	name := options.Name
+	if name == "" {
+		name = "Root"
+		if root.title != nil {
+			name = exportedGoName(*root.title)
+		}
+	}
+	// The root and its definitions are referenced with the $id of the root when it has one
+	base := &d.documentReference
+	if root.id != nil {
+		base = root.id
+	}
+	gen.declare(root, name, refKey(base), refKey(&d.documentReference))
+	for _, key := range sortedDefinitions(definitions) {
+		keys := []string{refKey(definitions[key].ref)}
+		if ref, err := base.Inherits(*definitions[key].ref); err == nil {
+			keys = append(keys, refKey(ref))
+		}
+		gen.declare(definitions[key], exportedGoName(key), keys...)
+	}
+	d.referencePool.lock.Unlock()
+
+	var source bytes.Buffer
+	fmt.Fprintf(&source, "// Code generated by gojsonschema. DO NOT EDIT.\n\npackage %s\n", options.Package)
+	if gen.usesTime {
+		source.WriteString("\nimport \"time\"\n")
+	}
+	source.Write(gen.decls.Bytes())
+
+	return format.Source(source.Bytes())
+}
+
+// definitions compiles the definitions of the root document, which are not part of the
+// compiled schema unless they are referenced
+func (d *Schema) definitions() (map[string]*subSchema, error) {
+	spd, err := d.pool.GetDocument(d.documentReference, d.loaderFactory)
+	if err != nil {
+		return nil, err
+	}
+	document, ok := spd.Document.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	definitions, _ := document[KEY_DEFINITIONS].(map[string]interface{})
+
+	compiled := make(map[string]*subSchema)
+	for key := range definitions {
+		pointer := "/" + KEY_DEFINITIONS + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+		sub, err := d.SubSchema(pointer)
+		if err != nil {
+			return nil, err
+		}
+		compiled[key] = sub.rootSchema
+	}
+	return compiled, nil
+}
+
+func sortedDefinitions(m map[string]*subSchema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// goTypesGenerator declares a named type for every subSchema that needs one
+type goTypesGenerator struct {
+	names     map[*subSchema]string
+	refs      map[string]string
+	used      map[string]bool
+	declaring map[string]bool
+	usesTime  bool
+	decls     bytes.Buffer
+}
+
+// uniqueName returns name, or name followed by a number if it is already used by a type or a constant
+func (gen *goTypesGenerator) uniqueName(name string) string {
+	unique := name
+	for i := 2; gen.used[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	gen.used[unique] = true
+	return unique
+}
+
+// declare declares the named type of s if it does not exist yet, and returns its name.
+// keys are the canonical references of s, if any: the same reference may be compiled more than once,
+// for instance "#" is compiled again when it is referenced
+func (gen *goTypesGenerator) declare(s *subSchema, name string, keys ...string) string {
+	s = resolveRef(s)
+	if existing, ok := gen.names[s]; ok {
+		return existing
+	}
+	for _, key := range keys {
+		if existing, ok := gen.refs[key]; ok && key != "" {
+			gen.names[s] = existing
+			return existing
+		}
+	}
+	name = gen.uniqueName(goTypeName(name))
+	gen.names[s] = name
+	for _, key := range keys {
+		if key != "" {
+			gen.refs[key] = name
+		}
+	}
+	gen.declaring[name] = true
+	defer delete(gen.declaring, name)
+
+	// The declaration is written in its own buffer, as declaring its fields may declare other types first
+	var decl bytes.Buffer
+	writeGoDoc(&decl, "", s)
+
+	if gen.isStruct(s) {
+		fmt.Fprintf(&decl, "type %s struct {\n", name)
+		gen.fields(&decl, s, name)
+		decl.WriteString("}\n")
+	} else if values := stringEnum(s); values != nil {
+		fmt.Fprintf(&decl, "type %s string\n\nconst (\n", name)
+		for _, value := range values {
+			// The constants share the package scope with the types
+			constant := name + exportedGoName(value)
+			if value == "" || constant == name {
+				constant = name + "Empty"
+			}
+			constant = gen.uniqueName(constant)
+			fmt.Fprintf(&decl, "%s %s = %q\n", constant, name, value)
+		}
+		decl.WriteString(")\n")
+	} else {
+		underlying := gen.underlyingGoType(s, name+"Value")
+		if underlying == "interface{}" {
+			fmt.Fprintf(&decl, "type %s = interface{}\n", name)
+		} else {
+			fmt.Fprintf(&decl, "type %s %s\n", name, underlying)
+		}
+	}
+
+	gen.decls.WriteString("\n")
+	gen.decls.Write(decl.Bytes())
+	return name
+}
+
+// refKey returns the canonical form of ref, the same subschema may be referenced as "x.json" and "x.json#"
+func refKey(ref *gojsonreference.JsonReference) string {
+	if ref == nil {
+		return ""
+	}
+	return strings.TrimSuffix(ref.String(), "#")
+}
+
+func resolveRef(s *subSchema) *subSchema {
+	for s.refSchema != nil && s.pass == nil {
+		s = s.refSchema
+	}
+	return s
+}
+
+// writeGoDoc writes the title and the description of s as a comment
+func writeGoDoc(w *bytes.Buffer, indent string, s *subSchema) {
+	var paragraphs []string
+	if s.title != nil && strings.TrimSpace(*s.title) != "" {
+		paragraphs = append(paragraphs, strings.TrimSpace(*s.title))
+	}
+	if s.description != nil && strings.TrimSpace(*s.description) != "" {
+		paragraphs = append(paragraphs, strings.TrimSpace(*s.description))
+	}
+	for i, p := range paragraphs {
+		if i > 0 {
+			fmt.Fprintf(w, "%s//\n", indent)
+		}
+		for _, line := range strings.Split(p, "\n") {
+			fmt.Fprintf(w, "%s// %s\n", indent, strings.TrimRight(line, " \t\r"))
+		}
+	}
+}
+
+// nonNullTypes returns the types of s other than null, and whether null is allowed
+func nonNullTypes(s *subSchema) ([]string, bool) {
+	var types []string
+	nullable := false
+	for _, t := range s.types.types {
+		if t == TYPE_NULL {
+			nullable = true
+		} else {
+			types = append(types, t)
+		}
+	}
+	return types, nullable
+}
+
+// isStruct reports whether s is an object described by its properties
+func (gen *goTypesGenerator) isStruct(s *subSchema) bool {
+	types, _ := nonNullTypes(s)
+	if len(types) > 1 || (len(types) == 1 && types[0] != TYPE_OBJECT) {
+		return false
+	}
+	if len(s.propertiesChildren) > 0 {
+		return true
+	}
+	for _, a := range s.allOf {
+		if gen.isStruct(resolveRef(a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringEnum returns the values of the enum of s if they are all strings
+func stringEnum(s *subSchema) []string {
+	if len(s.enum) == 0 {
+		return nil
+	}
+	types, _ := nonNullTypes(s)
+	if len(types) > 1 || (len(types) == 1 && types[0] != TYPE_STRING) {
+		return nil
+	}
+	values := make([]string, 0, len(s.enum))
+	for _, e := range s.enum {
+		if !strings.HasPrefix(e, `"`) {
+			return nil
+		}
+		var value string
+		if err := json.Unmarshal([]byte(e), &value); err != nil {
+			return nil
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// fields writes the fields of the struct declared for s
+func (gen *goTypesGenerator) fields(w *bytes.Buffer, s *subSchema, structName string) {
+	// allOf branches that are structs are embedded, encoding/json promotes their fields
+	for _, a := range s.allOf {
+		if target := resolveRef(a); gen.isStruct(target) {
+			hint, key := structName+"Part", ""
+			if a.refSchema != nil {
+				// Every subSchema inherits the ref of its document, it only identifies $ref targets
+				hint, key = refGoName(target), refKey(target.ref)
+			}
+			fmt.Fprintf(w, "%s\n", gen.declare(target, hint, key))
+		}
+	}
+
+	required := make(map[string]bool)
+	for _, r := range s.required {
+		required[r] = true
+	}
+
+	properties := sortedProperties(s)
+	fieldNames := make(map[string]bool)
+	for _, p := range properties {
+		fieldName := exportedGoName(p.property)
+		if fieldName == "" || !unicode.IsLetter([]rune(fieldName)[0]) {
+			fieldName = "Field" + fieldName
+		}
+		unique := fieldName
+		for i := 2; fieldNames[unique]; i++ {
+			unique = fmt.Sprintf("%s%d", fieldName, i)
+		}
+		fieldNames[unique] = true
+
+		fieldType := gen.goType(p, structName+fieldName)
+		tag := p.property
+		if !required[p.property] {
+			tag += ",omitempty"
+			fieldType = optionalGoType(fieldType)
+		} else if gen.declaring[fieldType] {
+			// A struct can only contain itself through a pointer
+			fieldType = "*" + fieldType
+		}
+
+		writeGoDoc(w, "", p)
+		fmt.Fprintf(w, "%s %s `json:%q`\n", unique, fieldType, tag)
+	}
+}
+
+// optionalGoType is the type of a field that may be missing, nil stands for a missing value
+func optionalGoType(t string) string {
+	if t == "interface{}" || strings.HasPrefix(t, "*") || strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[") {
+		return t
+	}
+	return "*" + t
+}
+
+// goType returns the Go type of s, declaring the named types it needs. hint is the name of
+// the type declared for s if it needs one and is not referenced
+func (gen *goTypesGenerator) goType(s *subSchema, hint string) string {
+	if s.pass != nil {
+		return "interface{}"
+	}
+	if s.refSchema != nil {
+		target := resolveRef(s)
+		return gen.declare(target, refGoName(target), refKey(target.ref))
+	}
+	if name, ok := gen.names[s]; ok {
+		return name
+	}
+	if gen.needsName(s) {
+		t := gen.declare(s, hint)
+		if _, nullable := nonNullTypes(s); nullable {
+			return optionalGoType(t)
+		}
+		return t
+	}
+	return gen.underlyingGoType(s, hint)
+}
+
+// underlyingGoType returns the Go type of s without giving it a name
+func (gen *goTypesGenerator) underlyingGoType(s *subSchema, hint string) string {
+	types, nullable := nonNullTypes(s)
+
+	if len(types) == 0 {
+		if t, ok := gen.choiceGoType(s, hint); ok {
+			return t
+		}
+	}
+
+	var t string
+	switch {
+	case len(types) == 0:
+		t = "interface{}"
+		if s.additionalProperties != nil || s.patternProperties != nil || s.minProperties != nil || s.maxProperties != nil || len(s.required) > 0 {
+			t = gen.objectGoType(s, hint)
+		} else if s.itemsChildren != nil {
+			t = gen.arrayGoType(s, hint)
+		}
+	case len(types) == 2 && (types[0] == TYPE_INTEGER && types[1] == TYPE_NUMBER || types[0] == TYPE_NUMBER && types[1] == TYPE_INTEGER):
+		t = "float64"
+	case len(types) > 1:
+		t = "interface{}"
+	default:
+		switch types[0] {
+		case TYPE_OBJECT:
+			t = gen.objectGoType(s, hint)
+		case TYPE_ARRAY:
+			t = gen.arrayGoType(s, hint)
+		case TYPE_STRING:
+			t = "string"
+			if s.format == "date-time" {
+				gen.usesTime = true
+				t = "time.Time"
+			}
+		case TYPE_INTEGER:
+			t = "int64"
+		case TYPE_NUMBER:
+			t = "float64"
+		case TYPE_BOOLEAN:
+			t = "bool"
+		}
+	}
+
+	if nullable {
+		return optionalGoType(t)
+	}
+	return t
+}
+
+// needsName reports whether s must be a named type, structs and enums of strings
+func (gen *goTypesGenerator) needsName(s *subSchema) bool {
+	return gen.isStruct(s) || stringEnum(s) != nil
+}
+
+// choiceGoType maps oneOf and anyOf: a choice between a schema and null is a pointer,
+// a choice between schemas of the same Go type is that type, anything else is interface{}
+func (gen *goTypesGenerator) choiceGoType(s *subSchema, hint string) (string, bool) {
+	choices := s.oneOf
+	if len(choices) == 0 {
+		choices = s.anyOf
+	}
+	if len(choices) == 0 {
+		return "", false
+	}
+
+	nullable := false
+	var choiceTypes []string
+	for i, c := range choices {
+		target := resolveRef(c)
+		if types, null := nonNullTypes(target); null && len(types) == 0 && target.pass == nil {
+			nullable = true
+			continue
+		}
+		choiceHint := hint
+		if i > 0 {
+			choiceHint = fmt.Sprintf("%s%d", hint, i+1)
+		}
+		choiceTypes = append(choiceTypes, gen.goType(c, choiceHint))
+	}
+
+	t := "interface{}"
+	if len(choiceTypes) > 0 {
+		t = choiceTypes[0]
+		for _, other := range choiceTypes[1:] {
+			if other != t {
+				t = "interface{}"
+			}
+		}
+	}
+	if nullable {
+		t = optionalGoType(t)
+	}
+	return t, true
+}
+
+func (gen *goTypesGenerator) objectGoType(s *subSchema, hint string) string {
+	if ap, ok := s.additionalProperties.(*subSchema); ok {
+		return "map[string]" + gen.goType(ap, hint+"Value")
+	}
+	return "map[string]interface{}"
+}
+
+func (gen *goTypesGenerator) arrayGoType(s *subSchema, hint string) string {
+	if s.itemsChildrenIsSingleSchema {
+		return "[]" + gen.goType(s.itemsChildren[0], hint+"Item")
+	}
+	return "[]interface{}"
+}
+
+// refGoName names the type of a referenced subschema after the last token of its JSON Pointer,
+// or after the file name for the root of another document
+func refGoName(s *subSchema) string {
+	if s.ref != nil {
+		if s.ref.HasFragmentOnly || s.ref.GetUrl().Fragment != "" {
+			if pointer, err := gojsonpointer.NewJsonPointer(s.ref.GetUrl().Fragment); err == nil {
+				tokens := strings.Split(pointer.String(), "/")
+				if last := tokens[len(tokens)-1]; last != "" {
+					return exportedGoName(strings.NewReplacer("~1", "/", "~0", "~").Replace(last))
+				}
+			}
+		}
+		base := path.Base(s.ref.GetUrl().Path)
+		if name := exportedGoName(strings.TrimSuffix(base, path.Ext(base))); name != "" {
+			return name
+		}
+	}
+	return "Schema"
+}
+
+// goTypeName makes name, as returned by exportedGoName, a valid type name.
+// Names from titles or definition keys such as "2nd person" or "1x" start with a digit, others like "/" are empty
+func goTypeName(name string) string {
+	if name == "" {
+		return "Schema"
+	}
+	if !unicode.IsLetter([]rune(name)[0]) {
+		return "Schema" + name
+	}
+	return name
+}
+
+// goInitialisms are written in upper case in exported names, as golint expects
+var goInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// exportedGoName turns s, e.g. "shipping_address" or "content-type", into an exported Go name
+func exportedGoName(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var name strings.Builder
+	for _, word := range words {
+		if upper := strings.ToUpper(word); goInitialisms[upper] {
+			name.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		name.WriteString(string(runes))
+	}
+	return name.String()
+}
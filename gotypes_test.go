@@ -0,0 +1,176 @@
+package gojsonschema
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateGoTypes(t *testing.T) {
+	sl := NewSchemaLoader()
+	err := sl.AddSchemas(NewStringLoader(`{
+		"$id" : "http://example.com/address.json",
+		"title" : "Address",
+		"description" : "A postal address",
+		"type" : "object",
+		"properties" : {
+			"street" : { "type" : "string" },
+			"zip" : { "type" : "string" }
+		},
+		"required" : ["street"]
+	}`))
+	assert.Nil(t, err)
+
+	schema, err := sl.Compile(NewStringLoader(`{
+		"$id" : "http://example.com/person.json",
+		"title" : "person",
+		"type" : "object",
+		"properties" : {
+			"id" : { "type" : "integer" },
+			"name" : { "type" : "string", "description" : "Full name" },
+			"born" : { "type" : "string", "format" : "date-time" },
+			"home" : { "$ref" : "address.json" },
+			"work" : { "$ref" : "http://example.com/address.json#" },
+			"color" : { "enum" : ["red", "dark-blue"] },
+			"nickname" : { "type" : ["string", "null"] },
+			"parent" : { "$ref" : "#" },
+			"pets" : { "type" : "array", "items" : { "type" : "object", "properties" : { "kind" : { "type" : "string" } } } },
+			"scores" : { "type" : "object", "additionalProperties" : { "type" : "number" } },
+			"contact" : { "oneOf" : [{ "$ref" : "#/definitions/email" }, { "type" : "null" }] },
+			"any" : { "oneOf" : [{ "type" : "string" }, { "type" : "integer" }] },
+			"office" : { "allOf" : [{ "$ref" : "address.json" }, { "properties" : { "floor" : { "type" : "integer" } } }] }
+		},
+		"required" : ["id", "name"],
+		"definitions" : {
+			"email" : { "type" : "string", "format" : "email" },
+			"unused" : { "type" : "object", "properties" : { "x" : { "type" : "boolean" } } }
+		}
+	}`))
+	assert.Nil(t, err)
+
+	source, err := schema.GenerateGoTypes(GoTypesOptions{Package: "people"})
+	assert.Nil(t, err)
+	generated := string(source)
+
+	assert.Contains(t, generated, "package people")
+	assert.Contains(t, generated, `import "time"`)
+	assert.Contains(t, generated, "// Address\n//\n// A postal address\ntype Address struct {")
+	assert.Contains(t, generated, "type Person struct {")
+	assert.Regexp(t, "ID +int64 +`json:\"id\"`", generated)
+	assert.Regexp(t, "// Full name\n\tName +string +`json:\"name\"`", generated)
+	assert.Regexp(t, "Born +\\*time.Time +`json:\"born,omitempty\"`", generated)
+	assert.Regexp(t, "Nickname +\\*string", generated)
+	assert.Regexp(t, "Parent +\\*Person ", generated)
+	assert.Regexp(t, "Pets +\\[\\]PersonPetsItem ", generated)
+	assert.Regexp(t, "Scores +map\\[string\\]float64 ", generated)
+	assert.Regexp(t, "Contact +\\*Email ", generated)
+	assert.Regexp(t, "Any +interface\\{\\} ", generated)
+	assert.Contains(t, generated, "type PersonColor string")
+	assert.Regexp(t, "PersonColorDarkBlue +PersonColor = \"dark-blue\"", generated)
+	assert.Contains(t, generated, "type Email string")
+	assert.Contains(t, generated, "type Unused struct {")
+	assert.Contains(t, generated, "type PersonOffice struct {\n\tAddress\n\tPersonOfficePart\n}")
+
+	// Both references to the other document share a single type
+	assert.Regexp(t, "Home +\\*Address ", generated)
+	assert.Regexp(t, "Work +\\*Address ", generated)
+	assert.NotContains(t, generated, "Address2")
+	assert.NotContains(t, generated, "Person2")
+
+	again, err := schema.GenerateGoTypes(GoTypesOptions{Package: "people"})
+	assert.Nil(t, err)
+	assert.Equal(t, generated, string(again))
+}
+
+func TestExportedGoName(t *testing.T) {
+	assert.Equal(t, "ShippingAddress", exportedGoName("shipping_address"))
+	assert.Equal(t, "ContentType", exportedGoName("content-type"))
+	assert.Equal(t, "UserID", exportedGoName("user_id"))
+	assert.Equal(t, "", exportedGoName("$$"))
+}
+
+// Titles and definition keys that don't make a valid name on their own still give valid Go
+func TestGenerateGoTypesNames(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"title" : "2nd person",
+		"type" : "object",
+		"properties" : {
+			"a" : { "$ref" : "#/definitions/1x" },
+			"b" : { "$ref" : "#/definitions/~1" },
+			"c" : { "$ref" : "#/definitions/$$" }
+		},
+		"definitions" : {
+			"1x" : { "type" : "object", "properties" : { "y" : { "enum" : ["1", "2"] } } },
+			"/" : { "type" : "string" },
+			"$$" : { "type" : "integer" }
+		}
+	}`))
+	assert.Nil(t, err)
+
+	source, err := schema.GenerateGoTypes(GoTypesOptions{})
+	if !assert.Nil(t, err) {
+		return
+	}
+	generated := string(source)
+	assert.Contains(t, generated, "type Schema2ndPerson struct {")
+	assert.Contains(t, generated, "type Schema1x struct {")
+	assert.Contains(t, generated, "type Schema1xY string")
+	assert.Regexp(t, "Schema1xY1 +Schema1xY = \"1\"", generated)
+	assert.Regexp(t, "A +\\*Schema1x ", generated)
+	assert.Regexp(t, "B +\\*Schema ", generated)
+	assert.Regexp(t, "C +\\*Schema2 ", generated)
+}
+
+// typeCheckGoSource reports the errors of the generated source as the Go compiler would
+func typeCheckGoSource(source []byte) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "generated.go", source, 0)
+	if err != nil {
+		return err
+	}
+	config := types.Config{Importer: importer.Default()}
+	_, err = config.Check("generated", fset, []*ast.File{file}, nil)
+	return err
+}
+
+// Enum constants and types are declared in the same scope, whichever is declared first
+func TestGenerateGoTypesConstantNames(t *testing.T) {
+	for document, expected := range map[string][]string{
+		`{
+			"title" : "Color",
+			"enum" : ["red", "blue"],
+			"definitions" : {
+				"ColorRed" : { "type" : "object", "properties" : { "a" : { "type" : "string" } } }
+			}
+		}`: {"ColorRed +Color = \"red\"", "type ColorRed2 struct {"},
+		`{
+			"type" : "object",
+			"properties" : {
+				"a" : { "$ref" : "#/definitions/ColorRed" },
+				"b" : { "$ref" : "#/definitions/Color" }
+			},
+			"definitions" : {
+				"ColorRed" : { "type" : "object", "properties" : { "a" : { "type" : "string" } } },
+				"Color" : { "enum" : ["red", "blue"] }
+			}
+		}`: {"type ColorRed struct {", "ColorRed2 +Color = \"red\""},
+	} {
+		schema, err := NewSchema(NewStringLoader(document))
+		assert.Nil(t, err)
+
+		source, err := schema.GenerateGoTypes(GoTypesOptions{})
+		if !assert.Nil(t, err) {
+			continue
+		}
+		generated := string(source)
+		assert.Nil(t, typeCheckGoSource(source), generated)
+		for _, e := range expected {
+			assert.Regexp(t, e, generated)
+		}
+	}
+}
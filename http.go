@@ -0,0 +1,208 @@
+package gojsonschema
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// HTTPJSONLoaderFactory is a JSON loader factory for references loaded with a configurable HTTP client.
+// Set it as the LoaderFactory of a SchemaLoader to use it for every remote $ref:
+//
+//	sl := gojsonschema.NewSchemaLoader()
+//	sl.LoaderFactory = &gojsonschema.HTTPJSONLoaderFactory{
+//		Client: &http.Client{Timeout: 10 * time.Second},
+//		Decorate: func(req *http.Request) error {
+//			req.Header.Set("Authorization", "Bearer "+token)
+//			return nil
+//		},
+//		Retry:            gojsonschema.HTTPRetry{MaxRetries: 3, Backoff: 100 * time.Millisecond},
+//		MaxResponseBytes: 1 << 20,
+//	}
+type HTTPJSONLoaderFactory struct {
+	// FileSystem opens the file references, the local OS file system if nil
+	FileSystem http.FileSystem
+	// Client sends the requests, http.DefaultClient if nil
+	Client *http.Client
+	// Decorate is called with every request before it is sent, e.g. to add headers.
+	// An error cancels loading the reference
+	Decorate func(req *http.Request) error
+	// Retry is the policy of retrying failed requests
+	Retry HTTPRetry
+	// MaxResponseBytes is the maximum size of a response body, 0 for no limit.
+	// Exceeding it makes loading the reference fail with a *ResponseTooLargeError
+	MaxResponseBytes int64
+}
+
+// HTTPRetry is the policy of retrying requests that fail with a network error that may be temporary,
+// a timeout or a refused, reset or truncated connection, or with a status that may be temporary:
+// 408, 429, 500, 502, 503 and 504. Refused redirects, TLS errors and invalid addresses are not retried
+type HTTPRetry struct {
+	// MaxRetries is the number of times a failed request is retried, 0 disables retries
+	MaxRetries int
+	// Backoff is the delay before the first retry, doubled for every next one
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries, 0 for no cap
+	MaxBackoff time.Duration
+}
+
+// New creates a new JSON loader for the given source
+func (f *HTTPJSONLoaderFactory) New(source string) JSONLoader {
+	fs := f.FileSystem
+	if fs == nil {
+		fs = osFS
+	}
+	return &jsonReferenceLoader{
+		fs:     fs,
+		source: source,
+		http:   f,
+	}
+}
+
+// ResponseTooLargeError is returned when the response to a remote reference exceeds MaxResponseBytes
+type ResponseTooLargeError struct {
+	// URL is the address of the reference
+	URL string
+	// Max is the configured MaxResponseBytes
+	Max int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return formatErrorDescription(
+		Locale.HttpResponseTooLarge(),
+		ErrorDetails{"url": e.URL, "max": e.Max},
+	)
+}
+
+// get sends a GET request for address, retrying it as configured, and following only redirects
+// permitted by policy if it is not nil. The status of the returned response is not checked
+func (f *HTTPJSONLoaderFactory) get(ctx context.Context, address string, policy *ReferencePolicy) (*http.Response, error) {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if policy != nil {
+		client = policy.checkRedirects(client)
+	}
+	// A redirect that is refused would be refused again, so it is never retried
+	redirectRefused := false
+	unchecked := client
+	checked := *client
+	checked.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		err := checkRedirect(unchecked, req, via)
+		redirectRefused = err != nil
+		return err
+	}
+	client = &checked
+
+	backoff := f.Retry.Backoff
+	for retry := 0; ; retry++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+		if err != nil {
+			return nil, err
+		}
+		if f.Decorate != nil {
+			if err := f.Decorate(req); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := client.Do(req)
+		if retry >= f.Retry.MaxRetries || redirectRefused || !retryable(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			// drain the body so the connection can be reused
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		}
+
+		if f.Retry.MaxBackoff > 0 && backoff > f.Retry.MaxBackoff {
+			backoff = f.Retry.MaxBackoff
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// checkRedirect applies the redirect policy of client, or the default one of http.Client
+func checkRedirect(client *http.Client, req *http.Request, via []*http.Request) error {
+	if client.CheckRedirect != nil {
+		return client.CheckRedirect(req, via)
+	}
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// retryable reports whether a request that returned resp and err may succeed when it is sent again.
+// Of the errors, only timeouts, refused or reset connections and truncated responses are retried
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		var policyErr *ReferencePolicyError
+		if errors.As(err, &policyErr) {
+			return false
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true
+		}
+		return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// limitResponse fails reading the body of resp past MaxResponseBytes
+func (f *HTTPJSONLoaderFactory) limitResponse(address string, resp *http.Response) (io.ReadCloser, error) {
+	if f.MaxResponseBytes <= 0 {
+		return resp.Body, nil
+	}
+	tooLarge := &ResponseTooLargeError{URL: address, Max: f.MaxResponseBytes}
+	if resp.ContentLength > f.MaxResponseBytes {
+		resp.Body.Close()
+		return nil, tooLarge
+	}
+	return &responseReader{ReadCloser: resp.Body, remaining: f.MaxResponseBytes, err: tooLarge}, nil
+}
+
+// responseReader fails with err as soon as more than remaining bytes are read
+type responseReader struct {
+	io.ReadCloser
+	remaining int64
+	err       error
+}
+
+func (r *responseReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		var probe [1]byte
+		n, err := r.ReadCloser.Read(probe[:])
+		if n > 0 {
+			return 0, r.err
+		}
+		return 0, err
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,210 @@
+package gojsonschema
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func compileWithFactory(factory JSONLoaderFactory, schema string) (*Schema, error) {
+	sl := NewSchemaLoader()
+	sl.LoaderFactory = factory
+	return sl.Compile(NewStringLoader(schema))
+}
+
+func TestHTTPLoaderFactoryClient(t *testing.T) {
+	var requests []string
+	factory := &HTTPJSONLoaderFactory{
+		Client: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.URL.String())
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/yaml"}},
+				Body:       ioutil.NopCloser(strings.NewReader("type: integer\n")),
+				Request:    req,
+			}, nil
+		})},
+	}
+
+	schema, err := compileWithFactory(factory, `{"properties": {"a": {"$ref": "http://registry.invalid/a.json#"}}}`)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"http://registry.invalid/a.json"}, requests)
+
+	result, err := schema.Validate(NewStringLoader(`{"a": "1"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"invalid_type (root).a"}, errorFields(result))
+
+	// the factory of the root loader is used for its references
+	requests = nil
+	_, err = NewSchema(factory.New("http://registry.invalid/root.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"http://registry.invalid/root.json"}, requests)
+}
+
+func TestHTTPLoaderFactoryDecorate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"type": "string"}`))
+	}))
+	defer ts.Close()
+
+	schema := `{"$ref": "` + ts.URL + `/string.json"}`
+	_, err := compileWithFactory(&HTTPJSONLoaderFactory{}, schema)
+	assert.EqualError(t, err, "Could not read schema from HTTP, response status is 401 Unauthorized")
+
+	_, err = compileWithFactory(&HTTPJSONLoaderFactory{
+		Decorate: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer secret")
+			return nil
+		},
+	}, schema)
+	assert.Nil(t, err)
+
+	errDenied := errors.New("denied")
+	_, err = compileWithFactory(&HTTPJSONLoaderFactory{
+		Decorate: func(req *http.Request) error {
+			return errDenied
+		},
+	}, schema)
+	assert.Equal(t, errDenied, err)
+}
+
+func TestHTTPLoaderFactoryRetry(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`{"type": "string"}`))
+		}
+	}))
+	defer ts.Close()
+
+	schema := `{"$ref": "` + ts.URL + `/string.json"}`
+	_, err := compileWithFactory(&HTTPJSONLoaderFactory{
+		Retry: HTTPRetry{MaxRetries: 1, Backoff: time.Millisecond},
+	}, schema)
+	assert.EqualError(t, err, "Could not read schema from HTTP, response status is 429 Too Many Requests")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	atomic.StoreInt32(&requests, 0)
+	_, err = compileWithFactory(&HTTPJSONLoaderFactory{
+		Retry: HTTPRetry{MaxRetries: 5, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond},
+	}, schema)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// other statuses are not retried
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	_, err = compileWithFactory(&HTTPJSONLoaderFactory{
+		Retry: HTTPRetry{MaxRetries: 5},
+	}, `{"$ref": "`+notFound.URL+`/string.json"}`)
+	assert.EqualError(t, err, "Could not read schema from HTTP, response status is 404 Not Found")
+}
+
+// A refused redirect is refused again, so the request is not sent again
+func TestHTTPLoaderFactoryRetryRedirect(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/public/escape.json" {
+			atomic.AddInt32(&requests, 1)
+			http.Redirect(w, r, "/private/string.json", http.StatusFound)
+			return
+		}
+		w.Write([]byte(`{"type": "string"}`))
+	}))
+	defer ts.Close()
+
+	sl := NewSchemaLoader()
+	sl.LoaderFactory = &HTTPJSONLoaderFactory{
+		Decorate: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer token")
+			return nil
+		},
+		Retry: HTTPRetry{MaxRetries: 3, Backoff: time.Millisecond},
+	}
+	sl.Policy = &ReferencePolicy{Deny: []ReferenceRule{{Path: "/private/"}}}
+	_, err := sl.Compile(NewStringLoader(`{"$ref": "` + ts.URL + `/public/escape.json"}`))
+	var policyErr *ReferencePolicyError
+	assert.True(t, errors.As(err, &policyErr), "%v", err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	atomic.StoreInt32(&requests, 0)
+	errRedirect := errors.New("no redirects")
+	_, err = compileWithFactory(&HTTPJSONLoaderFactory{
+		Client: &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return errRedirect
+		}},
+		Retry: HTTPRetry{MaxRetries: 3, Backoff: time.Millisecond},
+	}, `{"$ref": "`+ts.URL+`/public/escape.json"}`)
+	assert.True(t, errors.Is(err, errRedirect), "%v", err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+// Connections that are refused may be accepted later, and are retried
+func TestHTTPLoaderFactoryRetryRefused(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	address := ts.URL
+	ts.Close()
+
+	var attempts int32
+	_, err := compileWithFactory(&HTTPJSONLoaderFactory{
+		Decorate: func(req *http.Request) error {
+			atomic.AddInt32(&attempts, 1)
+			return nil
+		},
+		Retry: HTTPRetry{MaxRetries: 2, Backoff: time.Millisecond},
+	}, `{"$ref": "`+address+`/string.json"}`)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestHTTPLoaderFactoryMaxResponseBytes(t *testing.T) {
+	large := `{"type": "string", "description": "` + strings.Repeat("x", 1000) + `"}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked.json" {
+			// flushing sends the response without a Content-Length
+			w.Write([]byte(large[:10]))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(large[10:]))
+			return
+		}
+		w.Write([]byte(large))
+	}))
+	defer ts.Close()
+
+	for _, path := range []string{"/large.json", "/chunked.json"} {
+		_, err := compileWithFactory(&HTTPJSONLoaderFactory{MaxResponseBytes: 100}, `{"$ref": "`+ts.URL+path+`"}`)
+		var tooLarge *ResponseTooLargeError
+		if assert.True(t, errors.As(err, &tooLarge), "%s: %v", path, err) {
+			assert.Equal(t, ts.URL+path, tooLarge.URL)
+			assert.Equal(t, int64(100), tooLarge.Max)
+			assert.Equal(t, "Could not read schema from HTTP, the response of "+ts.URL+path+" exceeds 100 bytes", err.Error())
+		}
+	}
+
+	_, err := compileWithFactory(&HTTPJSONLoaderFactory{MaxResponseBytes: int64(len(large))}, `{"$ref": "`+ts.URL+`/large.json"}`)
+	assert.Nil(t, err)
+}
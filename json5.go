@@ -0,0 +1,532 @@
+package gojsonschema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/xeipuuv/gojsonreference"
+)
+
+// JSON5 loader
+
+type json5StringLoader struct {
+	source string
+}
+
+func (l *json5StringLoader) JsonSource() interface{} {
+	return l.source
+}
+
+func (l *json5StringLoader) JsonReference() (gojsonreference.JsonReference, error) {
+	return gojsonreference.NewJsonReference("#")
+}
+
+func (l *json5StringLoader) LoaderFactory() JSONLoaderFactory {
+	return &DefaultJSONLoaderFactory{}
+}
+
+// NewJSON5Loader creates a new JSONLoader, taking a JSON5 string as source. This includes JSONC,
+// JSON with comments and trailing commas. Numbers keep their text like with json.Number,
+// so a document validates exactly like the same document in strict JSON.
+// Infinity and NaN have no JSON equivalent and are an error.
+// Referenced files and URLs ending in .json5 or .jsonc are decoded as JSON5 as well
+func NewJSON5Loader(source string) JSONLoader {
+	return &json5StringLoader{source: source}
+}
+
+func (l *json5StringLoader) LoadJSON() (interface{}, error) {
+	return decodeJSON5(strings.NewReader(l.source))
+}
+
+func (l *json5StringLoader) openJSON(ctx context.Context) (io.ReadCloser, error) {
+	return &documentReader{ReadCloser: ioutil.NopCloser(strings.NewReader(l.source)), decode: decodeJSON5}, nil
+}
+
+// isJSON5Path reports whether the file name or URL path name has a JSON5 or JSONC extension
+func isJSON5Path(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json5", ".jsonc":
+		return true
+	}
+	return false
+}
+
+// JSON5Error is returned for JSON5 text that can't be decoded, or that has no JSON equivalent
+type JSON5Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *JSON5Error) Error() string {
+	return fmt.Sprintf("json5: line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// json5MaxDepth bounds the nesting of arrays and objects, like encoding/json does
+const json5MaxDepth = 10000
+
+// decodeJSON5 decodes JSON5 like decodeJSONUsingNumber decodes JSON
+func decodeJSON5(r io.Reader) (interface{}, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &json5Parser{src: string(b), line: 1}
+	if !utf8.ValidString(p.src) {
+		return nil, p.errorf("invalid UTF-8")
+	}
+	if err := p.skipBlank(); err != nil {
+		return nil, err
+	}
+	if p.eof() {
+		return nil, p.errorf("no value")
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.skipBlank(); err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected %s after the value", p.describe())
+	}
+	return value, nil
+}
+
+type json5Parser struct {
+	src       string
+	pos       int
+	line      int
+	lineStart int
+	depth     int
+}
+
+func (p *json5Parser) errorf(format string, args ...interface{}) error {
+	return &JSON5Error{Line: p.line, Column: p.pos - p.lineStart + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *json5Parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *json5Parser) peek() rune {
+	if p.eof() {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	return r
+}
+
+// describe names the character at the current position for errors
+func (p *json5Parser) describe() string {
+	if p.eof() {
+		return "end of input"
+	}
+	return strconv.QuoteRune(p.peek())
+}
+
+func isJSON5LineTerminator(r rune) bool {
+	return r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029'
+}
+
+// newline skips the line terminator at the current position
+func (p *json5Parser) newline() {
+	if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos++
+	}
+	_, size := utf8.DecodeRuneInString(p.src[p.pos:])
+	p.pos += size
+	p.line++
+	p.lineStart = p.pos
+}
+
+// skipBlank skips white space and comments
+func (p *json5Parser) skipBlank() error {
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		switch {
+		case isJSON5LineTerminator(r):
+			p.newline()
+		case isJSWhitespace(r) || unicode.Is(unicode.Zs, r):
+			p.pos += size
+		case strings.HasPrefix(p.src[p.pos:], "//"):
+			for !p.eof() && !isJSON5LineTerminator(p.peek()) {
+				_, size := utf8.DecodeRuneInString(p.src[p.pos:])
+				p.pos += size
+			}
+		case strings.HasPrefix(p.src[p.pos:], "/*"):
+			start := p.line
+			p.pos += 2
+			for !strings.HasPrefix(p.src[p.pos:], "*/") {
+				if p.eof() {
+					return p.errorf("unterminated comment starting on line %d", start)
+				}
+				if isJSON5LineTerminator(p.peek()) {
+					p.newline()
+				} else {
+					_, size := utf8.DecodeRuneInString(p.src[p.pos:])
+					p.pos += size
+				}
+			}
+			p.pos += 2
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (p *json5Parser) parseValue() (interface{}, error) {
+	switch r := p.peek(); {
+	case r == '{':
+		return p.parseObject()
+	case r == '[':
+		return p.parseArray()
+	case r == '"' || r == '\'':
+		return p.parseString()
+	case r == '-' || r == '+' || r == '.' || r >= '0' && r <= '9':
+		return p.parseNumber()
+	case isJSON5IdentifierStart(r) || r == '\\':
+		start := p.pos
+		word, err := p.parseIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		switch word {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		case "Infinity", "NaN":
+			p.pos = start
+			return nil, p.errorf("%s has no JSON equivalent", word)
+		}
+		p.pos = start
+		return nil, p.errorf("unexpected identifier %q, strings must be quoted", word)
+	}
+	return nil, p.errorf("unexpected %s", p.describe())
+}
+
+// enter counts an array or object being decoded
+func (p *json5Parser) enter() error {
+	p.depth++
+	if p.depth > json5MaxDepth {
+		return p.errorf("exceeded max depth of %d", json5MaxDepth)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *json5Parser) parseObject() (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	object := map[string]interface{}{}
+	for {
+		if err := p.skipBlank(); err != nil {
+			return nil, err
+		}
+		if p.peek() == '}' {
+			p.pos++
+			return object, nil
+		}
+
+		var key string
+		var err error
+		switch r := p.peek(); {
+		case r == '"' || r == '\'':
+			key, err = p.parseString()
+		case isJSON5IdentifierStart(r) || r == '\\':
+			key, err = p.parseIdentifier()
+		default:
+			return nil, p.errorf("expected a property name, found %s", p.describe())
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.skipBlank(); err != nil {
+			return nil, err
+		}
+		if p.peek() != ':' {
+			return nil, p.errorf("expected ':' after property name %q, found %s", key, p.describe())
+		}
+		p.pos++
+		if err := p.skipBlank(); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		// like encoding/json, the last of duplicate keys wins
+		object[key] = value
+
+		if err := p.skipBlank(); err != nil {
+			return nil, err
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("expected ',' or '}' after an object member, found %s", p.describe())
+		}
+	}
+}
+
+func (p *json5Parser) parseArray() (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	array := []interface{}{}
+	for {
+		if err := p.skipBlank(); err != nil {
+			return nil, err
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return array, nil
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+
+		if err := p.skipBlank(); err != nil {
+			return nil, err
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']' after an array element, found %s", p.describe())
+		}
+	}
+}
+
+func isJSON5IdentifierStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r)
+}
+
+func isJSON5IdentifierPart(r rune) bool {
+	return isJSON5IdentifierStart(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc) || r == '\u200c' || r == '\u200d'
+}
+
+// parseIdentifier parses an ECMAScript identifier name, which may have \u escapes
+func (p *json5Parser) parseIdentifier() (string, error) {
+	var b strings.Builder
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if r == '\\' {
+			if !strings.HasPrefix(p.src[p.pos:], "\\u") {
+				return "", p.errorf("invalid escape in an identifier")
+			}
+			var ok bool
+			if r, ok = p.hexRune(p.pos+2, 4); !ok {
+				return "", p.errorf("invalid \\u escape")
+			}
+			size = 6
+		}
+		if b.Len() == 0 && !isJSON5IdentifierStart(r) || b.Len() > 0 && !isJSON5IdentifierPart(r) {
+			if size == 6 {
+				return "", p.errorf("%U can't be used in an identifier", r)
+			}
+			break
+		}
+		b.WriteRune(r)
+		p.pos += size
+	}
+	return b.String(), nil
+}
+
+// hexRune decodes the digits hexadecimal digits at offset
+func (p *json5Parser) hexRune(offset int, digits int) (rune, bool) {
+	if offset+digits > len(p.src) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(p.src[offset:offset+digits], 16, 32)
+	return rune(n), err == nil
+}
+
+// json5Escapes are the single character escapes of strings, other characters escape to themselves
+var json5Escapes = map[rune]string{'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v"}
+
+func (p *json5Parser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		switch {
+		case r == quote:
+			p.pos++
+			return b.String(), nil
+		case r == '\n' || r == '\r':
+			return "", p.errorf("line breaks in strings must be escaped")
+		case r == '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteString(p.src[p.pos : p.pos+size])
+			p.pos += size
+		}
+	}
+}
+
+func (p *json5Parser) parseEscape(b *strings.Builder) error {
+	p.pos++ // '\'
+	if p.eof() {
+		return p.errorf("unterminated string")
+	}
+	e, size := utf8.DecodeRuneInString(p.src[p.pos:])
+
+	switch {
+	case isJSON5LineTerminator(e):
+		// a line continuation
+		p.newline()
+		return nil
+	case json5Escapes[e] != "":
+		b.WriteString(json5Escapes[e])
+	case e == '0':
+		if next := p.src[p.pos+1:]; next != "" && next[0] >= '0' && next[0] <= '9' {
+			return p.errorf("octal escapes are not allowed")
+		}
+		b.WriteByte(0)
+	case e >= '1' && e <= '9':
+		return p.errorf("octal escapes are not allowed")
+	case e == 'x':
+		r, ok := p.hexRune(p.pos+1, 2)
+		if !ok {
+			return p.errorf("invalid \\x escape")
+		}
+		b.WriteRune(r)
+		size = 3
+	case e == 'u':
+		r, ok := p.hexRune(p.pos+1, 4)
+		if !ok {
+			return p.errorf("invalid \\u escape")
+		}
+		size = 5
+		if utf16.IsSurrogate(r) {
+			// a pair of escapes, or U+FFFD like encoding/json for a lone surrogate
+			if low, ok := p.hexRune(p.pos+7, 4); ok && strings.HasPrefix(p.src[p.pos+5:], "\\u") {
+				if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+					r, size = pair, 11
+				}
+			}
+			if size == 5 {
+				r = utf8.RuneError
+			}
+		}
+		b.WriteRune(r)
+	default:
+		b.WriteRune(e)
+	}
+	p.pos += size
+	return nil
+}
+
+// parseNumber parses a decimal or hexadecimal number, written as a JSON number.
+// The text of numbers that are valid JSON already is kept
+func (p *json5Parser) parseNumber() (interface{}, error) {
+	start := p.pos
+	sign := ""
+	if r := p.peek(); r == '-' || r == '+' {
+		if r == '-' {
+			sign = "-"
+		}
+		p.pos++
+	}
+
+	if r := p.peek(); r == 'I' || r == 'N' {
+		word, err := p.parseIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		text := p.src[start:p.pos]
+		p.pos = start
+		if word == "Infinity" || word == "NaN" {
+			return nil, p.errorf("%s has no JSON equivalent", text)
+		}
+		return nil, p.errorf("invalid number")
+	}
+
+	if strings.HasPrefix(p.src[p.pos:], "0x") || strings.HasPrefix(p.src[p.pos:], "0X") {
+		p.pos += 2
+		digits := p.pos
+		for p.isDigit(16) {
+			p.pos++
+		}
+		n, ok := new(big.Int).SetString(p.src[digits:p.pos], 16)
+		if !ok {
+			return nil, p.errorf("invalid hexadecimal number")
+		}
+		return json.Number(sign + n.String()), nil
+	}
+
+	digits := 0
+	if p.peek() == '0' && p.pos+1 < len(p.src) && p.src[p.pos+1] >= '0' && p.src[p.pos+1] <= '9' {
+		return nil, p.errorf("numbers can't have leading zeros")
+	}
+	for p.isDigit(10) {
+		p.pos++
+		digits++
+	}
+	if p.peek() == '.' {
+		p.pos++
+		for p.isDigit(10) {
+			p.pos++
+			digits++
+		}
+	}
+	if digits == 0 {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	if r := p.peek(); r == 'e' || r == 'E' {
+		p.pos++
+		if r := p.peek(); r == '-' || r == '+' {
+			p.pos++
+		}
+		if !p.isDigit(10) {
+			return nil, p.errorf("invalid number exponent")
+		}
+		for p.isDigit(10) {
+			p.pos++
+		}
+	}
+	return json.Number(jsonFloatText(p.src[start:p.pos])), nil
+}
+
+func (p *json5Parser) isDigit(base int) bool {
+	r := p.peek()
+	return r >= '0' && r <= '9' || base == 16 && (r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F')
+}
@@ -0,0 +1,175 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeJSON5(t *testing.T) {
+	cases := []struct {
+		json5 string
+		json  string
+	}{
+		// JSONC
+		{"// settings\n{\n  \"a\": 1, /* inline */ \"b\": [1, 2,],\n}\n", `{"a" : 1, "b" : [1, 2]}`},
+		{"/* a\n multi-line\n comment */ [] // end", `[]`},
+
+		// JSON5
+		{"{unquoted: 1, $dollar_1: 2, \u00fcnicode: 3, a\u200db: 4}", "{\"unquoted\" : 1, \"$dollar_1\" : 2, \"\u00fcnicode\" : 3, \"a\u200db\" : 4}"},
+		{`['single "quoted"', 'it\'s']`, `["single \"quoted\"", "it's"]`},
+		{"'line \\\ncontinued'", `"line continued"`},
+		{`"\x41\u00e9\ud83d\ude00\v\0\q"`, `"A\u00e9\ud83d\ude00\u000b\u0000q"`},
+		{`"\ud83d"`, `"\ufffd"`},
+		{`[0x1F, -0XfF, +1, .5, 5., -.5e2, 1.50, -0, 1E+2]`, `[31, -255, 1, 0.5, 5, -0.5e2, 1.50, -0, 1E+2]`},
+		{`{a: 1, a: 2}`, `{"a" : 2}`},
+		{"\u00a0\ufeff\u2028 null \t", `null`},
+	}
+
+	for _, c := range cases {
+		expected, err := decodeJSONUsingNumber(strings.NewReader(c.json))
+		if !assert.Nil(t, err, c.json) {
+			continue
+		}
+		actual, err := decodeJSON5(strings.NewReader(c.json5))
+		if assert.Nil(t, err, "%q", c.json5) {
+			assert.Equal(t, expected, actual, "%q", c.json5)
+		}
+	}
+}
+
+func TestDecodeJSON5Errors(t *testing.T) {
+	cases := []struct {
+		json5   string
+		message string
+	}{
+		{``, "line 1, column 1: no value"},
+		{`{a: Infinity}`, "line 1, column 5: Infinity has no JSON equivalent"},
+		{`-Infinity`, "-Infinity has no JSON equivalent"},
+		{`[NaN]`, "NaN has no JSON equivalent"},
+		{`{a: yes}`, `unexpected identifier "yes", strings must be quoted`},
+		{"{\n  a: 1\n  b: 2\n}", "line 3, column 3: expected ',' or '}' after an object member"},
+		{`[1,,2]`, "unexpected ','"},
+		{`{,}`, "expected a property name"},
+		{`{a 1}`, `expected ':' after property name "a"`},
+		{`/* open`, "unterminated comment starting on line 1"},
+		{`'open`, "unterminated string"},
+		{"'a\nb'", "line breaks in strings must be escaped"},
+		{`"\1"`, "octal escapes are not allowed"},
+		{`012`, "numbers can't have leading zeros"},
+		{`1e`, "invalid number exponent"},
+		{`0x`, "invalid hexadecimal number"},
+		{`.`, "invalid number"},
+		{`1 2`, "unexpected '2' after the value"},
+		{strings.Repeat("[", 10001), "exceeded max depth"},
+	}
+
+	for _, c := range cases {
+		_, err := decodeJSON5(strings.NewReader(c.json5))
+		var json5Err *JSON5Error
+		if assert.True(t, errors.As(err, &json5Err), "%q: expected a JSON5Error, got %v", c.json5, err) {
+			assert.Contains(t, json5Err.Error(), c.message, "%q", c.json5)
+		}
+	}
+}
+
+// Strict JSON decodes to the same document as with decodeJSONUsingNumber
+func TestDecodeJSON5StrictJSON(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*", "*.json"))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, files)
+
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		expected, err := decodeJSONUsingNumber(bytes.NewReader(b))
+		assert.Nil(t, err, file)
+		actual, err := decodeJSON5(bytes.NewReader(b))
+		if assert.Nil(t, err, file) {
+			assert.Equal(t, expected, actual, file)
+		}
+	}
+}
+
+// FuzzJSON5 checks that decodeJSON5 either fails with a JSON5Error or returns a JSON document,
+// and that strict JSON decodes to the same document as with decodeJSONUsingNumber
+func FuzzJSON5(f *testing.F) {
+	for _, seed := range []string{
+		"// settings\n{\n  \"a\": 1, /* inline */ \"b\": [1, 2,],\n}\n",
+		"{unquoted: 1, $dollar_1: 2, \u00fcnicode: 3}",
+		`['single "quoted"', 'it\'s', "\x41\u00e9\ud83d\ude00\v\0"]`,
+		"'line \\\ncontinued'",
+		`[0x1F, -0XfF, +1, .5, 5., -.5e2, 1.50, -0, 1E+2, Infinity, NaN]`,
+		`{"a" : [1, 2.5e3, -0, "x\"y", true, null], "b" : {}}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, source []byte) {
+		document, err := decodeJSON5(bytes.NewReader(source))
+		if err != nil {
+			var json5Err *JSON5Error
+			if !errors.As(err, &json5Err) {
+				t.Fatalf("%q: expected a JSON5Error, got %v", source, err)
+			}
+			return
+		}
+		checkJSONDocument(t, source, document)
+
+		if !json.Valid(source) {
+			return
+		}
+		expected, err := decodeJSONUsingNumber(bytes.NewReader(source))
+		if err != nil || !reflect.DeepEqual(expected, document) {
+			t.Fatalf("%q: decoded as %#v, JSON is %#v", source, document, expected)
+		}
+	})
+}
+
+// checkJSONDocument fails if the document decoded from source is not a JSON document,
+// which would not be the same once written as JSON text and decoded again
+func checkJSONDocument(t *testing.T, source []byte, document interface{}) {
+	b, err := json.Marshal(document)
+	if err != nil {
+		t.Fatalf("%q: %#v is not a JSON document: %v", source, document, err)
+	}
+	again, err := decodeJSONUsingNumber(bytes.NewReader(b))
+	if err != nil || !reflect.DeepEqual(document, again) {
+		t.Fatalf("%q: %#v changes through JSON to %#v", source, document, again)
+	}
+}
+
+func TestJSON5Loader(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "schema.jsonc"), []byte(`{
+		// the port to listen on
+		"properties": {
+			"port": { "$ref": "port.json5" },
+		},
+	}`), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "port.json5"), []byte(`{type: 'integer', maximum: 0xFFFF}`), 0644))
+
+	schema, err := NewSchema(NewReferenceLoader("file://" + filepath.ToSlash(filepath.Join(dir, "schema.jsonc"))))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	result, err := schema.Validate(NewJSON5Loader(`{port: 8080, /* default */}`))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid(), "%v", result.Errors())
+
+	result, err = schema.Validate(NewJSON5Loader(`{port: 0x10000}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"number_lte (root).port"}, errorFields(result))
+
+	_, err = schema.Validate(NewJSON5Loader(`{port: Infinity}`))
+	var json5Err *JSON5Error
+	assert.True(t, errors.As(err, &json5Err))
+}
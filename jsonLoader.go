@@ -28,6 +28,7 @@ package gojsonschema
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -52,6 +53,57 @@ type JSONLoader interface {
 	LoaderFactory() JSONLoaderFactory
 }
 
+// contextJSONLoader is implemented by loaders whose loading can be aborted,
+// such as references that are fetched over HTTP
+type contextJSONLoader interface {
+	loadJSONContext(ctx context.Context) (interface{}, error)
+}
+
+// loadJSONContext loads the JSON of l, passing ctx along if the loader supports it
+func loadJSONContext(ctx context.Context, l JSONLoader) (interface{}, error) {
+	if cl, ok := l.(contextJSONLoader); ok {
+		return cl.loadJSONContext(ctx)
+	}
+	return l.LoadJSON()
+}
+
+// jsonReaderLoader is implemented by loaders that decode their document from JSON text,
+// giving access to the text itself so that it can be decoded under Limits.
+// Text in another format is opened as a *documentReader
+type jsonReaderLoader interface {
+	openJSON(ctx context.Context) (io.ReadCloser, error)
+}
+
+// documentReader is the text of a document in another format than JSON, with the function that decodes it
+type documentReader struct {
+	io.ReadCloser
+	decode func(r io.Reader) (interface{}, error)
+}
+
+// referenceDecoder returns the decoder of a referenced document that isn't JSON, found from
+// the extension of its path or its Content-Type. It is nil for JSON
+func referenceDecoder(name string, contentType string) func(r io.Reader) (interface{}, error) {
+	switch {
+	case isYAMLPath(name) || isYAMLMediaType(contentType):
+		return decodeYAML
+	case isJSON5Path(name) || strings.HasPrefix(contentType, "application/json5"):
+		return decodeJSON5
+	case isCBORPath(name) || isCBORMediaType(contentType):
+		return decodeCBOR
+	case isMessagePackPath(name) || isMessagePackMediaType(contentType):
+		return decodeMessagePack
+	}
+	return nil
+}
+
+// decodeDocument decodes text opened by a jsonReaderLoader
+func decodeDocument(r io.Reader) (interface{}, error) {
+	if dr, ok := r.(*documentReader); ok {
+		return dr.decode(dr.ReadCloser)
+	}
+	return decodeJSONUsingNumber(r)
+}
+
 // JSONLoaderFactory defines the JSON loader factory interface
 type JSONLoaderFactory interface {
 	// New creates a new JSON loader for the given source
@@ -97,6 +149,10 @@ func (o osFileSystem) Open(name string) (http.File, error) {
 type jsonReferenceLoader struct {
 	fs     http.FileSystem
 	source string
+	// http loads the HTTP references, with the defaults if nil
+	http *HTTPJSONLoaderFactory
+	// policy checks the targets of HTTP redirects if not nil
+	policy *ReferencePolicy
 }
 
 func (l *jsonReferenceLoader) JsonSource() interface{} {
@@ -108,6 +164,9 @@ func (l *jsonReferenceLoader) JsonReference() (gojsonreference.JsonReference, er
 }
 
 func (l *jsonReferenceLoader) LoaderFactory() JSONLoaderFactory {
+	if l.http != nil {
+		return l.http
+	}
 	return &FileSystemJSONLoaderFactory{
 		fs: l.fs,
 	}
@@ -130,6 +189,20 @@ func NewReferenceLoaderFileSystem(source string, fs http.FileSystem) JSONLoader
 }
 
 func (l *jsonReferenceLoader) LoadJSON() (interface{}, error) {
+	return l.loadJSONContext(context.Background())
+}
+
+func (l *jsonReferenceLoader) loadJSONContext(ctx context.Context) (interface{}, error) {
+	r, err := l.openJSON(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return decodeDocument(r)
+}
+
+func (l *jsonReferenceLoader) openJSON(ctx context.Context) (io.ReadCloser, error) {
 
 	var err error
 
@@ -141,82 +214,82 @@ func (l *jsonReferenceLoader) LoadJSON() (interface{}, error) {
 	refToURL := reference
 	refToURL.GetUrl().Fragment = ""
 
-	var document interface{}
-
 	if reference.HasFileScheme {
 
-		filename := strings.TrimPrefix(refToURL.String(), "file://")
-		filename, err = url.QueryUnescape(filename)
-
+		filename, err := referenceFilename(refToURL.String())
 		if err != nil {
 			return nil, err
 		}
 
-		if runtime.GOOS == "windows" {
-			// on Windows, a file URL may have an extra leading slash, use slashes
-			// instead of backslashes, and have spaces escaped
-			filename = strings.TrimPrefix(filename, "/")
-			filename = filepath.FromSlash(filename)
-		}
-
-		document, err = l.loadFromFile(filename)
+		file, err := l.openFile(filename)
 		if err != nil {
 			return nil, err
 		}
-
-	} else {
-
-		document, err = l.loadFromHTTP(refToURL.String())
-		if err != nil {
-			return nil, err
+		if decode := referenceDecoder(filename, ""); decode != nil {
+			return &documentReader{ReadCloser: file, decode: decode}, nil
 		}
-
+		return file, nil
 	}
 
-	return document, nil
+	return l.openHTTP(ctx, refToURL.String())
+}
 
+// referenceFilename returns the file name of a file URL without a fragment
+func referenceFilename(fileURL string) (string, error) {
+	filename, err := url.QueryUnescape(strings.TrimPrefix(fileURL, "file://"))
+	if err != nil {
+		return "", err
+	}
+
+	if runtime.GOOS == "windows" {
+		// on Windows, a file URL may have an extra leading slash, use slashes
+		// instead of backslashes, and have spaces escaped
+		filename = strings.TrimPrefix(filename, "/")
+		filename = filepath.FromSlash(filename)
+	}
+	return filename, nil
 }
 
-func (l *jsonReferenceLoader) loadFromHTTP(address string) (interface{}, error) {
+func (l *jsonReferenceLoader) openHTTP(ctx context.Context, address string) (io.ReadCloser, error) {
 
 	// returned cached versions for metaschemas for drafts 4, 6 and 7
 	// for performance and allow for easier offline use
 	if metaSchema := drafts.GetMetaSchema(address); metaSchema != "" {
-		return decodeJSONUsingNumber(strings.NewReader(metaSchema))
+		return ioutil.NopCloser(strings.NewReader(metaSchema)), nil
+	}
+
+	factory := l.http
+	if factory == nil {
+		factory = &HTTPJSONLoaderFactory{}
 	}
 
-	resp, err := http.Get(address)
+	resp, err := factory.get(ctx, address, l.policy)
 	if err != nil {
 		return nil, err
 	}
 
 	// must return HTTP Status 200 OK
 	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
 		return nil, errors.New(formatErrorDescription(Locale.HttpBadStatus(), ErrorDetails{"status": resp.Status}))
 	}
 
-	bodyBuff, err := ioutil.ReadAll(resp.Body)
+	body, err := factory.limitResponse(address, resp)
 	if err != nil {
 		return nil, err
 	}
-
-	return decodeJSONUsingNumber(bytes.NewReader(bodyBuff))
-}
-
-func (l *jsonReferenceLoader) loadFromFile(path string) (interface{}, error) {
-	f, err := l.fs.Open(path)
-	if err != nil {
-		return nil, err
+	var path string
+	if u, err := url.Parse(address); err == nil {
+		path = u.Path
 	}
-	defer f.Close()
-
-	bodyBuff, err := ioutil.ReadAll(f)
-	if err != nil {
-		return nil, err
+	if decode := referenceDecoder(path, resp.Header.Get("Content-Type")); decode != nil {
+		return &documentReader{ReadCloser: body, decode: decode}, nil
 	}
+	return body, nil
+}
 
-	return decodeJSONUsingNumber(bytes.NewReader(bodyBuff))
-
+func (l *jsonReferenceLoader) openFile(path string) (io.ReadCloser, error) {
+	return l.fs.Open(path)
 }
 
 // JSON string loader
@@ -248,6 +321,10 @@ func (l *jsonStringLoader) LoadJSON() (interface{}, error) {
 
 }
 
+func (l *jsonStringLoader) openJSON(ctx context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(l.JsonSource().(string))), nil
+}
+
 // JSON bytes loader
 
 type jsonBytesLoader struct {
@@ -275,6 +352,10 @@ func (l *jsonBytesLoader) LoadJSON() (interface{}, error) {
 	return decodeJSONUsingNumber(bytes.NewReader(l.JsonSource().([]byte)))
 }
 
+func (l *jsonBytesLoader) openJSON(ctx context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(l.JsonSource().([]byte))), nil
+}
+
 // JSON Go (types) loader
 // used to load JSONs from the code as maps, interface{}, structs ...
 
@@ -294,21 +375,20 @@ func (l *jsonGoLoader) LoaderFactory() JSONLoaderFactory {
 	return &DefaultJSONLoaderFactory{}
 }
 
-// NewGoLoader creates a new JSONLoader from a given Go struct
+// NewGoLoader creates a new JSONLoader from a given Go struct.
+// The value is converted to the document encoding/json would produce for it before it is validated.
+// Maps, slices and scalars are copied without writing them as JSON text, structs are marshalled
 func NewGoLoader(source interface{}) JSONLoader {
 	return &jsonGoLoader{source: source}
 }
 
 func (l *jsonGoLoader) LoadJSON() (interface{}, error) {
 
-	// convert it to a compliant JSON first to avoid types "mismatches"
+	// convert it to a compliant JSON document first to avoid types "mismatches",
+	// only the parts of the value holding structs are marshalled to JSON and back.
+	// Validation works on that document, not on the value itself
 
-	jsonBytes, err := json.Marshal(l.JsonSource())
-	if err != nil {
-		return nil, err
-	}
-
-	return decodeJSONUsingNumber(bytes.NewReader(jsonBytes))
+	return goToDocument(l.JsonSource())
 
 }
 
@@ -336,6 +416,10 @@ func (l *jsonIOLoader) LoadJSON() (interface{}, error) {
 	return decodeJSONUsingNumber(l.buf)
 }
 
+func (l *jsonIOLoader) openJSON(ctx context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(l.buf), nil
+}
+
 func (l *jsonIOLoader) JsonReference() (gojsonreference.JsonReference, error) {
 	return gojsonreference.NewJsonReference("#")
 }
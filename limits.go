@@ -0,0 +1,330 @@
+package gojsonschema
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonreference"
+)
+
+// Limits restricts the resources a single document may use while it is loaded and validated.
+// They are meant for documents from untrusted sources. A zero value disables the corresponding limit
+type Limits struct {
+	// MaxInputBytes is the maximum size of the JSON text
+	MaxInputBytes int64
+	// MaxNestingDepth is the maximum nesting of arrays and objects, a top-level object has a depth of 1
+	MaxNestingDepth int
+	// MaxStringLength is the maximum length in bytes of a string or a property name
+	MaxStringLength int
+	// MaxArrayLength is the maximum number of items in an array
+	MaxArrayLength int
+	// MaxObjectSize is the maximum number of properties of an object
+	MaxObjectSize int
+	// MaxNumberLength is the maximum number of characters of a number literal
+	MaxNumberLength int
+	// MaxNumberExponent is the maximum absolute exponent of a number literal, e.g. 400 for 1e400.
+	// Exponents like 1e1000000000 otherwise result in huge allocations when the number is validated
+	MaxNumberExponent int
+}
+
+// LimitError is returned when a document exceeds one of its Limits
+type LimitError struct {
+	// Limit is the name of the exceeded field of Limits, e.g. "MaxStringLength"
+	Limit string
+	// Max is the configured value of the limit
+	Max int64
+	// Context is the location in the document where the limit was exceeded
+	Context *JsonContext
+}
+
+func (e *LimitError) Error() string {
+	return formatErrorDescription(
+		Locale.LimitExceeded(),
+		ErrorDetails{"limit": e.Limit, "max": e.Max, "context": e.Context.String()},
+	)
+}
+
+func newLimitError(limit string, max int64, context *JsonContext) *LimitError {
+	return &LimitError{Limit: limit, Max: max, Context: context}
+}
+
+func (l Limits) checkDepth(depth int, context *JsonContext) error {
+	if l.MaxNestingDepth > 0 && depth > l.MaxNestingDepth {
+		return newLimitError("MaxNestingDepth", int64(l.MaxNestingDepth), context)
+	}
+	return nil
+}
+
+func (l Limits) checkString(s string, context *JsonContext) error {
+	if l.MaxStringLength > 0 && len(s) > l.MaxStringLength {
+		return newLimitError("MaxStringLength", int64(l.MaxStringLength), context)
+	}
+	return nil
+}
+
+func (l Limits) checkArrayLength(n int, context *JsonContext) error {
+	if l.MaxArrayLength > 0 && n > l.MaxArrayLength {
+		return newLimitError("MaxArrayLength", int64(l.MaxArrayLength), context)
+	}
+	return nil
+}
+
+func (l Limits) checkObjectSize(n int, context *JsonContext) error {
+	if l.MaxObjectSize > 0 && n > l.MaxObjectSize {
+		return newLimitError("MaxObjectSize", int64(l.MaxObjectSize), context)
+	}
+	return nil
+}
+
+func (l Limits) checkNumber(number json.Number, context *JsonContext) error {
+	s := string(number)
+	if l.MaxNumberLength > 0 && len(s) > l.MaxNumberLength {
+		return newLimitError("MaxNumberLength", int64(l.MaxNumberLength), context)
+	}
+	if l.MaxNumberExponent > 0 {
+		if i := strings.IndexAny(s, "eE"); i >= 0 {
+			exponent, err := strconv.Atoi(s[i+1:])
+			if err != nil || exponent > l.MaxNumberExponent || exponent < -l.MaxNumberExponent {
+				return newLimitError("MaxNumberExponent", int64(l.MaxNumberExponent), context)
+			}
+		}
+	}
+	return nil
+}
+
+// checkDocument checks a document that has already been decoded, MaxInputBytes does not apply
+func (l Limits) checkDocument(document interface{}, context *JsonContext, depth int) error {
+	switch node := document.(type) {
+	case map[string]interface{}:
+		if err := l.checkDepth(depth+1, context); err != nil {
+			return err
+		}
+		if err := l.checkObjectSize(len(node), context); err != nil {
+			return err
+		}
+		for k, v := range node {
+			subContext := NewJsonContext(k, context)
+			if err := l.checkString(k, subContext); err != nil {
+				return err
+			}
+			if err := l.checkDocument(v, subContext, depth+1); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if err := l.checkDepth(depth+1, context); err != nil {
+			return err
+		}
+		if err := l.checkArrayLength(len(node), context); err != nil {
+			return err
+		}
+		for i, v := range node {
+			if err := l.checkDocument(v, NewJsonContext(strconv.Itoa(i), context), depth+1); err != nil {
+				return err
+			}
+		}
+	case string:
+		return l.checkString(node, context)
+	case json.Number:
+		return l.checkNumber(node, context)
+	}
+	return nil
+}
+
+// limitedReader fails with a LimitError as soon as more than remaining bytes are read.
+// Unlike io.LimitReader it never silently truncates the input
+type limitedReader struct {
+	r         io.Reader
+	max       int64
+	remaining int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		var probe [1]byte
+		n, err := r.r.Read(probe[:])
+		if n > 0 {
+			return 0, newLimitError("MaxInputBytes", r.max, NewJsonContext(STRING_CONTEXT_ROOT, nil))
+		}
+		return 0, err
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// limitInput fails reading r past MaxInputBytes
+func limitInput(r io.Reader, limits Limits) io.Reader {
+	if limits.MaxInputBytes > 0 {
+		return &limitedReader{r: r, max: limits.MaxInputBytes, remaining: limits.MaxInputBytes}
+	}
+	return r
+}
+
+// limitedDecoder decodes JSON token by token, so limits are enforced before
+// an oversized value is ever materialized
+type limitedDecoder struct {
+	decoder *json.Decoder
+	limits  Limits
+}
+
+// decodeJSONWithLimits decodes like decodeJSONUsingNumber while enforcing limits
+func decodeJSONWithLimits(r io.Reader, limits Limits) (interface{}, error) {
+	decoder := json.NewDecoder(limitInput(r, limits))
+	decoder.UseNumber()
+
+	d := limitedDecoder{decoder: decoder, limits: limits}
+	return d.decodeValue(NewJsonContext(STRING_CONTEXT_ROOT, nil), 0)
+}
+
+func (d *limitedDecoder) decodeValue(context *JsonContext, depth int) (interface{}, error) {
+	token, err := d.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if err := d.limits.checkDepth(depth+1, context); err != nil {
+			return nil, err
+		}
+		if t == '{' {
+			return d.decodeObject(context, depth+1)
+		}
+		return d.decodeArray(context, depth+1)
+	case string:
+		return t, d.limits.checkString(t, context)
+	case json.Number:
+		return t, d.limits.checkNumber(t, context)
+	}
+
+	// bool or nil
+	return token, nil
+}
+
+func (d *limitedDecoder) decodeObject(context *JsonContext, depth int) (interface{}, error) {
+	object := make(map[string]interface{})
+
+	for d.decoder.More() {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := token.(string)
+		subContext := NewJsonContext(key, context)
+		if err := d.limits.checkString(key, subContext); err != nil {
+			return nil, err
+		}
+
+		value, err := d.decodeValue(subContext, depth)
+		if err != nil {
+			return nil, err
+		}
+		object[key] = value
+
+		if err := d.limits.checkObjectSize(len(object), context); err != nil {
+			return nil, err
+		}
+	}
+
+	// closing '}'
+	if _, err := d.decoder.Token(); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (d *limitedDecoder) decodeArray(context *JsonContext, depth int) (interface{}, error) {
+	array := []interface{}{}
+
+	for d.decoder.More() {
+		if err := d.limits.checkArrayLength(len(array)+1, context); err != nil {
+			return nil, err
+		}
+		value, err := d.decodeValue(NewJsonContext(strconv.Itoa(len(array)), context), depth)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+
+	// closing ']'
+	if _, err := d.decoder.Token(); err != nil {
+		return nil, err
+	}
+	return array, nil
+}
+
+// loadJSONLimited loads the document of l while enforcing limits.
+// Loaders that decode JSON text are decoded under the limits, other documents are checked once loaded
+func loadJSONLimited(ctx context.Context, l JSONLoader, limits Limits) (interface{}, error) {
+	if limits == (Limits{}) {
+		return loadJSONContext(ctx, l)
+	}
+
+	var document interface{}
+	var err error
+	if rl, ok := l.(jsonReaderLoader); ok {
+		r, openErr := rl.openJSON(ctx)
+		if openErr != nil {
+			return nil, openErr
+		}
+		defer r.Close()
+
+		dr, ok := r.(*documentReader)
+		if !ok {
+			return decodeJSONWithLimits(r, limits)
+		}
+		// Other formats are decoded in full, MaxInputBytes applies to their text
+		document, err = dr.decode(limitInput(dr.ReadCloser, limits))
+	} else {
+		document, err = loadJSONContext(ctx, l)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.checkDocument(document, NewJsonContext(STRING_CONTEXT_ROOT, nil), 0); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// JSON limited loader
+// wraps another loader and enforces Limits while loading its document
+
+type jsonLimitedLoader struct {
+	loader JSONLoader
+	limits Limits
+}
+
+// NewLimitedLoader creates a new JSONLoader that loads the document of loader while enforcing limits.
+// Exceeding a limit makes LoadJSON return a *LimitError
+func NewLimitedLoader(loader JSONLoader, limits Limits) JSONLoader {
+	return &jsonLimitedLoader{loader: loader, limits: limits}
+}
+
+func (l *jsonLimitedLoader) JsonSource() interface{} {
+	return l.loader.JsonSource()
+}
+
+func (l *jsonLimitedLoader) LoadJSON() (interface{}, error) {
+	return l.loadJSONContext(context.Background())
+}
+
+func (l *jsonLimitedLoader) loadJSONContext(ctx context.Context) (interface{}, error) {
+	return loadJSONLimited(ctx, l.loader, l.limits)
+}
+
+func (l *jsonLimitedLoader) JsonReference() (gojsonreference.JsonReference, error) {
+	return l.loader.JsonReference()
+}
+
+func (l *jsonLimitedLoader) LoaderFactory() JSONLoaderFactory {
+	return l.loader.LoaderFactory()
+}
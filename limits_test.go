@@ -0,0 +1,83 @@
+package gojsonschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits(t *testing.T) {
+	cases := []struct {
+		limits   Limits
+		document string
+		limit    string
+		context  string
+	}{
+		{Limits{MaxInputBytes: 10}, `{"a" : "0123456789"}`, "MaxInputBytes", "(root)"},
+		{Limits{MaxNestingDepth: 2}, `{"a" : [[1]]}`, "MaxNestingDepth", "(root).a.0"},
+		{Limits{MaxStringLength: 3}, `{"a" : ["abcd"]}`, "MaxStringLength", "(root).a.0"},
+		{Limits{MaxStringLength: 3}, `{"abcd" : 1}`, "MaxStringLength", "(root).abcd"},
+		{Limits{MaxArrayLength: 2}, `[1, 2, 3]`, "MaxArrayLength", "(root)"},
+		{Limits{MaxObjectSize: 1}, `{"a" : {"b" : 1, "c" : 2}}`, "MaxObjectSize", "(root).a"},
+		{Limits{MaxNumberLength: 4}, `[12345]`, "MaxNumberLength", "(root).0"},
+		{Limits{MaxNumberExponent: 400}, `1e1000000000`, "MaxNumberExponent", "(root)"},
+		{Limits{MaxNumberExponent: 400}, `1E-401`, "MaxNumberExponent", "(root)"},
+	}
+
+	for _, c := range cases {
+		sl := NewSchemaLoader()
+		sl.Limits = c.limits
+		schema, err := sl.Compile(NewStringLoader(`{}`))
+		assert.Nil(t, err)
+
+		// Decoded under the limits
+		_, err = schema.Validate(NewStringLoader(c.document))
+		var limitErr *LimitError
+		if assert.True(t, errors.As(err, &limitErr), "%s: expected a LimitError, got %v", c.document, err) {
+			assert.Equal(t, c.limit, limitErr.Limit)
+			assert.Equal(t, c.context, limitErr.Context.String())
+		}
+
+		// Checked after decoding
+		if c.limit == "MaxInputBytes" {
+			continue
+		}
+		document, err := NewStringLoader(c.document).LoadJSON()
+		assert.Nil(t, err)
+		_, err = schema.Validate(NewRawLoader(document))
+		if assert.True(t, errors.As(err, &limitErr), "%s: expected a LimitError, got %v", c.document, err) {
+			assert.Equal(t, c.limit, limitErr.Limit)
+		}
+	}
+}
+
+func TestLimitsWithinBounds(t *testing.T) {
+	limits := Limits{
+		MaxInputBytes:     64,
+		MaxNestingDepth:   2,
+		MaxStringLength:   5,
+		MaxArrayLength:    3,
+		MaxObjectSize:     2,
+		MaxNumberLength:   6,
+		MaxNumberExponent: 10,
+	}
+	document := `{"a" : [1, 2.5, "abcde"], "b" : 1e10}`
+
+	loader := NewLimitedLoader(NewStringLoader(document), limits)
+	limited, err := loader.LoadJSON()
+	assert.Nil(t, err)
+	unlimited, err := NewStringLoader(document).LoadJSON()
+	assert.Nil(t, err)
+	assert.Equal(t, unlimited, limited)
+
+	schema, err := NewSchema(NewStringLoader(`{"properties" : {"b" : {"maximum" : 1}}}`))
+	assert.Nil(t, err)
+	result, err := schema.Validate(loader)
+	assert.Nil(t, err)
+	assert.False(t, result.Valid())
+
+	_, err = NewLimitedLoader(NewStringLoader(strings.Repeat("[", 3)+strings.Repeat("]", 3)), limits).LoadJSON()
+	assert.IsType(t, &LimitError{}, err)
+}
@@ -0,0 +1,164 @@
+package gojsonschema
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// LineResult is the outcome of validating one record of a newline-delimited JSON stream
+type LineResult struct {
+	// Line is the line number of the record, starting at 1
+	Line int
+	// Offset is the byte offset of the start of the record in the stream
+	Offset int64
+	// Result is the validation result, nil if the record could not be decoded
+	Result *Result
+	// Err is the error decoding the record, e.g. a syntax or a *LimitError
+	Err error
+}
+
+// LinesSummary counts the records of a newline-delimited JSON stream
+type LinesSummary struct {
+	// Records is the number of non-blank lines
+	Records int
+	// Valid and Invalid count the records that did or did not pass validation
+	Valid   int
+	Invalid int
+	// Failed counts the records that could not be decoded
+	Failed int
+}
+
+// ValidateLines validates every line of the newline-delimited JSON (NDJSON, JSON Lines) stream r
+// as a separate document and calls fn with its result. Blank lines are skipped.
+//
+// Records are read one at a time, so r may be endless. Reading stops at the end of r,
+// when ctx is done or when fn returns an error, which is then returned along with the counts so far.
+// A record whose validation is cancelled by ctx is neither passed to fn nor counted.
+// Limits apply to each record, MaxInputBytes being the maximum length of a line
+func (v *Schema) ValidateLines(ctx context.Context, r io.Reader, fn func(*LineResult) error) (LinesSummary, error) {
+	var summary LinesSummary
+
+	reader := bufio.NewReader(r)
+	var offset int64
+
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		record, n, tooLong, err := v.readLine(reader)
+		if err != nil && err != io.EOF {
+			return summary, err
+		}
+		eof := err == io.EOF
+
+		if tooLong || len(bytes.TrimSpace(record)) > 0 {
+			lineResult := &LineResult{Line: line, Offset: offset}
+			if tooLong {
+				lineResult.Err = newLimitError("MaxInputBytes", v.limits.MaxInputBytes, NewJsonContext(STRING_CONTEXT_ROOT, nil))
+			} else if document, err := v.decodeRecord(record); err != nil {
+				lineResult.Err = err
+			} else if lineResult.Result, err = v.validateDocumentContext(ctx, document); err != nil {
+				// The record was cut short, it is neither valid nor invalid
+				return summary, err
+			}
+
+			summary.Records++
+			switch {
+			case lineResult.Err != nil:
+				summary.Failed++
+			case lineResult.Result.Valid():
+				summary.Valid++
+			default:
+				summary.Invalid++
+			}
+
+			if err := fn(lineResult); err != nil {
+				return summary, err
+			}
+		}
+
+		offset += n
+		if eof {
+			return summary, nil
+		}
+	}
+}
+
+// ValidateLinesChan is ValidateLines sending every LineResult to results, which is closed on return
+func (v *Schema) ValidateLinesChan(ctx context.Context, r io.Reader, results chan<- *LineResult) (LinesSummary, error) {
+	defer close(results)
+
+	return v.ValidateLines(ctx, r, func(lineResult *LineResult) error {
+		select {
+		case results <- lineResult:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// readLine reads the next line of reader without its line ending.
+// n is the number of bytes consumed. A line longer than MaxInputBytes is discarded and reported as tooLong
+func (v *Schema) readLine(reader *bufio.Reader) (line []byte, n int64, tooLong bool, err error) {
+	max := v.limits.MaxInputBytes
+
+	for {
+		var chunk []byte
+		chunk, err = reader.ReadSlice('\n')
+		n += int64(len(chunk))
+
+		if !tooLong {
+			line = append(line, chunk...)
+			if max > 0 && int64(len(bytes.TrimRight(line, "\r\n"))) > max {
+				tooLong = true
+				line = nil
+			}
+		}
+
+		if err != bufio.ErrBufferFull {
+			break
+		}
+	}
+
+	if err == io.EOF && n > 0 {
+		// a last line without a line ending, EOF is reported on the next call
+		return bytes.TrimRight(line, "\r\n"), n, tooLong, nil
+	}
+	return bytes.TrimRight(line, "\r\n"), n, tooLong, err
+}
+
+// decodeRecord decodes a single record under the limits of v
+func (v *Schema) decodeRecord(record []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(record))
+	decoder.UseNumber()
+
+	var (
+		document interface{}
+		err      error
+	)
+	if v.limits == (Limits{}) {
+		err = decoder.Decode(&document)
+	} else {
+		d := limitedDecoder{decoder: decoder, limits: v.limits}
+		document, err = d.decodeValue(NewJsonContext(STRING_CONTEXT_ROOT, nil), 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A record is a single value
+	if _, err := decoder.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("invalid character after top-level value")
+		}
+		return nil, err
+	}
+
+	return document, nil
+}
@@ -0,0 +1,152 @@
+package gojsonschema
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLines(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{"type" : "object", "required" : ["id"]}`))
+	assert.Nil(t, err)
+
+	stream := "{\"id\" : 1}\n" +
+		"\n" +
+		"{\"name\" : \"a\"}\r\n" +
+		"{\"id\" : \n" +
+		"{\"id\" : 2} {\"id\" : 3}\n" +
+		"{\"id\" : 4}"
+
+	var lines []*LineResult
+	summary, err := schema.ValidateLines(context.Background(), strings.NewReader(stream), func(lineResult *LineResult) error {
+		lines = append(lines, lineResult)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, LinesSummary{Records: 5, Valid: 2, Invalid: 1, Failed: 2}, summary)
+
+	if assert.Len(t, lines, 5) {
+		assert.Equal(t, 1, lines[0].Line)
+		assert.Equal(t, int64(0), lines[0].Offset)
+		assert.True(t, lines[0].Result.Valid())
+
+		assert.Equal(t, 3, lines[1].Line)
+		assert.Equal(t, int64(12), lines[1].Offset)
+		assert.False(t, lines[1].Result.Valid())
+
+		assert.Equal(t, 4, lines[2].Line)
+		assert.NotNil(t, lines[2].Err)
+		assert.Nil(t, lines[2].Result)
+
+		assert.Equal(t, 5, lines[3].Line)
+		assert.NotNil(t, lines[3].Err)
+
+		assert.Equal(t, 6, lines[4].Line)
+		assert.Equal(t, int64(len(stream)-len(`{"id" : 4}`)), lines[4].Offset)
+		assert.True(t, lines[4].Result.Valid())
+	}
+}
+
+func TestValidateLinesLimits(t *testing.T) {
+	sl := NewSchemaLoader()
+	sl.Limits = Limits{MaxInputBytes: 20}
+	schema, err := sl.Compile(NewStringLoader(`{}`))
+	assert.Nil(t, err)
+
+	stream := "[1]\n" + "[" + strings.Repeat("1,", 5000) + "1]\n" + "[2]\n"
+
+	var lines []*LineResult
+	summary, err := schema.ValidateLines(context.Background(), strings.NewReader(stream), func(lineResult *LineResult) error {
+		lines = append(lines, lineResult)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, LinesSummary{Records: 3, Valid: 2, Failed: 1}, summary)
+	if assert.Len(t, lines, 3) {
+		assert.IsType(t, &LimitError{}, lines[1].Err)
+		assert.Equal(t, 3, lines[2].Line)
+		assert.Equal(t, int64(len(stream)-len("[2]\n")), lines[2].Offset)
+	}
+}
+
+func TestValidateLinesChan(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{"type" : "integer"}`))
+	assert.Nil(t, err)
+
+	results := make(chan *LineResult)
+	var summary LinesSummary
+	done := make(chan error)
+	go func() {
+		var err error
+		summary, err = schema.ValidateLinesChan(context.Background(), strings.NewReader("1\n\"a\"\n3\n"), results)
+		done <- err
+	}()
+
+	var valid []bool
+	for lineResult := range results {
+		valid = append(valid, lineResult.Result.Valid())
+	}
+	assert.Nil(t, <-done)
+	assert.Equal(t, []bool{true, false, true}, valid)
+	assert.Equal(t, LinesSummary{Records: 3, Valid: 2, Invalid: 1}, summary)
+
+	// A callback error stops the validation
+	stop := errors.New("stop")
+	summary, err = schema.ValidateLines(context.Background(), strings.NewReader("1\n2\n3\n"), func(*LineResult) error {
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, summary.Records)
+}
+
+// A record whose validation is cancelled is neither reported nor counted
+func TestValidateLinesCancelledRecord(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{"type" : "array", "items" : {"type" : "integer"}}`))
+	assert.Nil(t, err)
+
+	ctx := &countdownContext{Context: context.Background(), left: -1, done: make(chan struct{})}
+	stream := "[1]\n" + "[" + strings.Repeat("1,", 1000) + "\"a\"]\n" + "[2]\n"
+
+	var lines []*LineResult
+	summary, err := schema.ValidateLines(ctx, strings.NewReader(stream), func(lineResult *LineResult) error {
+		lines = append(lines, lineResult)
+		// the next record is cancelled in the middle of its walk
+		ctx.left = 10
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, LinesSummary{Records: 1, Valid: 1}, summary)
+	assert.Len(t, lines, 1)
+}
+
+// countdownContext is done once Done has been called left more times, it is never done while left is negative
+type countdownContext struct {
+	context.Context
+	left int
+	done chan struct{}
+}
+
+func (c *countdownContext) Done() <-chan struct{} {
+	if c.left == 0 {
+		select {
+		case <-c.done:
+		default:
+			close(c.done)
+		}
+	} else if c.left > 0 {
+		c.left--
+	}
+	return c.done
+}
+
+func (c *countdownContext) Err() error {
+	select {
+	case <-c.done:
+		return context.Canceled
+	default:
+		return nil
+	}
+}
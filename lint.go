@@ -0,0 +1,453 @@
+package gojsonschema
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LintSeverity is how serious a LintFinding is
+type LintSeverity string
+
+const (
+	// LintError is a mistake that makes a schema reject documents it is meant to accept
+	LintError LintSeverity = "error"
+	// LintWarning is a keyword that is most likely not doing what was intended
+	LintWarning LintSeverity = "warning"
+	// LintInfo is worth a look, but may well be intended
+	LintInfo LintSeverity = "info"
+)
+
+// LintRule identifies the check that reported a LintFinding
+type LintRule string
+
+const (
+	// LintRuleBounds reports a lower bound above its upper bound, e.g. a minimum greater than the maximum
+	LintRuleBounds LintRule = "bounds"
+	// LintRuleTypeMismatch reports a keyword for values of another type than the schema accepts, e.g. pattern on an integer
+	LintRuleTypeMismatch LintRule = "type-mismatch"
+	// LintRuleUndeclaredRequired reports a required property that additionalProperties false doesn't allow
+	LintRuleUndeclaredRequired LintRule = "undeclared-required"
+	// LintRuleUnknownKeyword reports a keyword that looks like a misspelled one, e.g. "requried"
+	LintRuleUnknownKeyword LintRule = "unknown-keyword"
+	// LintRuleUnusedDefinition reports a definition no $ref points to
+	LintRuleUnusedDefinition LintRule = "unused-definition"
+	// LintRuleInvalidDefault reports a default or an example that doesn't validate against its own schema
+	LintRuleInvalidDefault LintRule = "invalid-default"
+	// LintRuleDraftKeyword reports a keyword of another draft, which the draft of the schema ignores
+	LintRuleDraftKeyword LintRule = "draft-keyword"
+)
+
+// LintFinding is an authoring mistake found by Lint
+type LintFinding struct {
+	// Pointer is the JSON Pointer of the offending keyword in the schema document, e.g. "/properties/age/minimum"
+	Pointer  string
+	Rule     LintRule
+	Severity LintSeverity
+	Message  string
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s: %s [%s] %s", f.Pointer, f.Severity, f.Rule, f.Message)
+}
+
+// Lint checks root for common authoring mistakes, see SchemaLoader.Lint
+func Lint(root JSONLoader) ([]LintFinding, error) {
+	return NewSchemaLoader().Lint(root)
+}
+
+// Lint compiles root, and checks it for mistakes that compile fine but are unlikely to be intended,
+// like a minimum greater than the maximum or a misspelled keyword. Only the root document is checked,
+// the documents it references are followed to find out which definitions are used.
+// An error is returned if root doesn't compile, the findings are sorted by their Pointer
+func (sl *SchemaLoader) Lint(root JSONLoader) ([]LintFinding, error) {
+	schema, err := sl.Compile(root)
+	if err != nil {
+		return nil, err
+	}
+	spd, err := schema.pool.GetDocument(schema.documentReference, schema.loaderFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	draft := sl.Draft
+	if spd.Draft != nil {
+		draft = *spd.Draft
+	}
+	l := &linter{schema: schema, draft: draft}
+	l.schemaNode(spd.Document, "")
+	l.unusedDefinitions(spd.Document)
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		return l.findings[i].Pointer < l.findings[j].Pointer
+	})
+	return l.findings, nil
+}
+
+type linter struct {
+	schema      *Schema
+	draft       Draft
+	findings    []LintFinding
+	definitions []string
+}
+
+func (l *linter) report(pointer string, rule LintRule, severity LintSeverity, format string, args ...interface{}) {
+	l.findings = append(l.findings, LintFinding{
+		Pointer:  pointer,
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// schemaNode checks the schema at pointer, and the schemas it contains
+func (l *linter) schemaNode(node interface{}, pointer string) {
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		child := pointer + "/" + escapePointerToken(k)
+		l.keyword(m, k, child)
+
+		switch k {
+		case KEY_DEFINITIONS, KEY_PROPERTIES, KEY_PATTERN_PROPERTIES, KEY_DEPENDENCIES:
+			schemas, ok := m[k].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			names := make([]string, 0, len(schemas))
+			for name := range schemas {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				location := child + "/" + escapePointerToken(name)
+				if k == KEY_DEFINITIONS {
+					l.definitions = append(l.definitions, location)
+				}
+				l.schemaNode(schemas[name], location)
+			}
+		case KEY_ITEMS, KEY_ALL_OF, KEY_ANY_OF, KEY_ONE_OF:
+			if list, ok := m[k].([]interface{}); ok {
+				for i, item := range list {
+					l.schemaNode(item, child+"/"+strconv.Itoa(i))
+				}
+				continue
+			}
+			l.schemaNode(m[k], child)
+		case KEY_ADDITIONAL_ITEMS, KEY_ADDITIONAL_PROPERTIES, KEY_PROPERTY_NAMES, KEY_CONTAINS, KEY_NOT, KEY_IF, KEY_THEN, KEY_ELSE:
+			l.schemaNode(m[k], child)
+		}
+	}
+
+	l.bounds(m, pointer)
+	l.requiredProperties(m, pointer)
+	l.defaults(m, pointer)
+}
+
+// keyword checks that keyword k of schema m is a keyword of the draft, that applies to the type of m
+func (l *linter) keyword(m map[string]interface{}, k string, pointer string) {
+	keywords := draftKeywords()
+
+	if !keywords[Hybrid][k] {
+		if suggestion := similarKeyword(k); suggestion != "" {
+			l.report(pointer, LintRuleUnknownKeyword, LintWarning, "%s is not a keyword, did you mean %s?", k, suggestion)
+		}
+		return
+	}
+	if l.draft != Hybrid && !keywords[l.draft][k] {
+		l.report(pointer, LintRuleDraftKeyword, LintWarning, "%s is not a keyword of draft %d, it is ignored", k, l.draft)
+		return
+	}
+
+	applies, ok := keywordTypes[k]
+	if !ok {
+		return
+	}
+	types := typeList(m[KEY_TYPE])
+	if types == nil {
+		return
+	}
+	for _, t := range types {
+		if t == applies || t == TYPE_INTEGER && applies == TYPE_NUMBER {
+			return
+		}
+	}
+	l.report(pointer, LintRuleTypeMismatch, LintWarning, "%s applies to values of type %s, it has no effect on a schema of type %s", k, applies, strings.Join(types, ", "))
+}
+
+// keywordTypes are the types of the values the validation keywords apply to
+var keywordTypes = map[string]string{
+	KEY_PATTERN:               TYPE_STRING,
+	KEY_MIN_LENGTH:            TYPE_STRING,
+	KEY_MAX_LENGTH:            TYPE_STRING,
+	KEY_MULTIPLE_OF:           TYPE_NUMBER,
+	KEY_MINIMUM:               TYPE_NUMBER,
+	KEY_MAXIMUM:               TYPE_NUMBER,
+	KEY_EXCLUSIVE_MINIMUM:     TYPE_NUMBER,
+	KEY_EXCLUSIVE_MAXIMUM:     TYPE_NUMBER,
+	KEY_ITEMS:                 TYPE_ARRAY,
+	KEY_ADDITIONAL_ITEMS:      TYPE_ARRAY,
+	KEY_MIN_ITEMS:             TYPE_ARRAY,
+	KEY_MAX_ITEMS:             TYPE_ARRAY,
+	KEY_UNIQUE_ITEMS:          TYPE_ARRAY,
+	KEY_CONTAINS:              TYPE_ARRAY,
+	KEY_PROPERTIES:            TYPE_OBJECT,
+	KEY_PATTERN_PROPERTIES:    TYPE_OBJECT,
+	KEY_ADDITIONAL_PROPERTIES: TYPE_OBJECT,
+	KEY_REQUIRED:              TYPE_OBJECT,
+	KEY_MIN_PROPERTIES:        TYPE_OBJECT,
+	KEY_MAX_PROPERTIES:        TYPE_OBJECT,
+	KEY_DEPENDENCIES:          TYPE_OBJECT,
+	KEY_PROPERTY_NAMES:        TYPE_OBJECT,
+}
+
+var (
+	draftKeywordsOnce sync.Once
+	draftKeywordsSet  map[Draft]map[string]bool
+)
+
+// draftKeywords returns the keywords of every draft, as declared by its meta-schema. Hybrid has the keywords of all drafts
+func draftKeywords() map[Draft]map[string]bool {
+	draftKeywordsOnce.Do(func() {
+		draftKeywordsSet = map[Draft]map[string]bool{Hybrid: {KEY_REF: true}}
+		for _, config := range drafts {
+			keywords := map[string]bool{KEY_REF: true}
+			metaSchema, err := decodeJSONUsingNumber(strings.NewReader(config.MetaSchema))
+			if err != nil {
+				panic(err)
+			}
+			properties := metaSchema.(map[string]interface{})[KEY_PROPERTIES].(map[string]interface{})
+			for k := range properties {
+				keywords[k] = true
+				draftKeywordsSet[Hybrid][k] = true
+			}
+			draftKeywordsSet[config.Version] = keywords
+		}
+	})
+	return draftKeywordsSet
+}
+
+// similarKeyword returns the keyword k is most likely a misspelling of, or "" if there is none
+func similarKeyword(k string) string {
+	var keywords []string
+	for keyword := range draftKeywords()[Hybrid] {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	best, bestDistance := "", 0
+	for _, keyword := range keywords {
+		if strings.EqualFold(k, keyword) {
+			return keyword
+		}
+		if len(k) < 4 {
+			continue
+		}
+		maxDistance := 1
+		if len(keyword) > 6 {
+			maxDistance = 2
+		}
+		distance := editDistance(k, keyword)
+		if distance <= maxDistance && (best == "" || distance < bestDistance) {
+			best, bestDistance = keyword, distance
+		}
+	}
+	return best
+}
+
+// editDistance is the number of insertions, deletions, substitutions and transpositions of adjacent characters
+// that turn a into b
+func editDistance(a string, b string) int {
+	x, y := []rune(a), []rune(b)
+	d := make([][]int, len(x)+1)
+	for i := range d {
+		d[i] = make([]int, len(y)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && x[i-1] == y[j-2] && x[i-2] == y[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(x)][len(y)]
+}
+
+// bound is a lower or upper bound of a schema, of the values of a kind like numbers or string lengths
+type bound struct {
+	kind      string
+	keyword   string
+	value     interface{}
+	exclusive bool
+}
+
+// bounds reports the lower bounds of m that are above its upper bounds
+func (l *linter) bounds(m map[string]interface{}, pointer string) {
+	var lower, upper []bound
+	if existsMapKey(m, KEY_MINIMUM) {
+		exclusive, _ := m[KEY_EXCLUSIVE_MINIMUM].(bool)
+		lower = append(lower, bound{TYPE_NUMBER, KEY_MINIMUM, m[KEY_MINIMUM], exclusive})
+	}
+	if isJSONNumber(m[KEY_EXCLUSIVE_MINIMUM]) {
+		lower = append(lower, bound{TYPE_NUMBER, KEY_EXCLUSIVE_MINIMUM, m[KEY_EXCLUSIVE_MINIMUM], true})
+	}
+	if existsMapKey(m, KEY_MAXIMUM) {
+		exclusive, _ := m[KEY_EXCLUSIVE_MAXIMUM].(bool)
+		upper = append(upper, bound{TYPE_NUMBER, KEY_MAXIMUM, m[KEY_MAXIMUM], exclusive})
+	}
+	if isJSONNumber(m[KEY_EXCLUSIVE_MAXIMUM]) {
+		upper = append(upper, bound{TYPE_NUMBER, KEY_EXCLUSIVE_MAXIMUM, m[KEY_EXCLUSIVE_MAXIMUM], true})
+	}
+	for _, pair := range [][2]string{
+		{KEY_MIN_LENGTH, KEY_MAX_LENGTH},
+		{KEY_MIN_ITEMS, KEY_MAX_ITEMS},
+		{KEY_MIN_PROPERTIES, KEY_MAX_PROPERTIES},
+	} {
+		if existsMapKey(m, pair[0]) {
+			lower = append(lower, bound{kind: pair[0], keyword: pair[0], value: m[pair[0]]})
+		}
+		if existsMapKey(m, pair[1]) {
+			upper = append(upper, bound{kind: pair[0], keyword: pair[1], value: m[pair[1]]})
+		}
+	}
+
+	for _, lo := range lower {
+		for _, up := range upper {
+			if lo.kind != up.kind {
+				continue
+			}
+			x, y := mustBeNumber(lo.value), mustBeNumber(up.value)
+			if x == nil || y == nil {
+				continue
+			}
+			if c := x.Cmp(y); c > 0 || c == 0 && (lo.exclusive || up.exclusive) {
+				l.report(pointer+"/"+lo.keyword, LintRuleBounds, LintError, "%s %s is above %s %s, no value is valid",
+					lo.keyword, x.RatString(), up.keyword, y.RatString())
+			}
+		}
+	}
+}
+
+// requiredProperties reports the required properties of m that its additionalProperties false doesn't allow
+func (l *linter) requiredProperties(m map[string]interface{}, pointer string) {
+	if additional, ok := m[KEY_ADDITIONAL_PROPERTIES].(bool); !ok || additional {
+		return
+	}
+	required, ok := m[KEY_REQUIRED].([]interface{})
+	if !ok {
+		return
+	}
+	properties, _ := m[KEY_PROPERTIES].(map[string]interface{})
+	patternProperties, _ := m[KEY_PATTERN_PROPERTIES].(map[string]interface{})
+
+next:
+	for i, r := range required {
+		name, ok := r.(string)
+		if !ok || existsMapKey(properties, name) {
+			continue
+		}
+		for pattern := range patternProperties {
+			if matches, _ := regexp.MatchString(pattern, name); matches {
+				continue next
+			}
+		}
+		l.report(pointer+"/"+KEY_REQUIRED+"/"+strconv.Itoa(i), LintRuleUndeclaredRequired, LintError,
+			"%s is required but not declared in properties, and additionalProperties is false: no object is valid", name)
+	}
+}
+
+// defaults reports the default and examples of m that don't validate against m
+func (l *linter) defaults(m map[string]interface{}, pointer string) {
+	if !existsMapKey(m, KEY_DEFAULT) && !existsMapKey(m, KEY_EXAMPLES) {
+		return
+	}
+	sub, err := l.schema.SubSchema((&url.URL{Fragment: pointer}).EscapedFragment())
+	if err != nil {
+		return
+	}
+
+	check := func(value interface{}, location string, what string) {
+		result, err := sub.Validate(NewGoLoader(value))
+		if err != nil || result.Valid() {
+			return
+		}
+		errs := make([]string, len(result.Errors()))
+		for i, e := range result.Errors() {
+			errs[i] = e.Field() + ": " + e.Description()
+		}
+		l.report(location, LintRuleInvalidDefault, LintWarning, "%s doesn't validate against its schema: %s", what, strings.Join(errs, "; "))
+	}
+
+	if existsMapKey(m, KEY_DEFAULT) {
+		check(m[KEY_DEFAULT], pointer+"/"+KEY_DEFAULT, "the default")
+	}
+	if examples, ok := m[KEY_EXAMPLES].([]interface{}); ok {
+		for i, example := range examples {
+			check(example, pointer+"/"+KEY_EXAMPLES+"/"+strconv.Itoa(i), "example "+strconv.Itoa(i))
+		}
+	}
+}
+
+// unusedDefinitions reports the definitions of document that no $ref points to. References are resolved
+// like Bundle resolves them, following the references of the documents root references
+func (l *linter) unusedDefinitions(document interface{}) {
+	rootMap, ok := copyDocument(document).(map[string]interface{})
+	if !ok || len(l.definitions) == 0 {
+		return
+	}
+	// documents are embedded under names that aren't taken
+	b := &bundler{
+		schema:      l.schema,
+		draft:       Hybrid,
+		root:        rootMap,
+		definitions: KEY_DEFINITIONS,
+		locations:   make(map[string]string),
+	}
+	b.locate(l.schema.documentReference.String(), "")
+	b.walk(rootMap, "", l.schema.documentReference, true)
+	for i := 0; i < len(b.refs); i++ {
+		if _, ok := b.resolve(b.refs[i].ref); !ok {
+			// a document that can't be embedded has no references to follow
+			b.embed(b.refs[i].ref)
+		}
+	}
+
+	var used []string
+	for _, r := range b.refs {
+		if pointer, ok := b.resolve(r.ref); ok {
+			used = append(used, pointer)
+		}
+	}
+
+next:
+	for _, definition := range l.definitions {
+		for _, pointer := range used {
+			if pointer == definition || strings.HasPrefix(pointer, definition+"/") {
+				continue next
+			}
+		}
+		l.report(definition, LintRuleUnusedDefinition, LintInfo, "no $ref points to this definition")
+	}
+}
@@ -0,0 +1,126 @@
+package gojsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	base := writeSchemaFiles(t, map[string]string{
+		"root.json": `{
+			"definitions": {
+				"age": {"type": "integer", "minimum": 10, "maximum": 5, "pattern": "^[0-9]+$"},
+				"name": {"type": "string", "minLength": 3, "maxLength": 3},
+				"unused": {"type": "null"},
+				"remote": {"type": "boolean"}
+			},
+			"type": "object",
+			"properties": {
+				"age": {"$ref": "#/definitions/age"},
+				"name": {"$ref": "#/definitions/name", "default": "Bob"},
+				"tags": {"type": "array", "items": {"type": "string", "exclusiveMinimum": 1}, "examples": [["a"], [1]]},
+				"size": {"minimum": 3, "exclusiveMaximum": 3},
+				"other": {"$ref": "other.json"}
+			},
+			"requried": ["name"],
+			"required": ["age", "id"],
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": false,
+			"titel": "Person",
+			"x-vendor": true
+		}`,
+		"other.json": `{"not": {"$ref": "root.json#/definitions/remote"}}`,
+	})
+
+	findings, err := Lint(NewReferenceLoader(base + "root.json"))
+	assert.Nil(t, err)
+
+	type finding struct {
+		Pointer  string
+		Rule     LintRule
+		Severity LintSeverity
+	}
+	var actual []finding
+	for _, f := range findings {
+		actual = append(actual, finding{f.Pointer, f.Rule, f.Severity})
+	}
+	assert.Equal(t, []finding{
+		{"/definitions/age/minimum", LintRuleBounds, LintError},
+		{"/definitions/age/pattern", LintRuleTypeMismatch, LintWarning},
+		{"/definitions/unused", LintRuleUnusedDefinition, LintInfo},
+		{"/properties/size/minimum", LintRuleBounds, LintError},
+		{"/properties/tags/examples/1", LintRuleInvalidDefault, LintWarning},
+		{"/properties/tags/items/exclusiveMinimum", LintRuleTypeMismatch, LintWarning},
+		{"/required/1", LintRuleUndeclaredRequired, LintError},
+		{"/requried", LintRuleUnknownKeyword, LintWarning},
+		{"/titel", LintRuleUnknownKeyword, LintWarning},
+	}, actual)
+
+	messages := make(map[string]string)
+	for _, f := range findings {
+		messages[f.Pointer] = f.Message
+	}
+	assert.Equal(t, "minimum 10 is above maximum 5, no value is valid", messages["/definitions/age/minimum"])
+	assert.Equal(t, "requried is not a keyword, did you mean required?", messages["/requried"])
+	assert.Equal(t, "example 1 doesn't validate against its schema: 0: Invalid type. Expected: string, given: integer", messages["/properties/tags/examples/1"])
+}
+
+func TestLintDefault(t *testing.T) {
+	findings, err := Lint(NewStringLoader(`{"type": "object", "properties": {"n": {"type": "integer", "default": "1"}}, "default": {"n": 1}}`))
+	assert.Nil(t, err)
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, "/properties/n/default", findings[0].Pointer)
+		assert.Equal(t, LintRuleInvalidDefault, findings[0].Rule)
+		assert.Equal(t, "the default doesn't validate against its schema: (root): Invalid type. Expected: integer, given: string", findings[0].Message)
+	}
+}
+
+func TestLintDefinitionNames(t *testing.T) {
+	findings, err := Lint(NewStringLoader(`{
+		"definitions": {"default": {"$ref": "#/definitions/used"}, "used": {"type": "string"}},
+		"properties": {"a": {"$ref": "#/definitions/default"}}
+	}`))
+	assert.Nil(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestLintDraftKeywords(t *testing.T) {
+	cases := []struct {
+		schema   string
+		pointers []string
+	}{
+		{`{"$schema": "http://json-schema.org/draft-04/schema#", "$id": "a", "const": 1, "properties": {"a": {"if": {}, "examples": []}}}`,
+			[]string{"/$id", "/const", "/properties/a/examples", "/properties/a/if"}},
+		{`{"$schema": "http://json-schema.org/draft-06/schema#", "id": "a", "contains": {}, "if": {}, "$comment": ""}`,
+			[]string{"/$comment", "/id", "/if"}},
+		{`{"$schema": "http://json-schema.org/draft-07/schema#", "$id": "http://example.com/a", "if": {}, "$comment": ""}`,
+			nil},
+		{`{"id": "a", "$id": "b", "const": 1, "if": {}}`, nil},
+	}
+
+	for _, c := range cases {
+		findings, err := Lint(NewStringLoader(c.schema))
+		assert.Nil(t, err)
+		var pointers []string
+		for _, f := range findings {
+			assert.Equal(t, LintRuleDraftKeyword, f.Rule)
+			pointers = append(pointers, f.Pointer)
+		}
+		assert.Equal(t, c.pointers, pointers, c.schema)
+	}
+}
+
+func TestSimilarKeyword(t *testing.T) {
+	for k, expected := range map[string]string{
+		"requried":       "required",
+		"tpye":           "type",
+		"Pattern":        "pattern",
+		"minimun":        "minimum",
+		"additionalProp": "",
+		"x-foo":          "",
+		"ip":             "",
+	} {
+		assert.Equal(t, expected, similarKeyword(k), k)
+	}
+}
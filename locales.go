@@ -166,6 +166,9 @@ type (
 		// ReferenceMustBeCanonical returns a format-string to format a "reference must be canonical" error
 		ReferenceMustBeCanonical() string
 
+		// ReferenceCycle returns a format-string to format an error where a $ref recurses into itself infinitely
+		ReferenceCycle() string
+
 		// NotAValidType returns a format-string to format an invalid type error
 		NotAValidType() string
 
@@ -175,15 +178,27 @@ type (
 		// HttpBadStatus returns a format-string for errors when loading a schema using HTTP
 		HttpBadStatus() string
 
+		// HttpResponseTooLarge returns a format-string for a ResponseTooLargeError
+		HttpResponseTooLarge() string
+
+		// ReferenceNotPermitted returns a format-string for a ReferencePolicyError
+		ReferenceNotPermitted() string
+
 		// ParseError returns a format-string for JSON parsing errors
 		ParseError() string
 
+		// LimitExceeded returns a format-string for a LimitError
+		LimitExceeded() string
+
 		// ConditionThen returns a format-string for ConditionThenError errors
 		ConditionThen() string
 
 		// ConditionElse returns a format-string for ConditionElseError errors
 		ConditionElse() string
 
+		// MaxDepth returns a format-string for MaxDepthError errors
+		MaxDepth() string
+
 		// ErrorFormat returns a format string for errors
 		ErrorFormat() string
 	}
@@ -419,6 +434,11 @@ func (l DefaultLocale) ReferenceMustBeCanonical() string {
 	return `Reference {{.reference}} must be canonical`
 }
 
+// ReferenceCycle returns a format-string to format an error where a $ref recurses into itself infinitely
+func (l DefaultLocale) ReferenceCycle() string {
+	return `Reference {{.reference}} recurses into itself infinitely, through {{.cycle}}`
+}
+
 // NotAValidType returns a format-string to format an invalid type error
 func (l DefaultLocale) NotAValidType() string {
 	return `has a primitive type that is NOT VALID -- given: {{.given}} Expected valid values are:{{.expected}}`
@@ -440,11 +460,26 @@ func (l DefaultLocale) ErrorFormat() string {
 	return `{{.field}}: {{.description}}`
 }
 
+// HttpResponseTooLarge returns a format-string for a ResponseTooLargeError
+func (l DefaultLocale) HttpResponseTooLarge() string {
+	return `Could not read schema from HTTP, the response of {{.url}} exceeds {{.max}} bytes`
+}
+
+// ReferenceNotPermitted returns a format-string for a ReferencePolicyError
+func (l DefaultLocale) ReferenceNotPermitted() string {
+	return `Loading the reference {{.reference}} is not permitted, {{.violation}}`
+}
+
 // ParseError returns a format-string for JSON parsing errors
 func (l DefaultLocale) ParseError() string {
 	return `Expected: {{.expected}}, given: Invalid JSON`
 }
 
+// LimitExceeded returns a format-string for a LimitError
+func (l DefaultLocale) LimitExceeded() string {
+	return `{{.context}} exceeds {{.limit}} of {{.max}}`
+}
+
 // ConditionThen returns a format-string for ConditionThenError errors
 // If/Else
 func (l DefaultLocale) ConditionThen() string {
@@ -456,6 +491,11 @@ func (l DefaultLocale) ConditionElse() string {
 	return `Must validate "else" as "if" was not valid`
 }
 
+// MaxDepth returns a format-string for MaxDepthError errors
+func (l DefaultLocale) MaxDepth() string {
+	return `Exceeds the maximum validation depth of {{.max}}`
+}
+
 // constants
 const (
 	STRING_NUMBER                     = "number"
@@ -0,0 +1,346 @@
+package gojsonschema
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MigrateOptions configures SchemaLoader.Migrate
+type MigrateOptions struct {
+	// Definitions renames the definitions keywords, e.g. to "$defs" for consumers of newer drafts,
+	// and rewrites the $refs of the document that point into them. Draft 7 and older ignore "$defs",
+	// which still works as $refs are resolved by JSON Pointer. Empty keeps "definitions"
+	Definitions string
+}
+
+// MigrationIssue is a construct of a schema that Migrate couldn't migrate without changing what it validates
+type MigrationIssue struct {
+	// Pointer is the JSON Pointer of the construct in the original document
+	Pointer string
+	Message string
+}
+
+func (i MigrationIssue) String() string {
+	return i.Pointer + ": " + i.Message
+}
+
+// Migrate rewrites the schema document root for another draft, see SchemaLoader.Migrate
+func Migrate(root JSONLoader, to Draft, options MigrateOptions) (interface{}, []MigrationIssue, error) {
+	return NewSchemaLoader().Migrate(root, to, options)
+}
+
+// Migrate rewrites the schema document root from its draft, detected from its $schema or SchemaLoader.Draft,
+// for the draft to, which is Draft4, Draft6 or Draft7:
+//
+//   - id becomes $id, or the other way around
+//   - draft 4 boolean exclusiveMinimum and exclusiveMaximum become the numeric bounds of draft 6, or the other way around
+//   - a single value enum becomes a const, or the other way around
+//   - draft 4 has no boolean schemas, true becomes {} and false {"not": {}}
+//   - $schema is set to the meta-schema of to
+//
+// items as an array, which is prefixItems from draft 2020-12 on, is kept as it is valid in every draft to,
+// and reported as it has to be renamed for newer drafts.
+// Keywords that one of both drafts doesn't have are kept, and reported as they change what the schema validates.
+// Only root is migrated, the documents it references are not. The migrated schema is compiled, with the LoaderFactory
+// and Policy of the SchemaLoader, and validated against the meta-schema of to. An error is returned if it isn't valid
+func (sl *SchemaLoader) Migrate(root JSONLoader, to Draft, options MigrateOptions) (interface{}, []MigrationIssue, error) {
+	if to != Draft4 && to != Draft6 && to != Draft7 {
+		return nil, nil, fmt.Errorf("can't migrate to draft %d, the drafts to migrate to are 4, 6 and 7", to)
+	}
+	document, err := root.LoadJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+	_, from, err := parseSchemaURL(document)
+	if err != nil {
+		return nil, nil, err
+	}
+	if from == nil || !sl.AutoDetect {
+		from = &sl.Draft
+	}
+
+	m := &migrator{
+		from:    *from,
+		to:      to,
+		options: options,
+		renamed: make(map[string]string),
+	}
+	migrated := m.schema(copyDocument(document), "")
+	if rootMap, ok := migrated.(map[string]interface{}); ok {
+		rootMap[KEY_SCHEMA] = drafts.GetSchemaURL(to) + "#"
+	}
+	m.rewriteReferences()
+
+	if err := sl.verifyMigration(root, migrated, to); err != nil {
+		return nil, nil, err
+	}
+	return migrated, m.issues, nil
+}
+
+// verifyMigration compiles the migrated document of root as draft to, validating it against the meta-schema
+func (sl *SchemaLoader) verifyMigration(root JSONLoader, migrated interface{}, to Draft) error {
+	verifier := NewSchemaLoader()
+	verifier.Draft = to
+	verifier.Validate = true
+	verifier.LoaderFactory = sl.LoaderFactory
+	verifier.Policy = sl.Policy
+
+	ref, err := root.JsonReference()
+	if err != nil {
+		return err
+	}
+	if ref.String() == "" {
+		_, err = verifier.Compile(NewGoLoader(migrated))
+	} else if err = verifier.AddSchema(ref.String(), NewGoLoader(migrated)); err == nil {
+		_, err = verifier.Compile(root)
+	}
+	if err != nil {
+		return fmt.Errorf("the migrated schema is not a valid draft %d schema: %s", to, err.Error())
+	}
+	return nil
+}
+
+type migrator struct {
+	from    Draft
+	to      Draft
+	options MigrateOptions
+	issues  []MigrationIssue
+
+	// renamed maps the pointers of the renamed definitions keywords to their new name
+	renamed map[string]string
+	// resource is the pointer of the schema with the $id in scope, the root if there is none
+	resource string
+	refs     []migratedRef
+}
+
+// migratedRef is the schema at pointer with a $ref, that is relative to the schema at resource
+type migratedRef struct {
+	schema   map[string]interface{}
+	pointer  string
+	resource string
+}
+
+func (m *migrator) report(pointer string, format string, args ...interface{}) {
+	m.issues = append(m.issues, MigrationIssue{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+// schema migrates the schema at pointer in place, and returns it, or the schema replacing it
+func (m *migrator) schema(node interface{}, pointer string) interface{} {
+	if b, ok := node.(bool); ok && m.to == Draft4 {
+		if b {
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{KEY_NOT: map[string]interface{}{}}
+	}
+	v, ok := node.(map[string]interface{})
+	if !ok {
+		return node
+	}
+
+	m.keywords(v, pointer)
+
+	resource := m.resource
+	defer func() { m.resource = resource }()
+	m.migrateID(v, pointer)
+	if _, ok := v[KEY_REF].(string); ok {
+		m.refs = append(m.refs, migratedRef{schema: v, pointer: pointer, resource: m.resource})
+	}
+	m.migrateBound(v, pointer, KEY_MINIMUM, KEY_EXCLUSIVE_MINIMUM, true)
+	m.migrateBound(v, pointer, KEY_MAXIMUM, KEY_EXCLUSIVE_MAXIMUM, false)
+	m.migrateConst(v, pointer)
+
+	definitions := KEY_DEFINITIONS
+	if m.options.Definitions != "" && m.options.Definitions != KEY_DEFINITIONS && existsMapKey(v, KEY_DEFINITIONS) {
+		if existsMapKey(v, m.options.Definitions) {
+			m.report(pointer, "definitions can't be renamed to %s, the schema has both", m.options.Definitions)
+		} else {
+			definitions = m.options.Definitions
+			v[definitions] = v[KEY_DEFINITIONS]
+			delete(v, KEY_DEFINITIONS)
+			m.renamed[pointer+"/"+KEY_DEFINITIONS] = definitions
+		}
+	}
+
+	for _, k := range []string{definitions, KEY_PROPERTIES, KEY_PATTERN_PROPERTIES, KEY_DEPENDENCIES} {
+		schemas, ok := v[k].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		keyword := k
+		if k == definitions {
+			keyword = KEY_DEFINITIONS
+		}
+		for _, name := range sortedKeys(schemas) {
+			schemas[name] = m.schema(schemas[name], pointer+"/"+keyword+"/"+escapePointerToken(name))
+		}
+	}
+	for _, k := range []string{KEY_ITEMS, KEY_ALL_OF, KEY_ANY_OF, KEY_ONE_OF} {
+		if list, ok := v[k].([]interface{}); ok {
+			if k == KEY_ITEMS {
+				m.report(pointer+"/"+k, "items as an array is prefixItems from draft 2020-12 on, which can't be migrated to, it is kept as items")
+			}
+			for i, item := range list {
+				list[i] = m.schema(item, pointer+"/"+k+"/"+strconv.Itoa(i))
+			}
+		} else if existsMapKey(v, k) {
+			v[k] = m.schema(v[k], pointer+"/"+k)
+		}
+	}
+	for _, k := range []string{KEY_ADDITIONAL_ITEMS, KEY_ADDITIONAL_PROPERTIES} {
+		// booleans are valid here in every draft
+		if _, ok := v[k].(bool); !ok && existsMapKey(v, k) {
+			v[k] = m.schema(v[k], pointer+"/"+k)
+		}
+	}
+	for _, k := range []string{KEY_PROPERTY_NAMES, KEY_CONTAINS, KEY_NOT, KEY_IF, KEY_THEN, KEY_ELSE} {
+		if existsMapKey(v, k) {
+			v[k] = m.schema(v[k], pointer+"/"+k)
+		}
+	}
+	return v
+}
+
+// keywords reports the keywords of v that only one of the drafts has, and that aren't migrated
+func (m *migrator) keywords(v map[string]interface{}, pointer string) {
+	keywords := draftKeywords()
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		switch k {
+		case KEY_ID, KEY_ID_NEW, KEY_EXCLUSIVE_MINIMUM, KEY_EXCLUSIVE_MAXIMUM:
+			continue
+		case KEY_CONST:
+			if m.to == Draft4 {
+				continue
+			}
+		}
+		switch {
+		case !keywords[Hybrid][k]:
+		case !keywords[m.to][k]:
+			m.report(pointer+"/"+escapePointerToken(k), "%s is not a keyword of draft %d, it is ignored after the migration", k, m.to)
+		case m.from != Hybrid && !keywords[m.from][k]:
+			m.report(pointer+"/"+escapePointerToken(k), "%s is not a keyword of draft %d, it is ignored before the migration but not after it", k, m.from)
+		}
+	}
+}
+
+// migrateID renames the id keyword of v, and scopes the references of its schemas to it
+func (m *migrator) migrateID(v map[string]interface{}, pointer string) {
+	oldID, newID := KEY_ID, KEY_ID_NEW
+	if m.to == Draft4 {
+		oldID, newID = KEY_ID_NEW, KEY_ID
+	}
+
+	// "id" is the id keyword of draft 4 only
+	isID := m.from == Hybrid || (oldID == KEY_ID) == (m.from == Draft4)
+	if existsMapKey(v, oldID) && isID {
+		if existsMapKey(v, newID) && !reflect.DeepEqual(v[newID], v[oldID]) {
+			m.report(pointer+"/"+escapePointerToken(oldID), "%s can't be renamed to %s, the schema has both", oldID, newID)
+		} else {
+			v[newID] = v[oldID]
+			delete(v, oldID)
+		}
+	}
+
+	if id, ok := v[newID].(string); ok && pointer != "" && !strings.HasPrefix(id, "#") {
+		m.resource = pointer
+	}
+}
+
+// migrateBound converts the exclusive bound of v between the booleans of draft 4 and the numbers of draft 6
+func (m *migrator) migrateBound(v map[string]interface{}, pointer string, keyword string, exclusiveKeyword string, lower bool) {
+	switch exclusive := v[exclusiveKeyword].(type) {
+	case bool:
+		if m.to == Draft4 {
+			return
+		}
+		if exclusive && existsMapKey(v, keyword) {
+			v[exclusiveKeyword] = v[keyword]
+			delete(v, keyword)
+		} else {
+			delete(v, exclusiveKeyword)
+		}
+
+	default:
+		if m.to != Draft4 || !isJSONNumber(exclusive) {
+			return
+		}
+		if existsMapKey(v, keyword) {
+			bound, exclusiveBound := mustBeNumber(v[keyword]), mustBeNumber(exclusive)
+			if bound == nil || exclusiveBound == nil {
+				return
+			}
+			// only the tighter of both is kept
+			if c := exclusiveBound.Cmp(bound); c != 0 && (c > 0) != lower {
+				delete(v, exclusiveKeyword)
+				return
+			}
+		}
+		v[keyword] = exclusive
+		v[exclusiveKeyword] = true
+	}
+}
+
+// migrateConst converts between a const and a single value enum
+func (m *migrator) migrateConst(v map[string]interface{}, pointer string) {
+	if m.to == Draft4 {
+		if !existsMapKey(v, KEY_CONST) {
+			return
+		}
+		if enum, ok := v[KEY_ENUM].([]interface{}); ok && !containsValue(enum, v[KEY_CONST]) {
+			m.report(pointer+"/"+KEY_CONST, "const is not one of the values of enum, so no value is valid, it can't be migrated to an enum")
+			return
+		}
+		v[KEY_ENUM] = []interface{}{v[KEY_CONST]}
+		delete(v, KEY_CONST)
+		return
+	}
+
+	if enum, ok := v[KEY_ENUM].([]interface{}); ok && len(enum) == 1 && !existsMapKey(v, KEY_CONST) {
+		v[KEY_CONST] = enum[0]
+		delete(v, KEY_ENUM)
+	}
+}
+
+// rewriteReferences rewrites the $refs pointing into renamed definitions
+func (m *migrator) rewriteReferences() {
+	if len(m.renamed) == 0 {
+		return
+	}
+
+	for _, r := range m.refs {
+		ref := r.schema[KEY_REF].(string)
+		base, fragment := splitReference(ref)
+		if !strings.HasPrefix(fragment, "/") {
+			continue
+		}
+		if base != "" {
+			for _, token := range strings.Split(fragment, "/") {
+				if token == KEY_DEFINITIONS {
+					m.report(r.pointer+"/"+escapePointerToken(KEY_REF), "the $ref %s points into another document, which has to be migrated with the same Definitions", ref)
+					break
+				}
+			}
+			continue
+		}
+
+		tokens := strings.Split(fragment, "/")[1:]
+		location, renamed := r.resource, r.resource
+		for _, token := range tokens {
+			location += "/" + token
+			if name, ok := m.renamed[location]; ok {
+				token = escapePointerToken(name)
+			}
+			renamed += "/" + token
+		}
+		r.schema[KEY_REF] = "#" + (&url.URL{Fragment: renamed[len(r.resource):]}).EscapedFragment()
+	}
+}
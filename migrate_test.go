@@ -0,0 +1,159 @@
+package gojsonschema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateUp(t *testing.T) {
+	original := `{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"id": "http://example.com/person.json",
+		"type": "object",
+		"definitions": {
+			"age": {"type": "integer", "minimum": 0, "exclusiveMinimum": true, "maximum": 150, "exclusiveMaximum": false},
+			"item": {"id": "item.json", "definitions": {"name": {"type": "string"}}, "properties": {"name": {"$ref": "#/definitions/name"}}}
+		},
+		"properties": {
+			"age": {"$ref": "#/definitions/age"},
+			"kind": {"enum": ["person"]},
+			"items": {"type": "array", "items": [{"$ref": "#/definitions/item"}]},
+			"id": {"type": "string"},
+			"legacy": {"const": "x"}
+		}
+	}`
+
+	migrated, issues, err := Migrate(NewStringLoader(original), Draft7, MigrateOptions{Definitions: "$defs"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	expected, err := decodeJSONUsingNumber(strings.NewReader(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"$id": "http://example.com/person.json",
+		"type": "object",
+		"$defs": {
+			"age": {"type": "integer", "exclusiveMinimum": 0, "maximum": 150},
+			"item": {"$id": "item.json", "$defs": {"name": {"type": "string"}}, "properties": {"name": {"$ref": "#/$defs/name"}}}
+		},
+		"properties": {
+			"age": {"$ref": "#/$defs/age"},
+			"kind": {"const": "person"},
+			"items": {"type": "array", "items": [{"$ref": "#/$defs/item"}]},
+			"id": {"type": "string"},
+			"legacy": {"const": "x"}
+		}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, migrated)
+	assert.Equal(t, []MigrationIssue{
+		{Pointer: "/properties/items/items", Message: "items as an array is prefixItems from draft 2020-12 on, which can't be migrated to, it is kept as items"},
+		{Pointer: "/properties/legacy/const", Message: "const is not a keyword of draft 4, it is ignored before the migration but not after it"},
+	}, issues)
+
+	before, err := NewSchema(NewStringLoader(original))
+	assert.Nil(t, err)
+	after, err := NewSchema(NewGoLoader(migrated))
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, d := range []string{
+		`{"age": 0, "kind": "person", "items": [{"name": "a"}], "id": "1"}`,
+		`{"age": 150.5, "kind": "animal", "items": [{"name": 1}], "id": 1}`,
+		`{"age": 1}`,
+	} {
+		expected, err := before.Validate(NewStringLoader(d))
+		assert.Nil(t, err)
+		actual, err := after.Validate(NewStringLoader(d))
+		assert.Nil(t, err)
+		// a single value enum fails as const after the migration, so only the fields are compared
+		var expectedFields, actualFields []string
+		for _, e := range expected.Errors() {
+			expectedFields = append(expectedFields, e.Field())
+		}
+		for _, e := range actual.Errors() {
+			actualFields = append(actualFields, e.Field())
+		}
+		assert.ElementsMatch(t, expectedFields, actualFields, d)
+	}
+}
+
+func TestMigrateDown(t *testing.T) {
+	migrated, issues, err := Migrate(NewStringLoader(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"$id": "http://example.com/a.json",
+		"properties": {
+			"a": {"exclusiveMinimum": 1, "minimum": 0},
+			"b": {"exclusiveMaximum": 5, "maximum": 4},
+			"c": {"const": 1},
+			"d": false,
+			"e": {"if": {"type": "string"}, "then": {"minLength": 1}}
+		},
+		"additionalProperties": false
+	}`), Draft4, MigrateOptions{})
+	if !assert.Nil(t, err) {
+		return
+	}
+	expected, err := decodeJSONUsingNumber(strings.NewReader(`{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"id": "http://example.com/a.json",
+		"properties": {
+			"a": {"minimum": 1, "exclusiveMinimum": true},
+			"b": {"maximum": 4},
+			"c": {"enum": [1]},
+			"d": {"not": {}},
+			"e": {"if": {"type": "string"}, "then": {"minLength": 1}}
+		},
+		"additionalProperties": false
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, migrated)
+	assert.Equal(t, []MigrationIssue{
+		{Pointer: "/properties/e/if", Message: "if is not a keyword of draft 4, it is ignored after the migration"},
+		{Pointer: "/properties/e/then", Message: "then is not a keyword of draft 4, it is ignored after the migration"},
+	}, issues)
+}
+
+func TestMigrateReferencedDocument(t *testing.T) {
+	base := writeSchemaFiles(t, map[string]string{
+		"root.json":  `{"definitions": {"local": {"type": "integer"}}, "properties": {"a": {"$ref": "#/definitions/local"}, "b": {"$ref": "other.json#/definitions/remote"}}}`,
+		"other.json": `{"definitions": {"remote": {"type": "string"}}}`,
+	})
+
+	sl := NewSchemaLoader()
+	sl.Draft = Draft4
+	migrated, issues, err := sl.Migrate(NewReferenceLoader(base+"root.json"), Draft6, MigrateOptions{Definitions: "$defs"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	properties := migrated.(map[string]interface{})[KEY_PROPERTIES].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{KEY_REF: "#/$defs/local"}, properties["a"])
+	assert.Equal(t, map[string]interface{}{KEY_REF: "other.json#/definitions/remote"}, properties["b"])
+	assert.Equal(t, []MigrationIssue{
+		{Pointer: "/properties/b/$ref", Message: "the $ref other.json#/definitions/remote points into another document, which has to be migrated with the same Definitions"},
+	}, issues)
+}
+
+func TestMigrateErrors(t *testing.T) {
+	_, _, err := Migrate(NewStringLoader(`{}`), Hybrid, MigrateOptions{})
+	assert.NotNil(t, err)
+
+	// ids that are not strings are not valid, but must not make Migrate panic
+	for _, document := range []string{
+		`{"properties": {"a": {"id": [1], "$id": [1]}}}`,
+		`{"id": {}, "$id": {}}`,
+		`{"id": {"a": 1}, "$id": [2]}`,
+	} {
+		assert.NotPanics(t, func() {
+			_, _, err = Migrate(NewStringLoader(document), Draft7, MigrateOptions{})
+		})
+		assert.NotNil(t, err, document)
+	}
+
+	// draft 4 requires at least one required property
+	_, _, err = Migrate(NewStringLoader(`{"$schema": "http://json-schema.org/draft-07/schema#", "required": []}`), Draft4, MigrateOptions{})
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "the migrated schema is not a valid draft 4 schema: "), err.Error())
+	}
+}
@@ -0,0 +1,351 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xeipuuv/gojsonreference"
+)
+
+// MessagePack loader
+
+type messagePackLoader struct {
+	source []byte
+}
+
+func (l *messagePackLoader) JsonSource() interface{} {
+	return l.source
+}
+
+func (l *messagePackLoader) JsonReference() (gojsonreference.JsonReference, error) {
+	return gojsonreference.NewJsonReference("#")
+}
+
+func (l *messagePackLoader) LoaderFactory() JSONLoaderFactory {
+	return &DefaultJSONLoaderFactory{}
+}
+
+// NewMessagePackLoader creates a new JSONLoader, taking a single MessagePack object as source.
+// Values are decoded to those of the equivalent JSON:
+//
+//	integers and floats are exact json.Number
+//	binary data is a base64 string, see contentEncoding
+//	timestamps (extension type -1) are RFC 3339 date-time strings in UTC
+//	maps must have string keys
+//
+// Other extension types and floats that aren't finite have no JSON equivalent and are a *MessagePackError.
+// Referenced files ending in .msgpack or .mpk, or served as application/msgpack, are decoded as MessagePack as well
+func NewMessagePackLoader(source []byte) JSONLoader {
+	return &messagePackLoader{source: source}
+}
+
+func (l *messagePackLoader) LoadJSON() (interface{}, error) {
+	return decodeMessagePack(bytes.NewReader(l.source))
+}
+
+func (l *messagePackLoader) openJSON(ctx context.Context) (io.ReadCloser, error) {
+	return &documentReader{ReadCloser: ioutil.NopCloser(bytes.NewReader(l.source)), decode: decodeMessagePack}, nil
+}
+
+// isMessagePackPath reports whether the file name or URL path name has a MessagePack extension
+func isMessagePackPath(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".msgpack", ".mpk":
+		return true
+	}
+	return false
+}
+
+// isMessagePackMediaType reports whether the Content-Type contentType is MessagePack
+func isMessagePackMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/msgpack" || mediaType == "application/x-msgpack" || mediaType == "application/vnd.msgpack")
+}
+
+// MessagePackError is returned for MessagePack data that can't be decoded, or that has no JSON equivalent
+type MessagePackError struct {
+	Offset  int
+	Message string
+}
+
+func (e *MessagePackError) Error() string {
+	return fmt.Sprintf("msgpack: offset %d: %s", e.Offset, e.Message)
+}
+
+// decodeMessagePack decodes a MessagePack object like decodeJSONUsingNumber decodes JSON
+func decodeMessagePack(r io.Reader) (interface{}, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &messagePackDecoder{src: b}
+	value, err := d.decodeObject()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.src) {
+		return nil, d.errorf(d.pos, "unexpected data after the object")
+	}
+	return value, nil
+}
+
+type messagePackDecoder struct {
+	src   []byte
+	pos   int
+	depth int
+}
+
+func (d *messagePackDecoder) errorf(offset int, format string, args ...interface{}) error {
+	return &MessagePackError{Offset: offset, Message: fmt.Sprintf(format, args...)}
+}
+
+// read returns the next n bytes
+func (d *messagePackDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.src)-d.pos) {
+		return nil, d.errorf(d.pos, "unexpected end of data")
+	}
+	b := d.src[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// readUint reads a big endian unsigned integer of size bytes
+func (d *messagePackDecoder) readUint(size int) (uint64, error) {
+	b, err := d.read(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// messagePackSizes are the sizes of the length, or of the value, that follow a format byte
+var messagePackSizes = map[byte]int{
+	0xc4: 1, 0xc5: 2, 0xc6: 4, // bin
+	0xc7: 1, 0xc8: 2, 0xc9: 4, // ext
+	0xcc: 1, 0xcd: 2, 0xce: 4, 0xcf: 8, // uint
+	0xd0: 1, 0xd1: 2, 0xd2: 4, 0xd3: 8, // int
+	0xd9: 1, 0xda: 2, 0xdb: 4, // str
+	0xdc: 2, 0xdd: 4, // array
+	0xde: 2, 0xdf: 4, // map
+}
+
+func (d *messagePackDecoder) decodeObject() (interface{}, error) {
+	start := d.pos
+	if d.pos >= len(d.src) {
+		return nil, d.errorf(d.pos, "unexpected end of data")
+	}
+	format := d.src[d.pos]
+	d.pos++
+
+	switch {
+	case format <= 0x7f:
+		return json.Number(strconv.Itoa(int(format))), nil
+	case format >= 0xe0:
+		return json.Number(strconv.Itoa(int(int8(format)))), nil
+	case format <= 0x8f:
+		return d.decodeMap(start, uint64(format&0x0f))
+	case format <= 0x9f:
+		return d.decodeArray(start, uint64(format&0x0f))
+	case format <= 0xbf:
+		return d.decodeString(start, uint64(format&0x1f))
+	}
+
+	switch format {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xca:
+		bits, err := d.readUint(4)
+		if err != nil {
+			return nil, err
+		}
+		return d.float(start, float64(math.Float32frombits(uint32(bits))), 32)
+	case 0xcb:
+		bits, err := d.readUint(8)
+		if err != nil {
+			return nil, err
+		}
+		return d.float(start, math.Float64frombits(bits), 64)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		// fixext 1, 2, 4, 8 and 16
+		return d.decodeExtension(start, 1<<(format-0xd4))
+	}
+
+	size, ok := messagePackSizes[format]
+	if !ok {
+		return nil, d.errorf(start, "invalid format byte 0x%02x", format)
+	}
+	n, err := d.readUint(size)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case format <= 0xc6:
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case format <= 0xc9:
+		return d.decodeExtension(start, n)
+	case format <= 0xcf:
+		return json.Number(strconv.FormatUint(n, 10)), nil
+	case format <= 0xd3:
+		// sign extend
+		shift := 64 - 8*size
+		return json.Number(strconv.FormatInt(int64(n<<shift)>>shift, 10)), nil
+	case format <= 0xdb:
+		return d.decodeString(start, n)
+	case format <= 0xdd:
+		return d.decodeArray(start, n)
+	}
+	return d.decodeMap(start, n)
+}
+
+func (d *messagePackDecoder) decodeString(start int, length uint64) (interface{}, error) {
+	b, err := d.read(length)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(b) {
+		return nil, d.errorf(start, "invalid UTF-8 in a string")
+	}
+	return string(b), nil
+}
+
+// enter counts an array or map being decoded, and rejects lengths longer than the rest of the data
+// before anything is allocated for them. Every item is at least a byte
+func (d *messagePackDecoder) enter(start int, items uint64) error {
+	if items > uint64(len(d.src)-d.pos) {
+		return d.errorf(start, "length %d is longer than the data", items)
+	}
+	d.depth++
+	if d.depth > binaryMaxDepth {
+		return d.errorf(start, "exceeded max depth of %d", binaryMaxDepth)
+	}
+	return nil
+}
+
+func (d *messagePackDecoder) decodeArray(start int, length uint64) (interface{}, error) {
+	if err := d.enter(start, length); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	array := make([]interface{}, 0, length)
+	for i := uint64(0); i < length; i++ {
+		item, err := d.decodeObject()
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, item)
+	}
+	return array, nil
+}
+
+func (d *messagePackDecoder) decodeMap(start int, length uint64) (interface{}, error) {
+	if err := d.enter(start, length*2); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	object := make(map[string]interface{}, length)
+	for i := uint64(0); i < length; i++ {
+		keyStart := d.pos
+		if d.pos >= len(d.src) {
+			return nil, d.errorf(d.pos, "unexpected end of data")
+		}
+		if format := d.src[d.pos]; !(format >= 0xa0 && format <= 0xbf || format >= 0xd9 && format <= 0xdb) {
+			return nil, d.errorf(keyStart, "map keys must be strings")
+		}
+		key, err := d.decodeObject()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := object[key.(string)]; ok {
+			return nil, d.errorf(keyStart, "duplicate map key %q", key)
+		}
+		value, err := d.decodeObject()
+		if err != nil {
+			return nil, err
+		}
+		object[key.(string)] = value
+	}
+	return object, nil
+}
+
+// messagePackTimestamp is the extension type of timestamps
+const messagePackTimestamp = -1
+
+// decodeExtension decodes an extension type and its data of length bytes
+func (d *messagePackDecoder) decodeExtension(start int, length uint64) (interface{}, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	extension := int8(b[0])
+	data, err := d.read(length)
+	if err != nil {
+		return nil, err
+	}
+	if extension != messagePackTimestamp {
+		return nil, d.errorf(start, "extension type %d has no JSON equivalent", extension)
+	}
+
+	var seconds int64
+	var nanoseconds uint32
+	switch len(data) {
+	case 4:
+		seconds = int64(binary.BigEndian.Uint32(data))
+	case 8:
+		n := binary.BigEndian.Uint64(data)
+		seconds, nanoseconds = int64(n&(1<<34-1)), uint32(n>>34)
+	case 12:
+		nanoseconds, seconds = binary.BigEndian.Uint32(data), int64(binary.BigEndian.Uint64(data[4:]))
+	default:
+		return nil, d.errorf(start, "invalid timestamp of %d bytes", len(data))
+	}
+	if nanoseconds >= 1e9 {
+		return nil, d.errorf(start, "invalid timestamp nanoseconds %d", nanoseconds)
+	}
+	t := time.Unix(seconds, int64(nanoseconds)).UTC()
+	if t.Year() < 0 || t.Year() > 9999 {
+		return nil, d.errorf(start, "timestamp %d is out of the range of RFC 3339", seconds)
+	}
+	return t.Format(time.RFC3339Nano), nil
+}
+
+// float returns a json.Number of f, written as encoding/json writes a float of the given bits
+func (d *messagePackDecoder) float(start int, f float64, bits int) (interface{}, error) {
+	n, err := binaryFloat(f, bits)
+	if err != nil {
+		return nil, d.errorf(start, "%s", err.Error())
+	}
+	return n, nil
+}
@@ -0,0 +1,171 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeMessagePack(t *testing.T) {
+	cases := []struct {
+		msgpack string
+		json    string
+	}{
+		{"00", `0`},
+		{"7f", `127`},
+		{"ff", `-1`},
+		{"e0", `-32`},
+		{"ccff", `255`},
+		{"cd0100", `256`},
+		{"ceffffffff", `4294967295`},
+		{"cfffffffffffffffff", `18446744073709551615`},
+		{"d080", `-128`},
+		{"d18000", `-32768`},
+		{"d280000000", `-2147483648`},
+		{"d38000000000000000", `-9223372036854775808`},
+		{"ca3fc00000", `1.5`},
+		{"ca3dcccccd", `0.1`},
+		{"cb3ff199999999999a", `1.1`},
+		{"c0", `null`},
+		{"c2", `false`},
+		{"c3", `true`},
+		{"a3616263", `"abc"`},
+		{"d903616263", `"abc"`},
+		{"da0003616263", `"abc"`},
+		{"c40401020304", `"AQIDBA=="`},
+		{"c6000000020102", `"AQI="`},
+		{"93010203", `[1, 2, 3]`},
+		{"dc00020102", `[1, 2]`},
+		{"82a16101a1629202 03", `{"a": 1, "b": [2, 3]}`},
+		{"de0001a161c0", `{"a": null}`},
+		{"90", `[]`},
+		{"80", `{}`},
+		{"d6ff00000000", `"1970-01-01T00:00:00Z"`},
+		{"d7ff7735940000000001", `"1970-01-01T00:00:01.5Z"`},
+		{"c70cff00000000ffffffffffffffff", `"1969-12-31T23:59:59Z"`},
+	}
+
+	for _, c := range cases {
+		expected, err := decodeJSONUsingNumber(strings.NewReader(c.json))
+		if !assert.Nil(t, err, c.json) {
+			continue
+		}
+		b, err := hex.DecodeString(strings.Replace(c.msgpack, " ", "", -1))
+		assert.Nil(t, err)
+		actual, err := decodeMessagePack(bytes.NewReader(b))
+		if assert.Nil(t, err, c.msgpack) {
+			assert.Equal(t, expected, actual, c.msgpack)
+		}
+	}
+}
+
+func TestDecodeMessagePackErrors(t *testing.T) {
+	cases := []struct {
+		msgpack string
+		message string
+	}{
+		{"", "offset 0: unexpected end of data"},
+		{"cd01", "offset 1: unexpected end of data"},
+		{"0000", "offset 1: unexpected data after the object"},
+		{"c1", "invalid format byte 0xc1"},
+		{"cb7ff8000000000000", "NaN has no JSON equivalent"},
+		{"ca7f800000", "+Inf has no JSON equivalent"},
+		{"d40100", "extension type 1 has no JSON equivalent"},
+		{"d5ff0000", "invalid timestamp of 2 bytes"},
+		{"d7ffee6b280000000000", "invalid timestamp nanoseconds 1000000000"},
+		{"c70cff000000007fffffffffffffff", "out of the range of RFC 3339"},
+		{"a2c328", "invalid UTF-8 in a string"},
+		{"8101c0", "offset 1: map keys must be strings"},
+		{"82a16101a16102", "offset 4: duplicate map key \"a\""},
+		{"ddffffffff", "length 4294967295 is longer than the data"},
+		{"82a3616263c0", "offset 6: unexpected end of data"},
+		{strings.Repeat("91", 10001) + "00", "exceeded max depth of 10000"},
+	}
+
+	for _, c := range cases {
+		b, err := hex.DecodeString(c.msgpack)
+		assert.Nil(t, err)
+		_, err = decodeMessagePack(bytes.NewReader(b))
+		var msgpackErr *MessagePackError
+		if assert.True(t, errors.As(err, &msgpackErr), "%s: expected a MessagePackError, got %v", c.msgpack, err) {
+			assert.Contains(t, msgpackErr.Error(), c.message, c.msgpack)
+		}
+	}
+}
+
+// FuzzMessagePack checks that decodeMessagePack either fails with a MessagePackError or returns a JSON document
+func FuzzMessagePack(f *testing.F) {
+	for _, seed := range []string{
+		"cfffffffffffffffff",
+		"d280000000",
+		"ca3dcccccd",
+		"cb3ff199999999999a",
+		"d6ff5a4ec3b0",
+		"c70cff000000007fffffffffffffff",
+		"c403010203",
+		"82a16101a162920203",
+	} {
+		b, _ := hex.DecodeString(seed)
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, source []byte) {
+		document, err := decodeMessagePack(bytes.NewReader(source))
+		if err != nil {
+			var msgpackErr *MessagePackError
+			if !errors.As(err, &msgpackErr) {
+				t.Fatalf("%x: expected a MessagePackError, got %v", source, err)
+			}
+			return
+		}
+		checkJSONDocument(t, source, document)
+	})
+}
+
+func TestMessagePackReferenceOverHTTP(t *testing.T) {
+	// {"type": "string", "format": "date-time"}
+	timestamp, _ := hex.DecodeString("82a474797065a6737472696e67a6666f726d6174a9646174652d74696d65")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/msgpack")
+		w.Write(timestamp)
+	}))
+	defer ts.Close()
+
+	schema, err := NewSchema(NewStringLoader(`{
+		"properties": {
+			"at": { "$ref": "` + ts.URL + `/timestamp" },
+			"data": { "type": "string", "maxLength": 8 }
+		}
+	}`))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// {"at": timestamp 0, "data": bin 01020304}
+	document, _ := hex.DecodeString("82a26174d6ff00000000a464617461c40401020304")
+	result, err := schema.Validate(NewMessagePackLoader(document))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid(), "%v", result.Errors())
+
+	// {"at": "yesterday", "data": bin 0102030405060708}
+	document, _ = hex.DecodeString("82a26174a9796573746572646179a464617461c4080102030405060708")
+	result, err = schema.Validate(NewMessagePackLoader(document))
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"format (root).at", "string_lte (root).data"}, errorFields(result))
+}
+
+func TestIsMessagePackMediaType(t *testing.T) {
+	assert.True(t, isMessagePackMediaType("application/msgpack"))
+	assert.True(t, isMessagePackMediaType("application/x-msgpack; charset=binary"))
+	assert.True(t, isMessagePackMediaType("application/vnd.msgpack"))
+	assert.False(t, isMessagePackMediaType("application/json"))
+	assert.True(t, isCBORMediaType("application/cbor"))
+	assert.True(t, isCBORMediaType("application/senml+cbor"))
+	assert.False(t, isCBORMediaType("application/cbor-seq"))
+}
@@ -0,0 +1,94 @@
+package gojsonschema
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultArrayWorkersMinItems is the default minimum number of items an array must have
+// to be validated by multiple goroutines, see SchemaLoader.ArrayWorkers
+const DefaultArrayWorkersMinItems = 1000
+
+// validateItemsParallel is validateItems spread over v.arrayWorkers goroutines.
+// Results are merged in index order, so they are identical to a sequential validation
+func (v *validator) validateItemsParallel(itemSchema *subSchema, value []interface{}, from int, result *Result, context *JsonContext) {
+	results := make([]*Result, len(value)-from)
+	depthErrors := make([]ResultError, len(value)-from)
+	next := int64(from - 1)
+
+	workers := make([]validator, v.arrayWorkers)
+	var wg sync.WaitGroup
+	for w := range workers {
+		// Every worker gets its own copy of the validation state.
+		// Nested arrays are validated sequentially to keep the number of goroutines bounded
+		workers[w] = *v
+		workers[w].arrayWorkers = 0
+
+		wg.Add(1)
+		go func(worker *validator) {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(value) {
+					return
+				}
+				subContext := NewJsonContext(strconv.Itoa(i), context)
+				results[i-from] = worker.subValidateWithContext(itemSchema, value[i], subContext)
+				if worker.depthError != nil && depthErrors[i-from] == nil {
+					depthErrors[i-from] = worker.depthError
+					return
+				}
+			}
+		}(&workers[w])
+	}
+	wg.Wait()
+
+	for w := range workers {
+		v.interrupted = v.interrupted || workers[w].interrupted
+	}
+
+	// A sequential validation stops at the first depth error, the items after it are ignored even if a worker validated them
+	for i := range results {
+		if results[i] != nil {
+			result.mergeErrors(results[i])
+		}
+		if depthErrors[i] != nil {
+			v.depthError = depthErrors[i]
+			return
+		}
+	}
+}
+
+// ValidateBatch validates the documents of loaders using at most workers goroutines,
+// or GOMAXPROCS goroutines if workers is not positive.
+// results[i] and errs[i] are what ValidateContext returns for loaders[i]
+func (v *Schema) ValidateBatch(ctx context.Context, loaders []JSONLoader, workers int) (results []*Result, errs []error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	results = make([]*Result, len(loaders))
+	errs = make([]error, len(loaders))
+	next := int64(-1)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(loaders); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(loaders) {
+					return
+				}
+				results[i], errs[i] = v.ValidateContext(ctx, loaders[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results, errs
+}
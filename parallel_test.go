@@ -0,0 +1,106 @@
+package gojsonschema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const parallelSchema = `{
+	"type" : "array",
+	"items" : {
+		"type" : "object",
+		"properties" : {
+			"id" : { "type" : "integer", "multipleOf" : 7 },
+			"values" : { "type" : "array", "items" : { "type" : "string" } }
+		},
+		"required" : ["id"]
+	}
+}`
+
+func parallelDocument(n int) string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"id" : %d, "values" : ["a", %d]}`, i, i)
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
+
+func TestValidateArrayParallel(t *testing.T) {
+	document := NewStringLoader(parallelDocument(2000))
+
+	// The same compiled schema is used for both, as the order of properties differs between compilations
+	schema, err := NewSchema(NewStringLoader(parallelSchema))
+	assert.Nil(t, err)
+	expected, err := schema.Validate(document)
+	assert.Nil(t, err)
+
+	schema.SetArrayWorkers(8, 100)
+	result, err := schema.Validate(document)
+	assert.Nil(t, err)
+
+	assert.Equal(t, len(expected.Errors()), len(result.Errors()))
+	for i := range expected.Errors() {
+		assert.Equal(t, expected.Errors()[i].String(), result.Errors()[i].String())
+	}
+	assert.Equal(t, expected.score, result.score)
+}
+
+func TestValidateArrayParallelMaxDepth(t *testing.T) {
+	sl := NewSchemaLoader()
+	sl.ArrayWorkers = 4
+	sl.ArrayWorkersMinItems = 2
+	sl.MaxDepth = 4
+	schema, err := sl.Compile(NewStringLoader(`{"items" : {"$ref" : "#"}}`))
+	assert.Nil(t, err)
+
+	result, err := schema.Validate(NewStringLoader(`[1, 2, [[[[1]]]], 3]`))
+	assert.Nil(t, err)
+	if assert.False(t, result.Valid()) {
+		assert.Equal(t, "max_depth", result.Errors()[len(result.Errors())-1].Type())
+	}
+
+	// The items after the one too deep are ignored, as they are by a sequential validation
+	schema, err = NewSchema(NewStringLoader(`{"type" : ["array", "integer"], "minimum" : 10, "items" : {"$ref" : "#"}}`))
+	assert.Nil(t, err)
+	schema.SetMaxDepth(4)
+	document := NewStringLoader("[1, [[[[1]]]]" + strings.Repeat(", 2", 200) + "]")
+
+	expected, err := schema.Validate(document)
+	assert.Nil(t, err)
+	schema.SetArrayWorkers(4, 2)
+	result, err = schema.Validate(document)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, len(expected.Errors()))
+	assert.Equal(t, len(expected.Errors()), len(result.Errors()))
+	for i := range expected.Errors() {
+		assert.Equal(t, expected.Errors()[i].String(), result.Errors()[i].String())
+	}
+	assert.Equal(t, expected.score, result.score)
+}
+
+func TestValidateBatch(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{"type" : "integer", "minimum" : 10}`))
+	assert.Nil(t, err)
+
+	loaders := make([]JSONLoader, 100)
+	for i := range loaders {
+		loaders[i] = NewStringLoader(fmt.Sprintf("%d", i))
+	}
+	loaders[50] = NewStringLoader(`{`)
+
+	results, errs := schema.ValidateBatch(context.Background(), loaders, 4)
+	assert.Len(t, results, len(loaders))
+	for i := range loaders {
+		if i == 50 {
+			assert.NotNil(t, errs[i])
+			continue
+		}
+		assert.Nil(t, errs[i])
+		assert.Equal(t, i >= 10, results[i].Valid(), "document %d", i)
+	}
+}
@@ -0,0 +1,239 @@
+package gojsonschema
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/xeipuuv/gojsonreference"
+)
+
+// ReferencePolicy restricts the references a SchemaLoader may load while compiling a schema,
+// for schemas from untrusted sources. The embedded meta-schemas of the drafts, and the schemas
+// already added with AddSchema, AddSchemas or loaded before, are always permitted.
+// A reference that isn't permitted makes compiling fail with a *ReferencePolicyError
+type ReferencePolicy struct {
+	// Offline permits no other references at all
+	Offline bool
+	// Allow lists the references that may be loaded. If it is empty, every reference that isn't denied may be
+	Allow []ReferenceRule
+	// Deny lists the references that may not be loaded, even if they are allowed
+	Deny []ReferenceRule
+	// FileRoot is the directory file references must be in, after resolving ".." and symbolic links.
+	// If it is empty, file references are permitted anywhere
+	FileRoot string
+}
+
+// ReferenceRule matches references by their scheme, host and path.
+// Empty fields match everything, e.g. ReferenceRule{Scheme: "https", Host: "schemas.example.com", Path: "/public/"}
+type ReferenceRule struct {
+	// Scheme is e.g. "https" or "file"
+	Scheme string
+	// Host is a host name, or with a port to match only that port.
+	// A leading "*." matches every subdomain, e.g. "*.example.com"
+	Host string
+	// Path is a path prefix, matching whole segments: "/schemas" matches "/schemas/a.json" but not "/schemas-private".
+	// ".." segments are resolved before matching
+	Path string
+}
+
+// PolicyViolation is the reason a ReferencePolicy doesn't permit a reference
+type PolicyViolation string
+
+const (
+	// ViolationOffline is reported for every reference that isn't permitted, under an offline policy
+	ViolationOffline PolicyViolation = "the policy is offline"
+	// ViolationDenied is reported for a reference that matches a Deny rule
+	ViolationDenied PolicyViolation = "it is denied"
+	// ViolationNotAllowed is reported for a reference that matches no Allow rule
+	ViolationNotAllowed PolicyViolation = "it is not allowed"
+	// ViolationOutsideFileRoot is reported for a file reference outside the FileRoot
+	ViolationOutsideFileRoot PolicyViolation = "it is outside the file root"
+)
+
+// ReferencePolicyError is returned when a reference isn't permitted by the ReferencePolicy of a SchemaLoader
+type ReferencePolicyError struct {
+	// Reference is the URL that isn't permitted, a redirect target for HTTP redirects
+	Reference string
+	Violation PolicyViolation
+}
+
+func (e *ReferencePolicyError) Error() string {
+	return formatErrorDescription(
+		Locale.ReferenceNotPermitted(),
+		ErrorDetails{"reference": e.Reference, "violation": string(e.Violation)},
+	)
+}
+
+// Check returns a *ReferencePolicyError if the policy doesn't permit loading reference
+func (p *ReferencePolicy) Check(reference string) error {
+	ref, err := gojsonreference.NewJsonReference(reference)
+	if err != nil {
+		return err
+	}
+	u := ref.GetUrl()
+	u.Fragment = ""
+	address := u.String()
+
+	if drafts.GetMetaSchema(address) != "" {
+		return nil
+	}
+
+	violation := func(v PolicyViolation) error {
+		return &ReferencePolicyError{Reference: address, Violation: v}
+	}
+
+	if p.Offline {
+		return violation(ViolationOffline)
+	}
+	for _, rule := range p.Deny {
+		if rule.matches(u) {
+			return violation(ViolationDenied)
+		}
+	}
+	if len(p.Allow) > 0 {
+		allowed := false
+		for _, rule := range p.Allow {
+			if rule.matches(u) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return violation(ViolationNotAllowed)
+		}
+	}
+
+	if ref.HasFileScheme && p.FileRoot != "" {
+		filename, err := referenceFilename(address)
+		if err != nil {
+			return err
+		}
+		inside, err := p.inFileRoot(filename)
+		if err != nil {
+			return err
+		}
+		if !inside {
+			return violation(ViolationOutsideFileRoot)
+		}
+	}
+	return nil
+}
+
+func (r ReferenceRule) matches(u *url.URL) bool {
+	if r.Scheme != "" && !strings.EqualFold(r.Scheme, u.Scheme) {
+		return false
+	}
+
+	if r.Host != "" {
+		host := u.Hostname()
+		if strings.Contains(r.Host, ":") {
+			host = u.Host
+		}
+		host = strings.ToLower(host)
+		pattern := strings.ToLower(r.Host)
+		if strings.HasPrefix(pattern, "*.") {
+			if !strings.HasSuffix(host, pattern[1:]) {
+				return false
+			}
+		} else if host != pattern {
+			return false
+		}
+	}
+
+	if r.Path != "" {
+		p := path.Clean("/" + u.Path)
+		if !strings.HasPrefix(p, r.Path) {
+			return false
+		}
+		if !strings.HasSuffix(r.Path, "/") && len(p) > len(r.Path) && p[len(r.Path)] != '/' {
+			return false
+		}
+	}
+	return true
+}
+
+// inFileRoot reports whether filename is in the FileRoot, both as written and with symbolic links resolved
+func (p *ReferencePolicy) inFileRoot(filename string) (bool, error) {
+	root, err := filepath.Abs(p.FileRoot)
+	if err != nil {
+		return false, err
+	}
+	filename, err = filepath.Abs(filename)
+	if err != nil {
+		return false, err
+	}
+	if !inDirectory(root, filename) {
+		return false, nil
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false, err
+	}
+	realFilename, err := filepath.EvalSymlinks(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		// loading it fails anyway
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return inDirectory(realRoot, realFilename), nil
+}
+
+// inDirectory reports whether the clean absolute path name is dir or inside it
+func inDirectory(dir string, name string) bool {
+	rel, err := filepath.Rel(dir, name)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checkRedirects returns a copy of client that checks the target of every redirect against the policy
+func (p *ReferencePolicy) checkRedirects(client *http.Client) *http.Client {
+	checked := *client
+	checked.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := p.Check(req.URL.String()); err != nil {
+			return err
+		}
+		return checkRedirect(client, req, via)
+	}
+	return &checked
+}
+
+// policyLoaderFactory checks the references of the loaders of factory against policy before they are loaded
+type policyLoaderFactory struct {
+	policy  *ReferencePolicy
+	factory JSONLoaderFactory
+}
+
+// New creates a new JSON loader for the given source
+func (f *policyLoaderFactory) New(source string) JSONLoader {
+	loader := f.factory.New(source)
+	if rl, ok := loader.(*jsonReferenceLoader); ok {
+		checked := *rl
+		checked.policy = f.policy
+		loader = &checked
+	}
+	return &policyLoader{JSONLoader: loader, source: source, factory: f}
+}
+
+type policyLoader struct {
+	JSONLoader
+	source  string
+	factory *policyLoaderFactory
+}
+
+func (l *policyLoader) LoadJSON() (interface{}, error) {
+	if err := l.factory.policy.Check(l.source); err != nil {
+		return nil, err
+	}
+	return l.JSONLoader.LoadJSON()
+}
+
+func (l *policyLoader) LoaderFactory() JSONLoaderFactory {
+	return l.factory
+}
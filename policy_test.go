@@ -0,0 +1,161 @@
+package gojsonschema
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferencePolicyCheck(t *testing.T) {
+	policy := &ReferencePolicy{
+		Allow: []ReferenceRule{
+			{Scheme: "https", Host: "schemas.example.com", Path: "/public"},
+			{Scheme: "https", Host: "*.cdn.example.com"},
+			{Host: "localhost:8080"},
+		},
+		Deny: []ReferenceRule{
+			{Path: "/public/internal/"},
+		},
+	}
+
+	cases := []struct {
+		reference string
+		violation PolicyViolation
+	}{
+		{"https://schemas.example.com/public/a.json#/definitions/a", ""},
+		{"https://SCHEMAS.example.com/public", ""},
+		{"HTTPS://schemas.example.com/public/a.json", ""},
+		{"https://eu.cdn.example.com/a.json", ""},
+		{"http://localhost:8080/a.json", ""},
+		{"http://json-schema.org/draft-07/schema#", ""},
+		{"http://schemas.example.com/public/a.json", ViolationNotAllowed},
+		{"https://schemas.example.com/public-private/a.json", ViolationNotAllowed},
+		{"https://schemas.example.com/public/../admin/a.json", ViolationNotAllowed},
+		{"https://cdn.example.com/a.json", ViolationNotAllowed},
+		{"https://schemas.example.com.evil.com/public/a.json", ViolationNotAllowed},
+		{"http://localhost/a.json", ViolationNotAllowed},
+		{"http://169.254.169.254/latest/meta-data", ViolationNotAllowed},
+		{"file:///etc/passwd", ViolationNotAllowed},
+		{"https://schemas.example.com/public/internal/a.json", ViolationDenied},
+		{"https://schemas.example.com/public/x/../internal/a.json", ViolationDenied},
+	}
+
+	for _, c := range cases {
+		err := policy.Check(c.reference)
+		if c.violation == "" {
+			assert.Nil(t, err, c.reference)
+			continue
+		}
+		var policyErr *ReferencePolicyError
+		if assert.True(t, errors.As(err, &policyErr), "%s: %v", c.reference, err) {
+			assert.Equal(t, c.violation, policyErr.Violation, c.reference)
+		}
+	}
+
+	err := policy.Check("http://localhost/a.json#/a")
+	assert.EqualError(t, err, "Loading the reference http://localhost/a.json is not permitted, it is not allowed")
+}
+
+func TestReferencePolicyOffline(t *testing.T) {
+	sl := NewSchemaLoader()
+	sl.Validate = true
+	sl.Policy = &ReferencePolicy{Offline: true}
+	assert.Nil(t, sl.AddSchema("http://example.com/string.json", NewStringLoader(`{"type": "string"}`)))
+
+	// the embedded meta-schemas and the added schemas are permitted
+	schema, err := sl.Compile(NewStringLoader(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"properties": {"a": {"$ref": "http://example.com/string.json"}}
+	}`))
+	if assert.Nil(t, err) {
+		result, err := schema.Validate(NewStringLoader(`{"a": 1}`))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"invalid_type (root).a"}, errorFields(result))
+	}
+
+	_, err = sl.Compile(NewStringLoader(`{"$ref": "http://example.com/other.json"}`))
+	var policyErr *ReferencePolicyError
+	if assert.True(t, errors.As(err, &policyErr), "%v", err) {
+		assert.Equal(t, ViolationOffline, policyErr.Violation)
+		assert.Equal(t, "http://example.com/other.json", policyErr.Reference)
+	}
+
+	_, err = sl.Compile(NewReferenceLoader("file:///etc/passwd"))
+	assert.True(t, errors.As(err, &policyErr), "%v", err)
+}
+
+func TestReferencePolicyFileRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "schemas")
+	assert.Nil(t, os.Mkdir(root, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "string.json"), []byte(`{"type": "string"}`), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "secret.json"), []byte(`{"type": "string"}`), 0644))
+	assert.Nil(t, os.Symlink(filepath.Join(dir, "secret.json"), filepath.Join(root, "link.json")))
+	assert.Nil(t, os.Symlink(filepath.Join(root, "string.json"), filepath.Join(root, "inside.json")))
+
+	sl := NewSchemaLoader()
+	sl.Policy = &ReferencePolicy{FileRoot: root}
+	base := "file://" + filepath.ToSlash(root) + "/"
+
+	cases := []struct {
+		reference string
+		permitted bool
+	}{
+		{"string.json", true},
+		{"inside.json", true},
+		{"../schemas/string.json", true},
+		{"../secret.json", false},
+		{"%2e%2e/secret.json", false},
+		{"link.json", false},
+	}
+
+	for _, c := range cases {
+		_, err := sl.Compile(NewStringLoader(`{"$id": "` + base + `main.json", "$ref": "` + c.reference + `"}`))
+		if c.permitted {
+			assert.Nil(t, err, c.reference)
+			continue
+		}
+		var policyErr *ReferencePolicyError
+		if assert.True(t, errors.As(err, &policyErr), "%s: %v", c.reference, err) {
+			assert.Equal(t, ViolationOutsideFileRoot, policyErr.Violation, c.reference)
+		}
+	}
+}
+
+func TestReferencePolicyRedirect(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/public/string.json":
+			w.Write([]byte(`{"type": "string"}`))
+		case "/public/moved.json":
+			http.Redirect(w, r, "/public/string.json", http.StatusFound)
+		case "/public/escape.json":
+			http.Redirect(w, r, "/private/string.json", http.StatusFound)
+		default:
+			w.Write([]byte(`{"type": "integer"}`))
+		}
+	}))
+	defer ts.Close()
+
+	sl := NewSchemaLoader()
+	sl.LoaderFactory = &HTTPJSONLoaderFactory{}
+	sl.Policy = &ReferencePolicy{Allow: []ReferenceRule{{Path: "/public/"}}}
+
+	_, err := sl.Compile(NewStringLoader(`{"$ref": "` + ts.URL + `/public/moved.json"}`))
+	assert.Nil(t, err)
+
+	_, err = sl.Compile(NewStringLoader(`{"$ref": "` + ts.URL + `/public/escape.json"}`))
+	var policyErr *ReferencePolicyError
+	if assert.True(t, errors.As(err, &policyErr), "%v", err) {
+		assert.Equal(t, ts.URL+"/private/string.json", policyErr.Reference)
+		assert.Equal(t, ViolationNotAllowed, policyErr.Violation)
+	}
+
+	_, err = sl.Compile(NewStringLoader(`{"$ref": "` + ts.URL + `/private/string.json"}`))
+	assert.True(t, errors.As(err, &policyErr), "%v", err)
+}
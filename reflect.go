@@ -0,0 +1,616 @@
+package gojsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Reflect returns a draft-07 schema document describing the JSON encoding of the type of v,
+// following the rules of encoding/json: json tags, omitempty, embedded structs, maps, slices and pointers.
+// Fields without omitempty or omitzero are required, time.Time is a string with the date-time format.
+//
+// Constraints are added with the jsonschema struct tag, a comma separated list of keywords, e.g.
+//
+//	Age  int    `json:"age" jsonschema:"minimum=0,maximum=150"`
+//	Kind string `json:"kind,omitempty" jsonschema:"enum=cat|dog,description=The kind of pet"`
+//
+// Recursive types are written once in definitions and referenced with $ref.
+// The document can be compiled with NewGoLoader
+func Reflect(v interface{}) (map[string]interface{}, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("cannot reflect the schema of nil")
+	}
+
+	r := &goReflector{
+		recursive: make(map[reflect.Type]bool),
+		names:     make(map[reflect.Type]string),
+		used:      make(map[string]bool),
+	}
+	r.findRecursive(t, make(map[reflect.Type]bool), make(map[reflect.Type]bool))
+
+	schema, err := r.typeSchema(t)
+	if err != nil {
+		return nil, err
+	}
+
+	document := map[string]interface{}{KEY_SCHEMA: "http://json-schema.org/draft-07/schema#"}
+	for k, v := range schema {
+		document[k] = v
+	}
+	if len(r.names) > 0 {
+		// Writing a definition may reference recursive types that were not named yet
+		definitions := make(map[string]interface{})
+		for len(definitions) < len(r.names) {
+			for t, name := range r.names {
+				if _, ok := definitions[name]; ok {
+					continue
+				}
+				definition, err := r.structSchema(t)
+				if err != nil {
+					return nil, err
+				}
+				definitions[name] = definition
+			}
+		}
+		document[KEY_DEFINITIONS] = definitions
+	}
+	return document, nil
+}
+
+// goReflector builds the schemas of Go types
+type goReflector struct {
+	// recursive are the struct types containing themselves, they are written in definitions
+	recursive map[reflect.Type]bool
+	names     map[reflect.Type]string
+	used      map[string]bool
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// findRecursive walks the types reachable from t and marks the structs found inside themselves
+func (r *goReflector) findRecursive(t reflect.Type, stack map[reflect.Type]bool, done map[reflect.Type]bool) {
+	if hasCustomJSON(t) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		r.findRecursive(t.Elem(), stack, done)
+	case reflect.Map:
+		r.findRecursive(t.Elem(), stack, done)
+	case reflect.Struct:
+		if stack[t] {
+			r.recursive[t] = true
+			return
+		}
+		if done[t] {
+			return
+		}
+		stack[t] = true
+		for _, f := range cachedGoFields(t) {
+			r.findRecursive(t.FieldByIndex(f.index).Type, stack, done)
+		}
+		delete(stack, t)
+		done[t] = true
+	}
+}
+
+// hasCustomJSON reports whether values of t encode themselves
+func hasCustomJSON(t reflect.Type) bool {
+	if t == timeType || t == numberType {
+		return true
+	}
+	return t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+// typeSchema returns the schema of t, or a $ref to it for recursive structs
+func (r *goReflector) typeSchema(t reflect.Type) (map[string]interface{}, error) {
+	switch {
+	case t == timeType:
+		return map[string]interface{}{KEY_TYPE: TYPE_STRING, KEY_FORMAT: "date-time"}, nil
+	case t == numberType:
+		return map[string]interface{}{KEY_TYPE: TYPE_NUMBER}, nil
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// Anything may come out of MarshalJSON
+		return map[string]interface{}{}, nil
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]interface{}{KEY_TYPE: TYPE_STRING}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{KEY_TYPE: TYPE_BOOLEAN}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{KEY_TYPE: TYPE_INTEGER}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{KEY_TYPE: TYPE_INTEGER, KEY_MINIMUM: json.Number("0")}, nil
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{KEY_TYPE: TYPE_NUMBER}, nil
+
+	case reflect.String:
+		return map[string]interface{}{KEY_TYPE: TYPE_STRING}, nil
+
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+
+	case reflect.Ptr:
+		elem, err := r.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(elem), nil
+
+	case reflect.Slice:
+		if isByteSlice(t) {
+			return nullable(map[string]interface{}{KEY_TYPE: TYPE_STRING, "contentEncoding": "base64"}), nil
+		}
+		items, err := r.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(map[string]interface{}{KEY_TYPE: TYPE_ARRAY, KEY_ITEMS: items}), nil
+
+	case reflect.Array:
+		items, err := r.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		length := json.Number(strconv.Itoa(t.Len()))
+		return map[string]interface{}{KEY_TYPE: TYPE_ARRAY, KEY_ITEMS: items, KEY_MIN_ITEMS: length, KEY_MAX_ITEMS: length}, nil
+
+	case reflect.Map:
+		schema := map[string]interface{}{KEY_TYPE: TYPE_OBJECT}
+		switch {
+		case t.Key().Kind() == reflect.String, t.Key().Implements(textMarshalerType):
+		case t.Key().Kind() >= reflect.Int && t.Key().Kind() <= reflect.Uintptr:
+			schema[KEY_PROPERTY_NAMES] = map[string]interface{}{KEY_PATTERN: "^-?[0-9]+$"}
+		default:
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := r.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		schema[KEY_ADDITIONAL_PROPERTIES] = values
+		return nullable(schema), nil
+
+	case reflect.Struct:
+		if r.recursive[t] {
+			return map[string]interface{}{KEY_REF: "#/" + KEY_DEFINITIONS + "/" + r.definitionName(t)}, nil
+		}
+		return r.structSchema(t)
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// definitionName returns the name of t in definitions
+func (r *goReflector) definitionName(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if name == "" {
+		name = "Type"
+	}
+	unique := name
+	for i := 2; r.used[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	r.used[unique] = true
+	r.names[t] = unique
+	return unique
+}
+
+func (r *goReflector) structSchema(t reflect.Type) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for _, f := range cachedGoFields(t) {
+		sf := t.FieldByIndex(f.index)
+
+		var schema map[string]interface{}
+		if f.quoted {
+			schema = map[string]interface{}{KEY_TYPE: TYPE_STRING}
+		} else {
+			var err error
+			if schema, err = r.typeSchema(sf.Type); err != nil {
+				return nil, fmt.Errorf("%s.%s: %s", t, sf.Name, err)
+			}
+		}
+
+		isRequired := !f.omitEmpty && !f.omitZero
+		if tag, ok := sf.Tag.Lookup("jsonschema"); ok {
+			var err error
+			if schema, isRequired, err = applyGoSchemaTag(schema, tag, isRequired); err != nil {
+				return nil, fmt.Errorf("%s.%s: %s", t, sf.Name, err)
+			}
+		}
+
+		properties[f.name] = schema
+		if isRequired {
+			required = append(required, f.name)
+		}
+	}
+
+	schema := map[string]interface{}{KEY_TYPE: TYPE_OBJECT, KEY_PROPERTIES: properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		requiredList := make([]interface{}, len(required))
+		for i, name := range required {
+			requiredList[i] = name
+		}
+		schema[KEY_REQUIRED] = requiredList
+	}
+	return schema, nil
+}
+
+// nullable allows null on top of schema, a nil pointer, slice or map is encoded as null
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	switch t := schema[KEY_TYPE].(type) {
+	case string:
+		schema[KEY_TYPE] = []interface{}{t, TYPE_NULL}
+	case []interface{}:
+		schema[KEY_TYPE] = append(t, TYPE_NULL)
+	default:
+		if ref, ok := schema[KEY_REF]; ok {
+			return map[string]interface{}{KEY_ONE_OF: []interface{}{
+				map[string]interface{}{KEY_REF: ref},
+				map[string]interface{}{KEY_TYPE: TYPE_NULL},
+			}}
+		}
+	}
+	return schema
+}
+
+// goSchemaTagKeywords are the keywords of the jsonschema struct tag, along with the kind of their value
+var goSchemaTagKeywords = map[string]string{
+	KEY_TITLE:             TYPE_STRING,
+	KEY_DESCRIPTION:       TYPE_STRING,
+	KEY_FORMAT:            TYPE_STRING,
+	KEY_PATTERN:           TYPE_STRING,
+	KEY_MINIMUM:           TYPE_NUMBER,
+	KEY_MAXIMUM:           TYPE_NUMBER,
+	KEY_EXCLUSIVE_MINIMUM: TYPE_NUMBER,
+	KEY_EXCLUSIVE_MAXIMUM: TYPE_NUMBER,
+	KEY_MULTIPLE_OF:       TYPE_NUMBER,
+	KEY_MIN_LENGTH:        TYPE_INTEGER,
+	KEY_MAX_LENGTH:        TYPE_INTEGER,
+	KEY_MIN_ITEMS:         TYPE_INTEGER,
+	KEY_MAX_ITEMS:         TYPE_INTEGER,
+	KEY_MIN_PROPERTIES:    TYPE_INTEGER,
+	KEY_MAX_PROPERTIES:    TYPE_INTEGER,
+	KEY_UNIQUE_ITEMS:      TYPE_BOOLEAN,
+	KEY_ENUM:              TYPE_ARRAY,
+	"default":             "",
+}
+
+// applyGoSchemaTag adds the constraints of a jsonschema struct tag to schema.
+// A comma inside a value is escaped as \, and the values of enum are separated by |.
+// The required and optional options override omitempty
+func applyGoSchemaTag(schema map[string]interface{}, tag string, required bool) (map[string]interface{}, bool, error) {
+	constraints := make(map[string]interface{})
+
+	for _, option := range splitGoSchemaTag(tag) {
+		key, value, hasValue := strings.Cut(option, "=")
+		switch {
+		case option == "":
+			continue
+		case key == KEY_REQUIRED && !hasValue:
+			required = true
+			continue
+		case key == "optional" && !hasValue:
+			required = false
+			continue
+		}
+
+		kind, ok := goSchemaTagKeywords[key]
+		if !ok || !hasValue {
+			return nil, false, fmt.Errorf("invalid jsonschema tag option %q", option)
+		}
+
+		var err error
+		switch kind {
+		case TYPE_STRING:
+			constraints[key] = value
+		case TYPE_NUMBER, TYPE_INTEGER:
+			if _, err = strconv.ParseFloat(value, 64); err == nil && isValidNumber(value) {
+				constraints[key] = json.Number(value)
+			} else {
+				err = fmt.Errorf("%s must be a number", key)
+			}
+		case TYPE_BOOLEAN:
+			var b bool
+			b, err = strconv.ParseBool(value)
+			constraints[key] = b
+		case TYPE_ARRAY:
+			var values []interface{}
+			for _, v := range strings.Split(value, "|") {
+				values = append(values, goSchemaTagValue(schema, v))
+			}
+			constraints[key] = values
+		default:
+			constraints[key] = goSchemaTagValue(schema, value)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if _, ok := schema[KEY_REF]; ok || schema[KEY_ONE_OF] != nil {
+		// Keywords next to $ref are ignored, the constraints are added next to it instead
+		constraints[KEY_ALL_OF] = []interface{}{schema}
+		return constraints, required, nil
+	}
+
+	for k, v := range constraints {
+		if k == KEY_ENUM && isNullable(schema) {
+			v = append(v.([]interface{}), nil)
+		}
+		schema[k] = v
+	}
+	return schema, required, nil
+}
+
+func splitGoSchemaTag(tag string) []string {
+	var options []string
+	var current strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			current.WriteByte(',')
+			i++
+		case tag[i] == ',':
+			options = append(options, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(tag[i])
+		}
+	}
+	return append(options, current.String())
+}
+
+// goSchemaTagValue converts value, e.g. an enum value, to the type of schema
+func goSchemaTagValue(schema map[string]interface{}, value string) interface{} {
+	types := []interface{}{schema[KEY_TYPE]}
+	if list, ok := schema[KEY_TYPE].([]interface{}); ok {
+		types = list
+	}
+	for _, t := range types {
+		switch t {
+		case TYPE_INTEGER, TYPE_NUMBER:
+			if isValidNumber(value) {
+				return json.Number(value)
+			}
+		case TYPE_BOOLEAN:
+			if b, err := strconv.ParseBool(value); err == nil {
+				return b
+			}
+		}
+	}
+	return value
+}
+
+func isNullable(schema map[string]interface{}) bool {
+	list, ok := schema[KEY_TYPE].([]interface{})
+	if !ok {
+		return false
+	}
+	for _, t := range list {
+		if t == TYPE_NULL {
+			return true
+		}
+	}
+	return false
+}
+
+// goField is a struct field as encoding/json sees it
+type goField struct {
+	name      string
+	tag       bool
+	index     []int
+	typ       reflect.Type
+	omitEmpty bool
+	omitZero  bool
+	quoted    bool
+}
+
+var goFieldCache sync.Map // map[reflect.Type][]goField
+
+func cachedGoFields(t reflect.Type) []goField {
+	if fields, ok := goFieldCache.Load(t); ok {
+		return fields.([]goField)
+	}
+	fields, _ := goFieldCache.LoadOrStore(t, goTypeFields(t))
+	return fields.([]goField)
+}
+
+// goTypeFields returns the fields encoding/json encodes for struct type t,
+// embedded structs being walked breadth first so shallower fields hide deeper ones
+func goTypeFields(t reflect.Type) []goField {
+	current := []goField{}
+	next := []goField{{typ: t}}
+
+	var count, nextCount map[reflect.Type]int
+	visited := map[reflect.Type]bool{}
+
+	var fields []goField
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+			visited[f.typ] = true
+
+			for i := 0; i < f.typ.NumField(); i++ {
+				sf := f.typ.Field(i)
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, options := parseGoTag(tag)
+				if !isValidGoTag(name) {
+					name = ""
+				}
+
+				index := make([]int, len(f.index)+1)
+				copy(index, f.index)
+				index[len(f.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+
+				quoted := false
+				if options["string"] {
+					switch ft.Kind() {
+					case reflect.Bool,
+						reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+						reflect.Float32, reflect.Float64,
+						reflect.String:
+						quoted = true
+					}
+				}
+
+				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
+					field := goField{
+						name:      name,
+						tag:       name != "",
+						index:     index,
+						typ:       ft,
+						omitEmpty: options["omitempty"],
+						omitZero:  options["omitzero"],
+						quoted:    quoted,
+					}
+					if field.name == "" {
+						field.name = sf.Name
+					}
+					fields = append(fields, field)
+					if count[f.typ] > 1 {
+						// Two copies at the same level annihilate each other, see dominantGoField
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, goField{name: ft.Name(), index: index, typ: ft})
+				}
+			}
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		x := fields
+		if x[i].name != x[j].name {
+			return x[i].name < x[j].name
+		}
+		if len(x[i].index) != len(x[j].index) {
+			return len(x[i].index) < len(x[j].index)
+		}
+		if x[i].tag != x[j].tag {
+			return x[i].tag
+		}
+		return goIndexLess(x[i].index, x[j].index)
+	})
+
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		name := fields[i].name
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].name != name {
+				break
+			}
+		}
+		if advance == 1 {
+			out = append(out, fields[i])
+			continue
+		}
+		if dominant, ok := dominantGoField(fields[i : i+advance]); ok {
+			out = append(out, dominant)
+		}
+	}
+
+	fields = out
+	sort.Slice(fields, func(i, j int) bool {
+		return goIndexLess(fields[i].index, fields[j].index)
+	})
+	return fields
+}
+
+// dominantGoField returns the field hiding the others of the same name, if there is one.
+// fields is sorted by depth and tagged fields first
+func dominantGoField(fields []goField) (goField, bool) {
+	if len(fields) > 1 && len(fields[0].index) == len(fields[1].index) && fields[0].tag == fields[1].tag {
+		return goField{}, false
+	}
+	return fields[0], true
+}
+
+func goIndexLess(a, b []int) bool {
+	for k, x := range a {
+		if k >= len(b) {
+			return false
+		}
+		if x != b[k] {
+			return x < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+func parseGoTag(tag string) (string, map[string]bool) {
+	options := map[string]bool{}
+	name, rest, _ := strings.Cut(tag, ",")
+	for rest != "" {
+		var option string
+		option, rest, _ = strings.Cut(rest, ",")
+		options[option] = true
+	}
+	return name, options
+}
+
+func isValidGoTag(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+			// Backslash and quote chars are reserved, but otherwise any punctuation chars are allowed in a tag name
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package gojsonschema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reflectAddress struct {
+	Street string `json:"street" jsonschema:"minLength=1"`
+	Zip    string `json:"zip,omitempty" jsonschema:"pattern=^[0-9]{5}$"`
+}
+
+type reflectBase struct {
+	ID      uint64    `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type reflectPerson struct {
+	reflectBase
+	Name     string          `json:"name" jsonschema:"title=Name,description=Full name\\, as written"`
+	Age      int             `json:"age,omitempty" jsonschema:"minimum=0,maximum=150"`
+	Kind     string          `json:"kind,omitempty" jsonschema:"enum=admin|user"`
+	Home     *reflectAddress `json:"home,omitempty"`
+	Tags     []string        `json:"tags" jsonschema:"optional,uniqueItems=true"`
+	Scores   map[string]int  `json:"scores,omitempty"`
+	Ratio    float64         `json:"ratio,string"`
+	Parent   *reflectPerson  `json:"parent,omitempty"`
+	Children []reflectPerson `json:"children,omitempty"`
+	Extra    interface{}     `json:"extra,omitempty"`
+	Ignored  string          `json:"-"`
+	hidden   string
+	Pair     [2]int            `json:"pair,omitempty"`
+	Labels   map[int]string    `json:"labels,omitempty"`
+	Raw      json.RawMessage   `json:"raw,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	Options  map[string]string `json:",omitempty"`
+}
+
+func TestReflect(t *testing.T) {
+	document, err := Reflect(reflectPerson{})
+	assert.Nil(t, err)
+
+	// The root type is recursive, it lives in definitions
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", document["$schema"])
+	assert.Equal(t, "#/definitions/reflectPerson", document["$ref"])
+	person := document["definitions"].(map[string]interface{})["reflectPerson"].(map[string]interface{})
+	properties := person["properties"].(map[string]interface{})
+
+	assert.Equal(t, []interface{}{"created", "id", "name", "ratio"}, person["required"])
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "date-time"}, properties["created"])
+	assert.Equal(t, map[string]interface{}{"type": "integer", "minimum": json.Number("0")}, properties["id"])
+	assert.Equal(t, "Full name, as written", properties["name"].(map[string]interface{})["description"])
+	assert.Equal(t, []interface{}{"admin", "user"}, properties["kind"].(map[string]interface{})["enum"])
+	assert.Equal(t, map[string]interface{}{"type": "string"}, properties["ratio"])
+	assert.Equal(t, map[string]interface{}{"oneOf": []interface{}{
+		map[string]interface{}{"$ref": "#/definitions/reflectPerson"},
+		map[string]interface{}{"type": "null"},
+	}}, properties["parent"])
+	assert.NotContains(t, properties, "Ignored")
+	assert.NotContains(t, properties, "hidden")
+	assert.Contains(t, properties, "Options")
+
+	// The document compiles back, and describes the values of the type
+	sl := NewSchemaLoader()
+	sl.Validate = true
+	schema, err := sl.Compile(NewGoLoader(document))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	valid := reflectPerson{
+		Name:     "Ada",
+		Kind:     "admin",
+		Home:     &reflectAddress{Street: "Main street", Zip: "12345"},
+		Children: []reflectPerson{{Name: "Bob"}},
+		Pair:     [2]int{1, 2},
+		Labels:   map[int]string{1: "one"},
+		Raw:      json.RawMessage(`{"any":"thing"}`),
+		Data:     []byte("data"),
+	}
+	result, err := schema.Validate(NewGoLoader(valid))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid(), "%v", result.Errors())
+
+	invalid := valid
+	invalid.Age = 200
+	invalid.Kind = "guest"
+	invalid.Home = &reflectAddress{Zip: "1"}
+	invalid.Tags = []string{"a", "a"}
+	result, err = schema.Validate(NewGoLoader(invalid))
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{
+		"number_lte (root).age",
+		"enum (root).kind",
+		"string_gte (root).home.street",
+		"pattern (root).home.zip",
+		"unique (root).tags",
+	}, errorFields(result))
+}
+
+func errorFields(result *Result) []string {
+	var fields []string
+	for _, err := range result.Errors() {
+		fields = append(fields, err.Type()+" "+err.Context().String())
+	}
+	return fields
+}
+
+func TestReflectErrors(t *testing.T) {
+	_, err := Reflect(nil)
+	assert.NotNil(t, err)
+
+	_, err = Reflect(struct{ C chan int }{})
+	assert.NotNil(t, err)
+
+	_, err = Reflect(struct {
+		A int `jsonschema:"minimum=abc"`
+	}{})
+	assert.NotNil(t, err)
+
+	_, err = Reflect(struct {
+		A int `jsonschema:"unknown=1"`
+	}{})
+	assert.NotNil(t, err)
+}
@@ -31,6 +31,8 @@ import (
 	"math/big"
 	"reflect"
 	"regexp"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/xeipuuv/gojsonreference"
@@ -51,16 +53,165 @@ func NewSchema(l JSONLoader) (*Schema, error) {
 }
 
 // Schema holds a schema
+//
+// A compiled Schema is never modified by validating a document, so it is safe to
+// call Validate and ValidateContext from multiple goroutines concurrently.
+// Setters such as SetMaxDepth should only be used before the Schema is shared
 type Schema struct {
 	documentReference gojsonreference.JsonReference
 	rootSchema        *subSchema
 	pool              *schemaPool
+	loaderFactory     JSONLoaderFactory
 	referencePool     *schemaReferencePool
+	maxDepth          int
+	limits            Limits
+
+	arrayWorkers         int
+	arrayWorkersMinItems int
 }
 
 func (d *Schema) parse(document interface{}, draft Draft) error {
 	d.rootSchema = &subSchema{property: STRING_ROOT_SCHEMA_PROPERTY, draft: &draft}
-	return d.parseSchema(document, d.rootSchema)
+	if err := d.parseSchema(document, d.rootSchema); err != nil {
+		return err
+	}
+	return d.checkReferenceCycles()
+}
+
+// checkReferenceCycles rejects schemas whose $ref chains loop back onto themselves
+// without descending into the document, e.g. {"allOf":[{"$ref":"#"}]}.
+// Such a loop would make validateRecursive recurse forever.
+//
+// Keywords that are applied to the same instance (allOf, not, $ref, ...) are followed
+// depth first while keywords that move on to a child instance (properties, items, ...)
+// start a new path, as recursing through those always terminates.
+func (d *Schema) checkReferenceCycles() error {
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+
+	state := make(map[*subSchema]int)
+	queue := []*subSchema{d.rootSchema}
+	var path []*subSchema
+
+	var visit func(s *subSchema) error
+	visit = func(s *subSchema) error {
+		switch state[s] {
+		case onPath:
+			return referenceCycleError(path, s)
+		case done:
+			return nil
+		}
+
+		state[s] = onPath
+		path = append(path, s)
+		for _, child := range s.sameInstanceChildren() {
+			if err := visit(child); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[s] = done
+
+		queue = append(queue, s.childInstanceChildren()...)
+		return nil
+	}
+
+	for len(queue) > 0 {
+		s := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if err := visit(s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// referenceCycleError reports the cycle path takes back to s: the $ref that closes it,
+// and every $ref that is followed on the way
+func referenceCycleError(path []*subSchema, s *subSchema) error {
+	start := len(path) - 1
+	for path[start] != s {
+		start--
+	}
+	cycle := append(path[start:len(path):len(path)], s)
+
+	var refs []string
+	for i, node := range cycle[:len(cycle)-1] {
+		if node.refSchema == cycle[i+1] {
+			ref := node.ref.String()
+			if ref == "" {
+				// the root of a document loaded without a URL
+				ref = "#"
+			}
+			refs = append(refs, strconv.Quote(ref))
+		}
+	}
+	return errors.New(formatErrorDescription(
+		Locale.ReferenceCycle(),
+		ErrorDetails{"reference": refs[len(refs)-1], "cycle": strings.Join(refs, " -> ")},
+	))
+}
+
+// SetMaxDepth sets the maximum validation depth, 0 disables the limit.
+// See SchemaLoader.MaxDepth
+func (d *Schema) SetMaxDepth(depth int) {
+	d.maxDepth = depth
+}
+
+// SetLimits sets the Limits documents must respect to be validated.
+// See SchemaLoader.Limits
+func (d *Schema) SetLimits(limits Limits) {
+	d.limits = limits
+}
+
+// SetArrayWorkers sets the number of goroutines validating the items of arrays
+// with at least minItems items. See SchemaLoader.ArrayWorkers
+func (d *Schema) SetArrayWorkers(workers int, minItems int) {
+	d.arrayWorkers = workers
+	d.arrayWorkersMinItems = minItems
+}
+
+// SubSchema returns the subschema of d at the JSON Pointer pointer, e.g. "#/definitions/address",
+// as a Schema of its own. Subschemas that were already compiled as part of d, for instance
+// because they are referenced, are shared with d rather than compiled again
+func (d *Schema) SubSchema(pointer string) (*Schema, error) {
+	fragment, err := gojsonreference.NewJsonReference("#" + strings.TrimPrefix(pointer, "#"))
+	if err != nil {
+		return nil, err
+	}
+	ref, err := d.documentReference.Inherits(fragment)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := *d
+	sub.documentReference = *ref
+
+	if fragment.String() == "#" || fragment.String() == "" {
+		return &sub, nil
+	}
+
+	d.referencePool.lock.Lock()
+	defer d.referencePool.lock.Unlock()
+
+	root, ok := d.referencePool.Get(ref.String())
+	if !ok {
+		holder := &subSchema{property: STRING_ROOT_SCHEMA_PROPERTY, draft: d.rootSchema.draft, ref: ref, id: ref}
+		if err := sub.parseReference(nil, holder); err != nil {
+			return nil, err
+		}
+		root = holder.refSchema
+	}
+
+	sub.rootSchema = root
+	if err := sub.checkReferenceCycles(); err != nil {
+		return nil, err
+	}
+	return &sub, nil
 }
 
 // SetRootSchemaName sets the root-schema name
@@ -976,7 +1127,7 @@ func (d *Schema) parseReference(documentNode interface{}, currentSchema *subSche
 
 	d.referencePool.Add(currentSchema.ref.String(), newSchema)
 
-	dsp, err = d.pool.GetDocument(*currentSchema.ref)
+	dsp, err = d.pool.GetDocument(*currentSchema.ref, d.loaderFactory)
 	if err != nil {
 		return err
 	}
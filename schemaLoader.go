@@ -21,26 +21,47 @@ import (
 	"github.com/xeipuuv/gojsonreference"
 )
 
+// DefaultMaxDepth is the default maximum validation depth of a SchemaLoader
+const DefaultMaxDepth = 10000
+
 // SchemaLoader is used to load schemas
+//
+// AddSchema, AddSchemas and Compile may be called from multiple goroutines concurrently,
+// as long as the exported fields are not changed at the same time
 type SchemaLoader struct {
 	pool       *schemaPool
 	AutoDetect bool
 	Validate   bool
 	Draft      Draft
+	// MaxDepth is the maximum number of nested (sub)schemas, including $ref, that may be
+	// applied while validating a document with a compiled Schema. Exceeding it produces a
+	// MaxDepthError instead of overflowing the stack. 0 disables the limit
+	MaxDepth int
+	// Limits are enforced on every document validated by a compiled Schema.
+	// A document exceeding them is not validated, instead Validate returns a *LimitError
+	Limits Limits
+	// ArrayWorkers is the number of goroutines a compiled Schema uses to validate the items
+	// of an array with at least ArrayWorkersMinItems items. 0 or 1 validates them sequentially
+	ArrayWorkers         int
+	ArrayWorkersMinItems int
+	// LoaderFactory creates the loaders of the schemas compiled by reference and of their $refs,
+	// e.g. a *HTTPJSONLoaderFactory. If nil, the LoaderFactory of the compiled JSONLoader is used
+	LoaderFactory JSONLoaderFactory
+	// Policy restricts the references that may be loaded while compiling a schema, nil permits all of them
+	Policy *ReferencePolicy
 }
 
 // NewSchemaLoader creates a new NewSchemaLoader
 func NewSchemaLoader() *SchemaLoader {
 
 	ps := &SchemaLoader{
-		pool: &schemaPool{
-			schemaPoolDocuments: make(map[string]*schemaPoolDocument),
-		},
-		AutoDetect: true,
-		Validate:   false,
-		Draft:      Hybrid,
+		AutoDetect:           true,
+		Validate:             false,
+		Draft:                Hybrid,
+		MaxDepth:             DefaultMaxDepth,
+		ArrayWorkersMinItems: DefaultArrayWorkersMinItems,
 	}
-	ps.pool.autoDetect = &ps.AutoDetect
+	ps.pool = newSchemaPool(&ps.AutoDetect)
 
 	return ps
 }
@@ -66,17 +87,13 @@ func (sl *SchemaLoader) validateMetaschema(documentNode interface{}) error {
 		schema = drafts.GetSchemaURL(sl.Draft)
 	}
 
-	//Disable validation when loading the metaschema to prevent an infinite recursive loop
-	sl.Validate = false
-
-	metaSchema, err := sl.Compile(NewReferenceLoader(schema))
+	// Disable validation when loading the metaschema to prevent an infinite recursive loop
+	metaSchema, err := sl.compile(NewReferenceLoader(schema), false)
 
 	if err != nil {
 		return err
 	}
 
-	sl.Validate = true
-
 	result := metaSchema.validateDocument(documentNode)
 
 	if !result.Valid() {
@@ -119,7 +136,7 @@ func (sl *SchemaLoader) AddSchemas(loaders ...JSONLoader) error {
 	return nil
 }
 
-//AddSchema adds a schema under the provided URL to the schema cache
+// AddSchema adds a schema under the provided URL to the schema cache
 func (sl *SchemaLoader) AddSchema(url string, loader JSONLoader) error {
 
 	ref, err := gojsonreference.NewJsonReference(url)
@@ -145,6 +162,10 @@ func (sl *SchemaLoader) AddSchema(url string, loader JSONLoader) error {
 
 // Compile loads and compiles a schema
 func (sl *SchemaLoader) Compile(rootSchema JSONLoader) (*Schema, error) {
+	return sl.compile(rootSchema, sl.Validate)
+}
+
+func (sl *SchemaLoader) compile(rootSchema JSONLoader, validate bool) (*Schema, error) {
 
 	ref, err := rootSchema.JsonReference()
 
@@ -154,14 +175,24 @@ func (sl *SchemaLoader) Compile(rootSchema JSONLoader) (*Schema, error) {
 
 	d := Schema{}
 	d.pool = sl.pool
-	d.pool.jsonLoaderFactory = rootSchema.LoaderFactory()
+	d.loaderFactory = rootSchema.LoaderFactory()
+	if sl.LoaderFactory != nil {
+		d.loaderFactory = sl.LoaderFactory
+	}
+	if sl.Policy != nil {
+		d.loaderFactory = &policyLoaderFactory{policy: sl.Policy, factory: d.loaderFactory}
+	}
 	d.documentReference = ref
 	d.referencePool = newSchemaReferencePool()
+	d.maxDepth = sl.MaxDepth
+	d.limits = sl.Limits
+	d.arrayWorkers = sl.ArrayWorkers
+	d.arrayWorkersMinItems = sl.ArrayWorkersMinItems
 
 	var doc interface{}
 	if ref.String() != "" {
 		// Get document from schema pool
-		spd, err := d.pool.GetDocument(d.documentReference)
+		spd, err := d.pool.GetDocument(d.documentReference, d.loaderFactory)
 		if err != nil {
 			return nil, err
 		}
@@ -172,15 +203,18 @@ func (sl *SchemaLoader) Compile(rootSchema JSONLoader) (*Schema, error) {
 		if err != nil {
 			return nil, err
 		}
+		// The root document has no reference of its own, so keep it in a private pool
+		// to allow the SchemaLoader to compile any number of such schemas
+		d.pool = sl.pool.newChild()
 		// References need only be parsed if loading JSON directly
 		//  as pool.GetDocument already does this for us if loading by reference
-		err = sl.pool.parseReferences(doc, ref, true)
+		err = d.pool.parseReferences(doc, ref, true)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if sl.Validate {
+	if validate {
 		if err := sl.validateMetaschema(doc); err != nil {
 			return nil, err
 		}
@@ -74,6 +74,36 @@ func TestDoubleIDReference(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+// The $ids embedded in a schema compiled without a reference can be referenced by the schemas compiled after it
+func TestEmbeddedIDReference(t *testing.T) {
+	sl := NewSchemaLoader()
+	_, err := sl.Compile(NewStringLoader(`{
+		"definitions" : { "positive" : { "$id" : "http://localhost:1234/positive.json", "minimum" : 0 } }
+	}`))
+	assert.Nil(t, err)
+
+	schema, err := sl.Compile(NewStringLoader(`{ "$ref" : "http://localhost:1234/positive.json" }`))
+	assert.Nil(t, err)
+	result, err := schema.Validate(NewStringLoader(`-1`))
+	assert.Nil(t, err)
+	assert.False(t, result.Valid())
+
+	err = sl.AddSchemas(NewStringLoader(`{ "$id" : "http://localhost:1234/positive.json" }`))
+	assert.NotNil(t, err)
+}
+
+// The $id of a schema compiled without a reference is private to it, like the schema
+func TestPrivateRootID(t *testing.T) {
+	sl := NewSchemaLoader()
+	for _, schema := range []string{
+		`{ "$id" : "http://localhost:1234/main.json", "type" : "string" }`,
+		`{ "$id" : "http://localhost:1234/main.json", "type" : "integer" }`,
+	} {
+		_, err := sl.Compile(NewStringLoader(schema))
+		assert.Nil(t, err, schema)
+	}
+}
+
 func TestCustomMetaSchema(t *testing.T) {
 
 	loader := NewStringLoader(`{
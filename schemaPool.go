@@ -30,6 +30,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 
 	"github.com/xeipuuv/gojsonreference"
 )
@@ -41,11 +42,77 @@ type schemaPoolDocument struct {
 
 type schemaPool struct {
 	schemaPoolDocuments map[string]*schemaPoolDocument
-	jsonLoaderFactory   JSONLoaderFactory
 	autoDetect          *bool
+
+	// parent is only set for the private pool of a Schema compiled without a reference.
+	// Such a root document lives at the empty reference, which would clash with other
+	// Schemas compiled by the same SchemaLoader. Everything else, including the $ids
+	// of the subschemas of the root document, is looked up in, and loaded into, the parent
+	parent *schemaPool
+
+	// lock guards schemaPoolDocuments, as a pool is shared by every
+	// AddSchema, AddSchemas and Compile call of a SchemaLoader
+	lock sync.RWMutex
+}
+
+func newSchemaPool(autoDetect *bool) *schemaPool {
+	return &schemaPool{
+		schemaPoolDocuments: make(map[string]*schemaPoolDocument),
+		autoDetect:          autoDetect,
+	}
+}
+
+// newChild creates a private pool on top of p
+func (p *schemaPool) newChild() *schemaPool {
+	child := newSchemaPool(p.autoDetect)
+	child.parent = p
+	return child
+}
+
+// shared returns the pool of the SchemaLoader
+func (p *schemaPool) shared() *schemaPool {
+	if p.parent != nil {
+		return p.parent.shared()
+	}
+	return p
+}
+
+// lookup finds a document in p or its parents, along with the pool it was found in
+func (p *schemaPool) lookup(reference string) (*schemaPoolDocument, *schemaPool, bool) {
+	p.lock.RLock()
+	spd, ok := p.schemaPoolDocuments[reference]
+	p.lock.RUnlock()
+
+	if ok {
+		return spd, p, true
+	}
+	if p.parent != nil {
+		return p.parent.lookup(reference)
+	}
+	return nil, nil, false
+}
+
+// existsLocked is lookup for callers already holding p.lock
+func (p *schemaPool) existsLocked(reference string) bool {
+	if _, ok := p.schemaPoolDocuments[reference]; ok {
+		return true
+	}
+	if p.parent != nil {
+		_, _, ok := p.parent.lookup(reference)
+		return ok
+	}
+	return false
 }
 
 func (p *schemaPool) parseReferences(document interface{}, ref gojsonreference.JsonReference, pooled bool) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.parseReferencesLocked(document, ref, pooled)
+}
+
+// parseReferencesLocked is parseReferences for callers already holding p.lock
+func (p *schemaPool) parseReferencesLocked(document interface{}, ref gojsonreference.JsonReference, pooled bool) error {
 
 	var (
 		draft     *Draft
@@ -53,7 +120,7 @@ func (p *schemaPool) parseReferences(document interface{}, ref gojsonreference.J
 		reference = ref.String()
 	)
 	// Only the root document should be added to the schema pool if pooled is true
-	if _, ok := p.schemaPoolDocuments[reference]; pooled && ok {
+	if pooled && p.existsLocked(reference) {
 		return fmt.Errorf("Reference already exists: \"%s\"", reference)
 	}
 
@@ -64,7 +131,7 @@ func (p *schemaPool) parseReferences(document interface{}, ref gojsonreference.J
 		}
 	}
 
-	err = p.parseReferencesRecursive(document, ref, draft)
+	err = p.parseReferencesRecursive(document, ref, draft, true)
 
 	if pooled {
 		p.schemaPoolDocuments[reference] = &schemaPoolDocument{Document: document, Draft: draft}
@@ -73,7 +140,7 @@ func (p *schemaPool) parseReferences(document interface{}, ref gojsonreference.J
 	return err
 }
 
-func (p *schemaPool) parseReferencesRecursive(document interface{}, ref gojsonreference.JsonReference, draft *Draft) error {
+func (p *schemaPool) parseReferencesRecursive(document interface{}, ref gojsonreference.JsonReference, draft *Draft, root bool) error {
 	// parseReferencesRecursive parses a JSON document and resolves all $id and $ref references.
 	// For $ref references it takes into account the $id scope it is in and replaces
 	// the reference by the absolute resolved reference
@@ -83,7 +150,7 @@ func (p *schemaPool) parseReferencesRecursive(document interface{}, ref gojsonre
 	switch m := document.(type) {
 	case []interface{}:
 		for _, v := range m {
-			p.parseReferencesRecursive(v, ref, draft)
+			p.parseReferencesRecursive(v, ref, draft, false)
 		}
 	case map[string]interface{}:
 		localRef := &ref
@@ -97,10 +164,9 @@ func (p *schemaPool) parseReferencesRecursive(document interface{}, ref gojsonre
 			if err == nil {
 				localRef, err = ref.Inherits(jsonReference)
 				if err == nil {
-					if _, ok := p.schemaPoolDocuments[localRef.String()]; ok {
-						return fmt.Errorf("Reference already exists: \"%s\"", localRef.String())
+					if err := p.addIdentifiedLocked(localRef.String(), &schemaPoolDocument{Document: document, Draft: draft}, root); err != nil {
+						return err
 					}
-					p.schemaPoolDocuments[localRef.String()] = &schemaPoolDocument{Document: document, Draft: draft}
 				}
 			}
 		}
@@ -125,18 +191,41 @@ func (p *schemaPool) parseReferencesRecursive(document interface{}, ref gojsonre
 			if k == KEY_PROPERTIES || k == KEY_DEPENDENCIES || k == KEY_PATTERN_PROPERTIES {
 				if child, ok := v.(map[string]interface{}); ok {
 					for _, v := range child {
-						p.parseReferencesRecursive(v, *localRef, draft)
+						p.parseReferencesRecursive(v, *localRef, draft, false)
 					}
 				}
 			} else {
-				p.parseReferencesRecursive(v, *localRef, draft)
+				p.parseReferencesRecursive(v, *localRef, draft, false)
 			}
 		}
 	}
 	return nil
 }
 
-func (p *schemaPool) GetDocument(reference gojsonreference.JsonReference) (*schemaPoolDocument, error) {
+// addIdentifiedLocked adds spd, a schema with an $id, to the pool at reference.
+// A private pool adds the subschemas of its root document to the pool of the SchemaLoader,
+// so other schemas can reference them. The root itself stays private like the rest of it
+func (p *schemaPool) addIdentifiedLocked(reference string, spd *schemaPoolDocument, root bool) error {
+	if p.parent == nil || root {
+		if _, ok := p.schemaPoolDocuments[reference]; ok {
+			return fmt.Errorf("Reference already exists: \"%s\"", reference)
+		}
+		p.schemaPoolDocuments[reference] = spd
+		return nil
+	}
+
+	if _, ok := p.schemaPoolDocuments[reference]; ok {
+		return fmt.Errorf("Reference already exists: \"%s\"", reference)
+	}
+	shared := p.shared()
+	shared.lock.Lock()
+	defer shared.lock.Unlock()
+	return shared.addIdentifiedLocked(reference, spd, false)
+}
+
+// GetDocument returns the document the reference points to, loading it with loaderFactory
+// if it is not part of the pool yet
+func (p *schemaPool) GetDocument(reference gojsonreference.JsonReference, loaderFactory JSONLoaderFactory) (*schemaPoolDocument, error) {
 
 	var (
 		spd   *schemaPoolDocument
@@ -155,7 +244,7 @@ func (p *schemaPool) GetDocument(reference gojsonreference.JsonReference) (*sche
 	// First check if the given fragment is a location independent identifier
 	// http://json-schema.org/latest/json-schema-core.html#rfc.section.8.2.3
 
-	if spd, ok = p.schemaPoolDocuments[refToURL.String()]; ok {
+	if spd, _, ok = p.lookup(refToURL.String()); ok {
 		if internalLogEnabled {
 			internalLog(" From pool")
 		}
@@ -167,7 +256,7 @@ func (p *schemaPool) GetDocument(reference gojsonreference.JsonReference) (*sche
 
 	refToURL.GetUrl().Fragment = ""
 
-	if cachedSpd, ok := p.schemaPoolDocuments[refToURL.String()]; ok {
+	if cachedSpd, owner, ok := p.lookup(refToURL.String()); ok {
 		document, _, err := reference.GetPointer().Get(cachedSpd.Document)
 
 		if err != nil {
@@ -179,7 +268,9 @@ func (p *schemaPool) GetDocument(reference gojsonreference.JsonReference) (*sche
 		}
 
 		spd = &schemaPoolDocument{Document: document, Draft: cachedSpd.Draft}
-		p.schemaPoolDocuments[reference.String()] = spd
+		owner.lock.Lock()
+		owner.schemaPoolDocuments[reference.String()] = spd
+		owner.lock.Unlock()
 
 		return spd, nil
 	}
@@ -192,15 +283,28 @@ func (p *schemaPool) GetDocument(reference gojsonreference.JsonReference) (*sche
 		))
 	}
 
-	jsonReferenceLoader := p.jsonLoaderFactory.New(reference.String())
+	jsonReferenceLoader := loaderFactory.New(reference.String())
 	document, err := jsonReferenceLoader.LoadJSON()
 
 	if err != nil {
 		return nil, err
 	}
 
-	// add the whole document to the pool for potential re-use
-	p.parseReferences(document, refToURL, true)
+	// add the whole document to the pool for potential re-use,
+	// unless another goroutine has loaded the same document in the meantime
+	shared := p.shared()
+	shared.lock.Lock()
+	if cachedSpd, ok := shared.schemaPoolDocuments[refToURL.String()]; ok {
+		document = cachedSpd.Document
+	} else {
+		shared.parseReferencesLocked(document, refToURL, true)
+	}
+	shared.lock.Unlock()
+
+	// a plain name fragment is declared by an $id of the document, which is pooled now
+	if spd, _, ok = p.lookup(reference.String()); ok {
+		return spd, nil
+	}
 
 	_, draft, _ = parseSchemaURL(document)
 
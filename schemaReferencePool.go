@@ -26,11 +26,15 @@
 package gojsonschema
 
 import (
-	"fmt"
+	"sync"
 )
 
 type schemaReferencePool struct {
 	documents map[string]*subSchema
+
+	// lock serializes Schema.SubSchema calls, which may compile
+	// new subschemas into the pool of an already compiled Schema
+	lock sync.Mutex
 }
 
 func newSchemaReferencePool() *schemaReferencePool {
@@ -44,12 +48,12 @@ func newSchemaReferencePool() *schemaReferencePool {
 func (p *schemaReferencePool) Get(ref string) (r *subSchema, o bool) {
 
 	if internalLogEnabled {
-		internalLog(fmt.Sprintf("Schema Reference ( %s )", ref))
+		internalLog("Schema Reference ( %s )", ref)
 	}
 
 	if sch, ok := p.documents[ref]; ok {
 		if internalLogEnabled {
-			internalLog(fmt.Sprintf(" From pool"))
+			internalLog(" From pool")
 		}
 		return sch, true
 	}
@@ -60,7 +64,7 @@ func (p *schemaReferencePool) Get(ref string) (r *subSchema, o bool) {
 func (p *schemaReferencePool) Add(ref string, sch *subSchema) {
 
 	if internalLogEnabled {
-		internalLog(fmt.Sprintf("Add Schema Reference %s to pool", ref))
+		internalLog("Add Schema Reference %s to pool", ref)
 	}
 	if _, ok := p.documents[ref]; !ok {
 		p.documents[ref] = sch
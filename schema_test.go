@@ -27,12 +27,17 @@ package gojsonschema
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -380,3 +385,213 @@ func TestIncorrectRef(t *testing.T) {
 	assert.Nil(t, s)
 	assert.Equal(t, "Object has no key 'fail'", err.Error())
 }
+
+func TestValidateContextCancelled(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"type" : "array",
+		"items" : { "oneOf" : [ { "type" : "integer" }, { "type" : "string" } ] }
+	}`))
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := schema.ValidateContext(ctx, NewStringLoader(`[1, "a", true]`))
+	assert.Equal(t, context.Canceled, err)
+	assert.Nil(t, result)
+
+	result, err = schema.ValidateContext(context.Background(), NewStringLoader(`[1, "a", true]`))
+	assert.Nil(t, err)
+	assert.False(t, result.Valid())
+
+	// A context cancelled once the validation has completed doesn't fail it
+	result, err = schema.ValidateContext(lateCancelContext{context.Background()}, NewStringLoader(`[1, "a", true]`))
+	assert.Nil(t, err)
+	assert.False(t, result.Valid())
+}
+
+// A validation cancelled inside a oneOf returns no Result rather than the errors of its cut short branches
+func TestValidateContextCancelledInOneOf(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"oneOf" : [
+			{ "properties" : { "a" : { "type" : "integer" }, "b" : { "type" : "integer" } } },
+			{ "not" : { "type" : "object", "properties" : { "a" : { "type" : "integer" } } } }
+		]
+	}`))
+	assert.Nil(t, err)
+
+	for checks := 0; checks < 20; checks++ {
+		ctx := &doneAfterContext{Context: context.Background(), checks: checks}
+		result, err := schema.ValidateContext(ctx, NewStringLoader(`{"a" : 1, "b" : 2}`))
+		if err != nil {
+			assert.Equal(t, context.Canceled, err, "after %d checks", checks)
+			assert.Nil(t, result, "after %d checks", checks)
+		} else {
+			assert.True(t, result.Valid(), "after %d checks: %v", checks, result.Errors())
+		}
+	}
+}
+
+// doneAfterContext is done once Done has been called checks times
+type doneAfterContext struct {
+	context.Context
+	checks int
+	calls  int
+}
+
+func (c *doneAfterContext) Done() <-chan struct{} {
+	c.calls++
+	if c.calls > c.checks {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return nil
+}
+
+func (c *doneAfterContext) Err() error {
+	if c.calls > c.checks {
+		return context.Canceled
+	}
+	return nil
+}
+
+// lateCancelContext is never done, but reports it was cancelled, as if it was right after the validation
+type lateCancelContext struct {
+	context.Context
+}
+
+func (lateCancelContext) Err() error {
+	return context.Canceled
+}
+
+func TestValidateContextHTTPTimeout(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+
+	schema, err := NewSchema(NewStringLoader(`{}`))
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = schema.ValidateContext(ctx, NewReferenceLoader(server.URL+"/document.json"))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected deadline exceeded, got %v", err)
+}
+
+func TestInfiniteReferenceRecursion(t *testing.T) {
+	schemas := map[string]string{
+		`{"$ref" : "#"}`:               `Reference "#" recurses into itself infinitely, through "#"`,
+		`{"allOf" : [{"$ref" : "#"}]}`: `Reference "#" recurses into itself infinitely, through "#"`,
+		`{"definitions" : {"a" : {"anyOf" : [{"$ref" : "#/definitions/b"}]}, "b" : {"not" : {"$ref" : "#/definitions/a"}}}, "$ref" : "#/definitions/a"}`: `Reference "#/definitions/a" recurses into itself infinitely, through "#/definitions/b" -> "#/definitions/a"`,
+		`{"properties" : {"x" : {"$ref" : "#/definitions/a"}}, "definitions" : {"a" : {"$ref" : "#/definitions/a"}}}`:                                    `Reference "#/definitions/a" recurses into itself infinitely, through "#/definitions/a"`,
+	}
+	for s, message := range schemas {
+		_, err := NewSchema(NewStringLoader(s))
+		assert.EqualError(t, err, message, s)
+	}
+
+	// Recursion that descends into the document terminates and is allowed
+	_, err := NewSchema(NewStringLoader(`{"anyOf" : [{"type" : "string"}, {"items" : {"$ref" : "#"}}]}`))
+	assert.Nil(t, err)
+}
+
+func TestMaxDepth(t *testing.T) {
+	sl := NewSchemaLoader()
+	sl.MaxDepth = 10
+	schema, err := sl.Compile(NewStringLoader(`{"anyOf" : [{"type" : "integer"}, {"items" : {"$ref" : "#"}}]}`))
+	assert.Nil(t, err)
+
+	result, err := schema.Validate(NewStringLoader(`[[1]]`))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid())
+
+	result, err = schema.Validate(NewStringLoader(`[[[[[[[[[[[[1]]]]]]]]]]]]`))
+	assert.Nil(t, err)
+	if assert.False(t, result.Valid()) {
+		assert.Equal(t, "max_depth", result.Errors()[len(result.Errors())-1].Type())
+	}
+
+	schema.SetMaxDepth(0)
+	result, err = schema.Validate(NewStringLoader(`[[[[[[[[[[[[1]]]]]]]]]]]]`))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid())
+}
+
+func TestSubSchema(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"definitions" : {
+			"address" : {
+				"type" : "object",
+				"properties" : { "zip" : { "type" : "string", "pattern" : "^[0-9]{5}$" } },
+				"required" : ["zip"]
+			},
+			"name" : { "type" : "string", "minLength" : 1 },
+			"a/b" : { "const" : 1 }
+		},
+		"properties" : { "name" : { "$ref" : "#/definitions/name" } }
+	}`))
+	assert.Nil(t, err)
+
+	address, err := schema.SubSchema("#/definitions/address")
+	assert.Nil(t, err)
+	result, err := address.Validate(NewStringLoader(`{"zip" : "1234"}`))
+	assert.Nil(t, err)
+	if assert.Len(t, result.Errors(), 1) {
+		assert.Equal(t, "(root).zip", result.Errors()[0].Context().String())
+	}
+
+	// Referenced subschemas are shared
+	name, err := schema.SubSchema("/definitions/name")
+	assert.Nil(t, err)
+	assert.True(t, name.rootSchema == schema.rootSchema.propertiesChildren[0].refSchema)
+
+	escaped, err := schema.SubSchema("#/definitions/a~1b")
+	assert.Nil(t, err)
+	result, err = escaped.Validate(NewStringLoader(`2`))
+	assert.Nil(t, err)
+	assert.False(t, result.Valid())
+
+	root, err := schema.SubSchema("#")
+	assert.Nil(t, err)
+	assert.True(t, root.rootSchema == schema.rootSchema)
+
+	_, err = schema.SubSchema("#/definitions/missing")
+	assert.NotNil(t, err)
+}
+
+func TestValidateAt(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"definitions" : {
+			"address" : {
+				"properties" : { "zip" : { "type" : "string" } },
+				"required" : ["zip", "city"]
+			}
+		}
+	}`))
+	assert.Nil(t, err)
+	address, err := schema.SubSchema("#/definitions/address")
+	assert.Nil(t, err)
+
+	document := NewStringLoader(`{"customer" : {"shipping" : {"zip" : 12345, "city" : "x"}, "billing" : {"zip" : "1"}}}`)
+
+	result, err := address.ValidateAt(document, "/customer/shipping")
+	assert.Nil(t, err)
+	if assert.Len(t, result.Errors(), 1) {
+		assert.Equal(t, "(root).customer.shipping.zip", result.Errors()[0].Context().String())
+		assert.Equal(t, "customer.shipping.zip", result.Errors()[0].Field())
+	}
+
+	result, err = address.ValidateAt(document, "#/customer/billing")
+	assert.Nil(t, err)
+	if assert.Len(t, result.Errors(), 1) {
+		assert.Equal(t, "(root).customer.billing", result.Errors()[0].Context().String())
+	}
+
+	_, err = address.ValidateAt(document, "/customer/missing")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,527 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+
+	"github.com/xeipuuv/gojsonreference"
+)
+
+// SerializationVersion is the version of the format written by Schema.MarshalBinary.
+// It changes whenever a compiled Schema written by one release can no longer be read by another
+const SerializationVersion = 1
+
+// ErrIncompatibleSerialization is returned when a serialized Schema was written in another SerializationVersion
+var ErrIncompatibleSerialization = errors.New("serialized schema has an incompatible version")
+
+// serializedSchema is the serialized form of a compiled Schema.
+// subSchemas are stored in a flat list and refer to each other by index, which keeps the cycles of refSchema
+type serializedSchema struct {
+	Version              int                           `json:"version"`
+	DocumentReference    string                        `json:"documentReference"`
+	Root                 int                           `json:"root"`
+	SubSchemas           []serializedSubSchema         `json:"subSchemas"`
+	References           map[string]int                `json:"references,omitempty"`
+	Documents            map[string]serializedDocument `json:"documents,omitempty"`
+	MaxDepth             int                           `json:"maxDepth,omitempty"`
+	Limits               Limits                        `json:"limits"`
+	ArrayWorkers         int                           `json:"arrayWorkers,omitempty"`
+	ArrayWorkersMinItems int                           `json:"arrayWorkersMinItems,omitempty"`
+}
+
+type serializedDocument struct {
+	Document interface{} `json:"document"`
+	Draft    *Draft      `json:"draft,omitempty"`
+}
+
+// serializedRef is either a boolean or the index of a subSchema, as used by
+// additionalProperties and additionalItems, or a list of properties for dependencies
+type serializedRef struct {
+	Bool       *bool    `json:"bool,omitempty"`
+	SubSchema  *int     `json:"subSchema,omitempty"`
+	Properties []string `json:"properties,omitempty"`
+}
+
+type serializedSubSchema struct {
+	Draft       *Draft   `json:"draft,omitempty"`
+	ID          *string  `json:"id,omitempty"`
+	Title       *string  `json:"title,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Property    string   `json:"property,omitempty"`
+	Pass        *bool    `json:"pass,omitempty"`
+	Types       []string `json:"types,omitempty"`
+	Ref         *string  `json:"ref,omitempty"`
+	RefSchema   *int     `json:"refSchema,omitempty"`
+
+	Parent                      *int  `json:"parent,omitempty"`
+	ItemsChildren               []int `json:"itemsChildren,omitempty"`
+	ItemsChildrenIsSingleSchema bool  `json:"itemsChildrenIsSingleSchema,omitempty"`
+	PropertiesChildren          []int `json:"propertiesChildren,omitempty"`
+
+	MultipleOf       *string `json:"multipleOf,omitempty"`
+	Maximum          *string `json:"maximum,omitempty"`
+	ExclusiveMaximum *string `json:"exclusiveMaximum,omitempty"`
+	Minimum          *string `json:"minimum,omitempty"`
+	ExclusiveMinimum *string `json:"exclusiveMinimum,omitempty"`
+
+	MinLength *int    `json:"minLength,omitempty"`
+	MaxLength *int    `json:"maxLength,omitempty"`
+	Pattern   *string `json:"pattern,omitempty"`
+	Format    string  `json:"format,omitempty"`
+
+	MinProperties        *int                     `json:"minProperties,omitempty"`
+	MaxProperties        *int                     `json:"maxProperties,omitempty"`
+	Required             []string                 `json:"required,omitempty"`
+	Dependencies         map[string]serializedRef `json:"dependencies,omitempty"`
+	AdditionalProperties *serializedRef           `json:"additionalProperties,omitempty"`
+	PatternProperties    map[string]int           `json:"patternProperties,omitempty"`
+	PropertyNames        *int                     `json:"propertyNames,omitempty"`
+
+	MinItems        *int           `json:"minItems,omitempty"`
+	MaxItems        *int           `json:"maxItems,omitempty"`
+	UniqueItems     bool           `json:"uniqueItems,omitempty"`
+	Contains        *int           `json:"contains,omitempty"`
+	AdditionalItems *serializedRef `json:"additionalItems,omitempty"`
+
+	Const *string  `json:"const,omitempty"`
+	Enum  []string `json:"enum,omitempty"`
+
+	OneOf []int `json:"oneOf,omitempty"`
+	AnyOf []int `json:"anyOf,omitempty"`
+	AllOf []int `json:"allOf,omitempty"`
+	Not   *int  `json:"not,omitempty"`
+	If    *int  `json:"if,omitempty"`
+	Then  *int  `json:"then,omitempty"`
+	Else  *int  `json:"else,omitempty"`
+}
+
+// MarshalBinary serializes the compiled schema, so it can be loaded again with UnmarshalBinary
+// without parsing its documents, resolving references or accessing the network.
+// The documents the schema was compiled from are included, so SubSchema keeps working
+func (d *Schema) MarshalBinary() ([]byte, error) {
+	d.referencePool.lock.Lock()
+	defer d.referencePool.lock.Unlock()
+
+	s := &schemaSerializer{indexes: make(map[*subSchema]int)}
+	s.add(d.rootSchema)
+	for _, sch := range d.referencePool.documents {
+		s.add(sch)
+	}
+
+	out := serializedSchema{
+		Version:              SerializationVersion,
+		DocumentReference:    d.documentReference.String(),
+		Root:                 s.indexes[d.rootSchema],
+		References:           make(map[string]int),
+		Documents:            make(map[string]serializedDocument),
+		MaxDepth:             d.maxDepth,
+		Limits:               d.limits,
+		ArrayWorkers:         d.arrayWorkers,
+		ArrayWorkersMinItems: d.arrayWorkersMinItems,
+	}
+	for ref, sch := range d.referencePool.documents {
+		out.References[ref] = s.indexes[sch]
+	}
+	for _, sch := range s.subSchemas {
+		out.SubSchemas = append(out.SubSchemas, s.serialize(sch))
+	}
+
+	// Documents of the private pool take precedence over those of its parents
+	for p := d.pool; p != nil; p = p.parent {
+		p.lock.RLock()
+		for ref, spd := range p.schemaPoolDocuments {
+			if _, ok := out.Documents[ref]; !ok {
+				out.Documents[ref] = serializedDocument{Document: spd.Document, Draft: spd.Draft}
+			}
+		}
+		p.lock.RUnlock()
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalBinary loads a schema serialized by MarshalBinary into d.
+// It fails with ErrIncompatibleSerialization if data was written in another SerializationVersion
+func (d *Schema) UnmarshalBinary(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var in serializedSchema
+	if err := decoder.Decode(&in); err != nil {
+		return err
+	}
+	if in.Version != SerializationVersion {
+		return fmt.Errorf("%w: %d, expected %d", ErrIncompatibleSerialization, in.Version, SerializationVersion)
+	}
+
+	documentReference, err := gojsonreference.NewJsonReference(in.DocumentReference)
+	if err != nil {
+		return err
+	}
+
+	u := &schemaUnserializer{subSchemas: make([]*subSchema, len(in.SubSchemas))}
+	for i := range u.subSchemas {
+		u.subSchemas[i] = &subSchema{}
+	}
+	for i := range in.SubSchemas {
+		if err := u.unserialize(&in.SubSchemas[i], u.subSchemas[i]); err != nil {
+			return err
+		}
+	}
+
+	autoDetect := true
+	pool := newSchemaPool(&autoDetect)
+	for ref, document := range in.Documents {
+		pool.schemaPoolDocuments[ref] = &schemaPoolDocument{Document: document.Document, Draft: document.Draft}
+	}
+
+	referencePool := newSchemaReferencePool()
+	for ref, i := range in.References {
+		sch, err := u.get(&i)
+		if err != nil {
+			return err
+		}
+		referencePool.documents[ref] = sch
+	}
+
+	root, err := u.get(&in.Root)
+	if err != nil {
+		return err
+	}
+
+	loaded := Schema{
+		documentReference:    documentReference,
+		rootSchema:           root,
+		pool:                 pool,
+		loaderFactory:        &DefaultJSONLoaderFactory{},
+		referencePool:        referencePool,
+		maxDepth:             in.MaxDepth,
+		limits:               in.Limits,
+		arrayWorkers:         in.ArrayWorkers,
+		arrayWorkersMinItems: in.ArrayWorkersMinItems,
+	}
+	// data may not have been written by MarshalBinary, it gets the checks of a compiled schema
+	if err := loaded.checkReferenceCycles(); err != nil {
+		return err
+	}
+	*d = loaded
+	return nil
+}
+
+// schemaSerializer numbers every subSchema reachable from the ones added to it
+type schemaSerializer struct {
+	indexes    map[*subSchema]int
+	subSchemas []*subSchema
+}
+
+func (s *schemaSerializer) add(sch *subSchema) {
+	if sch == nil {
+		return
+	}
+	if _, ok := s.indexes[sch]; ok {
+		return
+	}
+	s.indexes[sch] = len(s.subSchemas)
+	s.subSchemas = append(s.subSchemas, sch)
+
+	s.add(sch.parent)
+	for _, child := range sch.sameInstanceChildren() {
+		s.add(child)
+	}
+	for _, child := range sch.childInstanceChildren() {
+		s.add(child)
+	}
+}
+
+func (s *schemaSerializer) index(sch *subSchema) *int {
+	if sch == nil {
+		return nil
+	}
+	i := s.indexes[sch]
+	return &i
+}
+
+func (s *schemaSerializer) indexList(list []*subSchema) []int {
+	if list == nil {
+		return nil
+	}
+	indexes := make([]int, len(list))
+	for i, sch := range list {
+		indexes[i] = *s.index(sch)
+	}
+	return indexes
+}
+
+// boolOrSchema serializes additionalProperties, additionalItems and dependencies
+func (s *schemaSerializer) boolOrSchema(v interface{}) *serializedRef {
+	switch v := v.(type) {
+	case bool:
+		return &serializedRef{Bool: &v}
+	case *subSchema:
+		return &serializedRef{SubSchema: s.index(v)}
+	case []string:
+		return &serializedRef{Properties: v}
+	}
+	return nil
+}
+
+func (s *schemaSerializer) serialize(sch *subSchema) serializedSubSchema {
+	out := serializedSubSchema{
+		Draft:       sch.draft,
+		ID:          referenceString(sch.id),
+		Title:       sch.title,
+		Description: sch.description,
+		Property:    sch.property,
+		Pass:        sch.pass,
+		Types:       sch.types.types,
+		Ref:         referenceString(sch.ref),
+		RefSchema:   s.index(sch.refSchema),
+
+		Parent:                      s.index(sch.parent),
+		ItemsChildren:               s.indexList(sch.itemsChildren),
+		ItemsChildrenIsSingleSchema: sch.itemsChildrenIsSingleSchema,
+		PropertiesChildren:          s.indexList(sch.propertiesChildren),
+
+		MultipleOf:       ratString(sch.multipleOf),
+		Maximum:          ratString(sch.maximum),
+		ExclusiveMaximum: ratString(sch.exclusiveMaximum),
+		Minimum:          ratString(sch.minimum),
+		ExclusiveMinimum: ratString(sch.exclusiveMinimum),
+
+		MinLength: sch.minLength,
+		MaxLength: sch.maxLength,
+		Format:    sch.format,
+
+		MinProperties:        sch.minProperties,
+		MaxProperties:        sch.maxProperties,
+		Required:             sch.required,
+		AdditionalProperties: s.boolOrSchema(sch.additionalProperties),
+		PropertyNames:        s.index(sch.propertyNames),
+
+		MinItems:        sch.minItems,
+		MaxItems:        sch.maxItems,
+		UniqueItems:     sch.uniqueItems,
+		Contains:        s.index(sch.contains),
+		AdditionalItems: s.boolOrSchema(sch.additionalItems),
+
+		Const: sch._const,
+		Enum:  sch.enum,
+
+		OneOf: s.indexList(sch.oneOf),
+		AnyOf: s.indexList(sch.anyOf),
+		AllOf: s.indexList(sch.allOf),
+		Not:   s.index(sch.not),
+		If:    s.index(sch._if),
+		Then:  s.index(sch._then),
+		Else:  s.index(sch._else),
+	}
+
+	if sch.pattern != nil {
+		pattern := sch.pattern.String()
+		out.Pattern = &pattern
+	}
+	if sch.dependencies != nil {
+		out.Dependencies = make(map[string]serializedRef, len(sch.dependencies))
+		for k, v := range sch.dependencies {
+			out.Dependencies[k] = *s.boolOrSchema(v)
+		}
+	}
+	if sch.patternProperties != nil {
+		out.PatternProperties = make(map[string]int, len(sch.patternProperties))
+		for k, v := range sch.patternProperties {
+			out.PatternProperties[k] = *s.index(v)
+		}
+	}
+
+	return out
+}
+
+func referenceString(ref *gojsonreference.JsonReference) *string {
+	if ref == nil {
+		return nil
+	}
+	s := ref.String()
+	return &s
+}
+
+func ratString(r *big.Rat) *string {
+	if r == nil {
+		return nil
+	}
+	s := r.RatString()
+	return &s
+}
+
+// schemaUnserializer rebuilds the subSchemas of a serializedSchema
+type schemaUnserializer struct {
+	subSchemas []*subSchema
+}
+
+func (u *schemaUnserializer) get(i *int) (*subSchema, error) {
+	if i == nil {
+		return nil, nil
+	}
+	if *i < 0 || *i >= len(u.subSchemas) {
+		return nil, fmt.Errorf("serialized schema refers to unknown subschema %d", *i)
+	}
+	return u.subSchemas[*i], nil
+}
+
+func (u *schemaUnserializer) getList(indexes []int) ([]*subSchema, error) {
+	if indexes == nil {
+		return nil, nil
+	}
+	list := make([]*subSchema, len(indexes))
+	for i := range indexes {
+		sch, err := u.get(&indexes[i])
+		if err != nil {
+			return nil, err
+		}
+		list[i] = sch
+	}
+	return list, nil
+}
+
+func (u *schemaUnserializer) boolOrSchema(ref *serializedRef) (interface{}, error) {
+	switch {
+	case ref == nil:
+		return nil, nil
+	case ref.Bool != nil:
+		return *ref.Bool, nil
+	case ref.SubSchema != nil:
+		return u.get(ref.SubSchema)
+	}
+	return ref.Properties, nil
+}
+
+func (u *schemaUnserializer) unserialize(in *serializedSubSchema, sch *subSchema) error {
+	var err error
+
+	sch.draft = in.Draft
+	sch.title = in.Title
+	sch.description = in.Description
+	sch.property = in.Property
+	sch.pass = in.Pass
+	sch.types.types = in.Types
+	sch.itemsChildrenIsSingleSchema = in.ItemsChildrenIsSingleSchema
+	sch.minLength = in.MinLength
+	sch.maxLength = in.MaxLength
+	sch.format = in.Format
+	sch.minProperties = in.MinProperties
+	sch.maxProperties = in.MaxProperties
+	sch.required = in.Required
+	sch.minItems = in.MinItems
+	sch.maxItems = in.MaxItems
+	sch.uniqueItems = in.UniqueItems
+	sch._const = in.Const
+	sch.enum = in.Enum
+
+	if sch.id, err = parseReferenceString(in.ID); err != nil {
+		return err
+	}
+	if sch.ref, err = parseReferenceString(in.Ref); err != nil {
+		return err
+	}
+
+	for _, r := range []struct {
+		in  *string
+		out **big.Rat
+	}{
+		{in.MultipleOf, &sch.multipleOf},
+		{in.Maximum, &sch.maximum},
+		{in.ExclusiveMaximum, &sch.exclusiveMaximum},
+		{in.Minimum, &sch.minimum},
+		{in.ExclusiveMinimum, &sch.exclusiveMinimum},
+	} {
+		if r.in == nil {
+			continue
+		}
+		rat, ok := new(big.Rat).SetString(*r.in)
+		if !ok {
+			return fmt.Errorf("serialized schema has an invalid number %q", *r.in)
+		}
+		*r.out = rat
+	}
+
+	if in.Pattern != nil {
+		if sch.pattern, err = regexp.Compile(*in.Pattern); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range []struct {
+		in  *int
+		out **subSchema
+	}{
+		{in.RefSchema, &sch.refSchema},
+		{in.Parent, &sch.parent},
+		{in.PropertyNames, &sch.propertyNames},
+		{in.Contains, &sch.contains},
+		{in.Not, &sch.not},
+		{in.If, &sch._if},
+		{in.Then, &sch._then},
+		{in.Else, &sch._else},
+	} {
+		if *c.out, err = u.get(c.in); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range []struct {
+		in  []int
+		out *[]*subSchema
+	}{
+		{in.ItemsChildren, &sch.itemsChildren},
+		{in.PropertiesChildren, &sch.propertiesChildren},
+		{in.OneOf, &sch.oneOf},
+		{in.AnyOf, &sch.anyOf},
+		{in.AllOf, &sch.allOf},
+	} {
+		if *c.out, err = u.getList(c.in); err != nil {
+			return err
+		}
+	}
+
+	if sch.additionalProperties, err = u.boolOrSchema(in.AdditionalProperties); err != nil {
+		return err
+	}
+	if sch.additionalItems, err = u.boolOrSchema(in.AdditionalItems); err != nil {
+		return err
+	}
+
+	if in.Dependencies != nil {
+		sch.dependencies = make(map[string]interface{}, len(in.Dependencies))
+		for k := range in.Dependencies {
+			ref := in.Dependencies[k]
+			if sch.dependencies[k], err = u.boolOrSchema(&ref); err != nil {
+				return err
+			}
+		}
+	}
+	if in.PatternProperties != nil {
+		sch.patternProperties = make(map[string]*subSchema, len(in.PatternProperties))
+		for k := range in.PatternProperties {
+			i := in.PatternProperties[k]
+			if sch.patternProperties[k], err = u.get(&i); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseReferenceString(s *string) (*gojsonreference.JsonReference, error) {
+	if s == nil {
+		return nil, nil
+	}
+	ref, err := gojsonreference.NewJsonReference(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &ref, nil
+}
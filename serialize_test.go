@@ -0,0 +1,137 @@
+package gojsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkSerializedResult validates document with a serialized and loaded copy of schema
+// and compares the result with expected, it is called for every case of the test suite
+func checkSerializedResult(t *testing.T, schema *Schema, document interface{}, expected *Result) {
+	data, err := schema.MarshalBinary()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var loaded Schema
+	if !assert.Nil(t, loaded.UnmarshalBinary(data)) {
+		return
+	}
+	result, err := loaded.Validate(NewGoLoader(document))
+	if assert.Nil(t, err) {
+		assert.Equal(t, errorStrings(expected), errorStrings(result))
+	}
+}
+
+func TestSerializeSchema(t *testing.T) {
+	sl := NewSchemaLoader()
+	sl.MaxDepth = 50
+	err := sl.AddSchema("http://localhost:1234/serialize/name.json", NewStringLoader(`{"type" : "string", "pattern" : "^[A-Z]"}`))
+	assert.Nil(t, err)
+
+	schema, err := sl.Compile(NewStringLoader(`{
+		"$schema" : "http://json-schema.org/draft-07/schema#",
+		"definitions" : {
+			"node" : {
+				"type" : "object",
+				"properties" : {
+					"name" : { "$ref" : "http://localhost:1234/serialize/name.json" },
+					"value" : { "type" : "number", "multipleOf" : 0.1, "exclusiveMaximum" : 100 },
+					"children" : { "type" : "array", "items" : { "$ref" : "#/definitions/node" } }
+				},
+				"patternProperties" : { "^x-" : { "type" : "boolean" } },
+				"additionalProperties" : false,
+				"dependencies" : { "value" : ["name"], "children" : { "required" : ["name"] } },
+				"if" : { "required" : ["value"] },
+				"then" : { "properties" : { "value" : { "minimum" : 0 } } }
+			},
+			"unused" : { "enum" : [1, 2] }
+		},
+		"$ref" : "#/definitions/node"
+	}`))
+	assert.Nil(t, err)
+
+	data, err := schema.MarshalBinary()
+	assert.Nil(t, err)
+
+	var loaded Schema
+	assert.Nil(t, loaded.UnmarshalBinary(data))
+	assert.Equal(t, 50, loaded.maxDepth)
+
+	for _, document := range []string{
+		`{"name" : "Root", "value" : 1.5, "children" : [{"name" : "Child", "x-flag" : true}]}`,
+		`{"name" : "root", "value" : -1.55, "children" : [{"value" : 3}, {"x-flag" : 1, "other" : 1}]}`,
+		`{"children" : [{"children" : [{"children" : [{"name" : 1}]}]}]}`,
+	} {
+		expected, err := schema.Validate(NewStringLoader(document))
+		assert.Nil(t, err)
+		result, err := loaded.Validate(NewStringLoader(document))
+		assert.Nil(t, err)
+		assert.Equal(t, expected.Valid(), result.Valid(), document)
+		assert.Equal(t, errorStrings(expected), errorStrings(result), document)
+	}
+
+	// The documents are included, so subschemas that were not compiled yet can be used offline
+	unused, err := loaded.SubSchema("#/definitions/unused")
+	if assert.Nil(t, err) {
+		result, err := unused.Validate(NewStringLoader(`3`))
+		assert.Nil(t, err)
+		assert.False(t, result.Valid())
+	}
+}
+
+func TestSerializeSchemaVersion(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{"type" : "string"}`))
+	assert.Nil(t, err)
+	data, err := schema.MarshalBinary()
+	assert.Nil(t, err)
+
+	data = []byte(strings.Replace(string(data), `"version":1`, `"version":0`, 1))
+	var loaded Schema
+	err = loaded.UnmarshalBinary(data)
+	assert.True(t, errors.Is(err, ErrIncompatibleSerialization), "%v", err)
+}
+
+// A serialized schema that NewSchema would have rejected is rejected as well
+func TestUnserializeReferenceCycle(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{"allOf" : [{"type" : "string"}]}`))
+	assert.Nil(t, err)
+	data, err := schema.MarshalBinary()
+	assert.Nil(t, err)
+
+	var serialized serializedSchema
+	assert.Nil(t, json.Unmarshal(data, &serialized))
+	root := serialized.SubSchemas[serialized.Root]
+	serialized.SubSchemas[root.AllOf[0]].RefSchema = &serialized.Root
+	data, err = json.Marshal(serialized)
+	assert.Nil(t, err)
+
+	var loaded Schema
+	err = loaded.UnmarshalBinary(data)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "recurses into itself infinitely")
+	}
+	assert.Nil(t, loaded.rootSchema)
+}
+
+// Every field of subSchema must be serialized. A new field must be added to serializedSubSchema,
+// and SerializationVersion changed, as the schemas serialized before can't be read correctly anymore
+func TestSerializeSubSchemaFields(t *testing.T) {
+	serialized := reflect.TypeOf(serializedSubSchema{})
+	fields := reflect.TypeOf(subSchema{})
+	for i := 0; i < fields.NumField(); i++ {
+		name := strings.TrimPrefix(fields.Field(i).Name, "_")
+		if name == "id" {
+			name = "ID"
+		} else {
+			name = strings.ToUpper(name[:1]) + name[1:]
+		}
+		_, ok := serialized.FieldByName(name)
+		assert.True(t, ok, "subSchema.%s is not serialized", fields.Field(i).Name)
+	}
+	assert.Equal(t, fields.NumField(), serialized.NumField())
+}
@@ -0,0 +1,454 @@
+package gojsonschema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+// errStreamStopped is returned internally once the validation has been cancelled
+var errStreamStopped = errors.New("stream validation stopped")
+
+// ValidateStream validates the JSON document read from r without decoding it as a whole
+func (v *Schema) ValidateStream(r io.Reader) (*Result, error) {
+	return v.ValidateStreamContext(context.Background(), r)
+}
+
+// ValidateStreamContext validates the JSON document read from r as it is read, aborting as soon as ctx is done.
+//
+// Arrays and objects are only decoded in full when their subschema needs the whole value,
+// e.g. for enum, const, uniqueItems, contains or a combinator such as anyOf. Everything else is
+// validated token by token, so a huge array of objects only ever holds a single item in memory.
+//
+// A streamed document produces the same errors as Validate, though possibly in a different order.
+// Errors about a streamed array or object are reported without its value.
+// Every occurrence of a key found more than once in an object is validated, whereas Validate
+// only sees the last one, as encoding/json keeps the last value.
+// A syntax error in the document is returned as error, along with the errors found up to that point.
+// A validation cancelled by ctx returns a nil Result along with ctx.Err(), as ValidateContext does
+func (v *Schema) ValidateStreamContext(ctx context.Context, r io.Reader) (*Result, error) {
+	decoder := json.NewDecoder(limitInput(r, v.limits))
+	decoder.UseNumber()
+
+	s := &streamValidator{
+		validator: newValidator(ctx, v),
+		decoder:   &limitedDecoder{decoder: decoder, limits: v.limits},
+	}
+	// Items are never held in memory at once, so they cannot be spread over goroutines
+	s.arrayWorkers = 0
+
+	result := &Result{}
+	err := s.streamValue(v.rootSchema, result, NewJsonContext(STRING_CONTEXT_ROOT, nil), 0)
+	if err == errStreamStopped {
+		if err = s.err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.depthError != nil {
+		result.errors = append(result.errors, s.depthError)
+	}
+	return result, err
+}
+
+// streamValidator validates a document while it is being decoded
+type streamValidator struct {
+	*validator
+	decoder *limitedDecoder
+}
+
+// streamValue validates the next value of the decoder against currentSubSchema.
+// A nil currentSubSchema accepts anything, the value is read and discarded
+func (s *streamValidator) streamValue(currentSubSchema *subSchema, result *Result, context *JsonContext, depth int) error {
+	if s.cancelled() {
+		return errStreamStopped
+	}
+
+	// Every $ref goes one level deeper, as in validateRecursive
+	refs := 0
+	defer func() { s.depth -= refs }()
+	for currentSubSchema != nil && currentSubSchema.pass == nil && currentSubSchema.refSchema != nil {
+		if !s.enter(context) {
+			return errStreamStopped
+		}
+		refs++
+		currentSubSchema = currentSubSchema.refSchema
+	}
+	if currentSubSchema != nil && currentSubSchema.pass != nil && *currentSubSchema.pass {
+		currentSubSchema = nil
+	}
+
+	if currentSubSchema != nil && !currentSubSchema.streamable() {
+		value, err := s.decoder.decodeValue(context, depth)
+		if err != nil {
+			return err
+		}
+		s.validateRecursive(currentSubSchema, value, result, context)
+		return nil
+	}
+
+	token, err := s.decoder.decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if err := s.decoder.limits.checkDepth(depth+1, context); err != nil {
+			return err
+		}
+		if t == '{' {
+			return s.streamObject(currentSubSchema, result, context, depth+1)
+		}
+		return s.streamArray(currentSubSchema, result, context, depth+1)
+	case string:
+		err = s.decoder.limits.checkString(t, context)
+	case json.Number:
+		err = s.decoder.limits.checkNumber(t, context)
+	}
+	if err != nil {
+		return err
+	}
+
+	if currentSubSchema != nil {
+		s.validateRecursive(currentSubSchema, token, result, context)
+	}
+	return nil
+}
+
+// enter is the depth guard of validateRecursive for $refs and streamed arrays and objects.
+// The depth is only left incremented when enter succeeds
+func (s *streamValidator) enter(context *JsonContext) bool {
+	s.depth++
+	if s.maxDepth > 0 && s.depth > s.maxDepth {
+		s.depthError = new(MaxDepthError)
+		newError(s.depthError, context, nil, Locale, ErrorDetails{"max": s.maxDepth})
+		s.depth--
+		return false
+	}
+	return true
+}
+
+// checkType reports whether a streamed array or object may be validated against currentSubSchema
+func (s *streamValidator) checkType(currentSubSchema *subSchema, given string, result *Result, context *JsonContext) bool {
+	if currentSubSchema.types.IsTyped() && !currentSubSchema.types.Contains(given) {
+		result.addInternalError(
+			new(InvalidTypeError),
+			context,
+			nil,
+			ErrorDetails{
+				"expected": currentSubSchema.types.String(),
+				"given":    given,
+			},
+		)
+		return false
+	}
+	return true
+}
+
+func (s *streamValidator) streamArray(currentSubSchema *subSchema, result *Result, context *JsonContext, depth int) error {
+	if currentSubSchema != nil {
+		if !s.enter(context) {
+			return errStreamStopped
+		}
+		defer func() { s.depth-- }()
+
+		if !s.checkType(currentSubSchema, TYPE_ARRAY, result, context) {
+			currentSubSchema = nil
+		}
+	}
+
+	nbValues := 0
+	additionalItemsNotAllowed := false
+
+	for s.decoder.decoder.More() {
+		if err := s.decoder.limits.checkArrayLength(nbValues+1, context); err != nil {
+			return err
+		}
+
+		var itemSchema *subSchema
+		if currentSubSchema != nil {
+			var allowed bool
+			itemSchema, allowed = currentSubSchema.itemSchema(nbValues)
+			additionalItemsNotAllowed = additionalItemsNotAllowed || !allowed
+		}
+
+		validationResult := &Result{}
+		err := s.streamValue(itemSchema, validationResult, NewJsonContext(strconv.Itoa(nbValues), context), depth)
+		result.mergeErrors(validationResult)
+		if err != nil {
+			return err
+		}
+		nbValues++
+	}
+
+	// closing ']'
+	if _, err := s.decoder.decoder.Token(); err != nil {
+		return err
+	}
+
+	if currentSubSchema == nil {
+		return nil
+	}
+
+	// validateSchema
+	result.incrementScore()
+
+	if additionalItemsNotAllowed {
+		result.addInternalError(new(ArrayNoAdditionalItemsError), context, nil, ErrorDetails{})
+	}
+
+	if currentSubSchema.minItems != nil && nbValues < int(*currentSubSchema.minItems) {
+		result.addInternalError(
+			new(ArrayMinItemsError),
+			context,
+			nil,
+			ErrorDetails{"min": *currentSubSchema.minItems},
+		)
+	}
+	if currentSubSchema.maxItems != nil && nbValues > int(*currentSubSchema.maxItems) {
+		result.addInternalError(
+			new(ArrayMaxItemsError),
+			context,
+			nil,
+			ErrorDetails{"max": *currentSubSchema.maxItems},
+		)
+	}
+
+	result.score += containerScore
+	return nil
+}
+
+func (s *streamValidator) streamObject(currentSubSchema *subSchema, result *Result, context *JsonContext, depth int) error {
+	if currentSubSchema != nil {
+		if !s.enter(context) {
+			return errStreamStopped
+		}
+		defer func() { s.depth-- }()
+
+		if !s.checkType(currentSubSchema, TYPE_OBJECT, result, context) {
+			currentSubSchema = nil
+		}
+	}
+
+	// Errors of the properties are reported after those of the object itself, as Validate does
+	propertiesResult := &Result{}
+	var keys []string
+	seen := make(map[string]bool)
+
+	for s.decoder.decoder.More() {
+		token, err := s.decoder.decoder.Token()
+		if err != nil {
+			return err
+		}
+		key := token.(string)
+		subContext := NewJsonContext(key, context)
+		if err := s.decoder.limits.checkString(key, subContext); err != nil {
+			return err
+		}
+
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+			if err := s.decoder.limits.checkObjectSize(len(keys), context); err != nil {
+				return err
+			}
+		}
+
+		if currentSubSchema == nil {
+			if err := s.streamValue(nil, propertiesResult, subContext, depth); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.streamProperty(currentSubSchema, key, propertiesResult, context, depth); err != nil {
+			result.mergeErrors(propertiesResult)
+			return err
+		}
+	}
+
+	// closing '}'
+	if _, err := s.decoder.decoder.Token(); err != nil {
+		return err
+	}
+
+	if currentSubSchema == nil {
+		return nil
+	}
+
+	// dependencies, streamable subschemas only have property dependencies
+	for _, key := range keys {
+		if dependency, ok := currentSubSchema.dependencies[key].([]string); ok {
+			for _, dependOnKey := range dependency {
+				if !seen[dependOnKey] {
+					result.addInternalError(
+						new(MissingDependencyError),
+						context,
+						nil,
+						ErrorDetails{"dependency": dependOnKey},
+					)
+				}
+			}
+		}
+	}
+	result.incrementScore()
+
+	if currentSubSchema.minProperties != nil && len(keys) < int(*currentSubSchema.minProperties) {
+		result.addInternalError(
+			new(ArrayMinPropertiesError),
+			context,
+			nil,
+			ErrorDetails{"min": *currentSubSchema.minProperties},
+		)
+	}
+	if currentSubSchema.maxProperties != nil && len(keys) > int(*currentSubSchema.maxProperties) {
+		result.addInternalError(
+			new(ArrayMaxPropertiesError),
+			context,
+			nil,
+			ErrorDetails{"max": *currentSubSchema.maxProperties},
+		)
+	}
+
+	for _, requiredProperty := range currentSubSchema.required {
+		if seen[requiredProperty] {
+			result.incrementScore()
+		} else {
+			result.addInternalError(
+				new(RequiredError),
+				context,
+				nil,
+				ErrorDetails{"property": requiredProperty},
+			)
+		}
+	}
+
+	result.mergeErrors(propertiesResult)
+
+	result.score += containerScore
+	return nil
+}
+
+// streamProperty validates the value of property key against every subschema that applies to it.
+// The value is only decoded in full when more than one subschema applies
+func (s *streamValidator) streamProperty(currentSubSchema *subSchema, key string, result *Result, context *JsonContext, depth int) error {
+	subContext := NewJsonContext(key, context)
+
+	if currentSubSchema.propertyNames != nil {
+		validationResult := s.subValidateWithContext(currentSubSchema.propertyNames, key, context)
+		if !validationResult.Valid() {
+			result.addInternalError(new(InvalidPropertyNameError),
+				context,
+				nil, ErrorDetails{
+					"property": key,
+				})
+			result.mergeErrors(validationResult)
+		}
+	}
+
+	var schemas []*subSchema
+	for _, pSchema := range currentSubSchema.propertiesChildren {
+		if pSchema.property == key {
+			schemas = append(schemas, pSchema)
+		}
+	}
+	found := len(schemas) > 0
+
+	ppMatch := false
+	for pk, pv := range currentSubSchema.patternProperties {
+		if matches, _ := regexp.MatchString(pk, key); matches {
+			ppMatch = true
+			schemas = append(schemas, pv)
+		}
+	}
+	if ppMatch {
+		result.incrementScore()
+	}
+
+	if !found && !ppMatch {
+		switch ap := currentSubSchema.additionalProperties.(type) {
+		case bool:
+			if !ap {
+				value, err := s.decoder.decodeValue(subContext, depth)
+				if err != nil {
+					return err
+				}
+				result.addInternalError(
+					new(AdditionalPropertyNotAllowedError),
+					context,
+					value,
+					ErrorDetails{"property": key},
+				)
+				return nil
+			}
+		case *subSchema:
+			schemas = append(schemas, ap)
+		}
+	}
+
+	if len(schemas) <= 1 {
+		var schema *subSchema
+		if len(schemas) == 1 {
+			schema = schemas[0]
+		}
+		validationResult := &Result{}
+		err := s.streamValue(schema, validationResult, subContext, depth)
+		result.mergeErrors(validationResult)
+		return err
+	}
+
+	value, err := s.decoder.decodeValue(subContext, depth)
+	if err != nil {
+		return err
+	}
+	for _, schema := range schemas {
+		s.validateRecursive(schema, value, result, subContext)
+	}
+	return nil
+}
+
+// streamable reports whether arrays and objects can be validated against s without
+// decoding them in full, i.e. whether none of its keywords needs the whole value
+func (s *subSchema) streamable() bool {
+	if s.pass != nil || s.refSchema != nil {
+		return false
+	}
+	if len(s.anyOf) > 0 || len(s.oneOf) > 0 || len(s.allOf) > 0 || s.not != nil || s._if != nil {
+		return false
+	}
+	if s._const != nil || len(s.enum) > 0 || s.format != "" || s.uniqueItems || s.contains != nil {
+		return false
+	}
+	for _, dependency := range s.dependencies {
+		if !isKind(dependency, reflect.Slice) {
+			return false
+		}
+	}
+	return true
+}
+
+// itemSchema returns the subschema of the item at index i of an array, nil if there is none.
+// allowed is false if the item is forbidden by "additionalItems": false
+func (s *subSchema) itemSchema(i int) (itemSchema *subSchema, allowed bool) {
+	if s.itemsChildrenIsSingleSchema {
+		return s.itemsChildren[0], true
+	}
+	if len(s.itemsChildren) == 0 {
+		return nil, true
+	}
+	if i < len(s.itemsChildren) {
+		return s.itemsChildren[i], true
+	}
+	switch additionalItems := s.additionalItems.(type) {
+	case bool:
+		return nil, additionalItems
+	case *subSchema:
+		return additionalItems, true
+	}
+	return nil, true
+}
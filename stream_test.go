@@ -0,0 +1,195 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// errorStrings returns the errors of result in a canonical order
+func errorStrings(result *Result) []string {
+	var errors []string
+	for _, err := range result.Errors() {
+		errors = append(errors, err.Type()+" "+err.String())
+	}
+	sort.Strings(errors)
+	return errors
+}
+
+// checkStreamResult validates document with ValidateStream and compares the result with expected,
+// it is called for every case of the test suite
+func checkStreamResult(t *testing.T, schema *Schema, document interface{}, expected *Result) {
+	data, err := json.Marshal(document)
+	if !assert.Nil(t, err) {
+		return
+	}
+	result, err := schema.ValidateStream(bytes.NewReader(data))
+	if assert.Nil(t, err, "%s", data) {
+		assert.Equal(t, errorStrings(expected), errorStrings(result), "%s", data)
+	}
+}
+
+func TestValidateStream(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"type" : "object",
+		"properties" : {
+			"items" : {
+				"type" : "array",
+				"items" : {
+					"type" : "object",
+					"properties" : {
+						"id" : { "type" : "integer", "minimum" : 0 },
+						"kind" : { "enum" : ["a", "b"] },
+						"tags" : { "type" : "array", "uniqueItems" : true }
+					},
+					"required" : ["id", "kind"],
+					"additionalProperties" : false
+				},
+				"maxItems" : 3
+			}
+		},
+		"required" : ["items"]
+	}`))
+	assert.Nil(t, err)
+
+	document := `{"items" : [
+		{"id" : 1, "kind" : "a", "tags" : ["x", "y"]},
+		{"id" : -1, "kind" : "c", "tags" : ["x", "x"]},
+		{"kind" : "b", "extra" : {"deep" : [1, 2, 3]}},
+		{"id" : 4, "kind" : "a"}
+	]}`
+
+	expected, err := schema.Validate(NewStringLoader(document))
+	assert.Nil(t, err)
+	result, err := schema.ValidateStream(strings.NewReader(document))
+	assert.Nil(t, err)
+	assert.False(t, result.Valid())
+	assert.Equal(t, errorStrings(expected), errorStrings(result))
+
+	_, err = schema.ValidateStream(strings.NewReader(`{"items" : [{"id" : 1,`))
+	assert.NotNil(t, err)
+}
+
+// generatedArray is a reader producing an array of items objects, the last one having a negative id
+type generatedArray struct {
+	items int
+	next  int
+	buf   bytes.Buffer
+}
+
+func (g *generatedArray) Read(p []byte) (int, error) {
+	for g.buf.Len() < len(p) && g.next <= g.items {
+		switch {
+		case g.next == 0:
+			g.buf.WriteString("[")
+		case g.next == g.items:
+			g.buf.WriteString(`{"id" : -1}]`)
+		default:
+			fmt.Fprintf(&g.buf, `{"id" : %d, "name" : "item"},`, g.next)
+		}
+		g.next++
+	}
+	if g.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return g.buf.Read(p)
+}
+
+func TestValidateStreamLargeArray(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"items" : {
+			"properties" : { "id" : { "minimum" : 0 }, "name" : { "enum" : ["item"] } }
+		}
+	}`))
+	assert.Nil(t, err)
+
+	result, err := schema.ValidateStream(&generatedArray{items: 200000})
+	assert.Nil(t, err)
+	if assert.Len(t, result.Errors(), 1) {
+		assert.Equal(t, "(root).199999.id", result.Errors()[0].Context().String())
+	}
+}
+
+func TestValidateStreamLimitsAndCancel(t *testing.T) {
+	sl := NewSchemaLoader()
+	sl.Limits = Limits{MaxArrayLength: 10}
+	schema, err := sl.Compile(NewStringLoader(`{}`))
+	assert.Nil(t, err)
+
+	_, err = schema.ValidateStream(&generatedArray{items: 100})
+	assert.IsType(t, &LimitError{}, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	schema, err = NewSchema(NewStringLoader(`{"items" : {}}`))
+	assert.Nil(t, err)
+	result, err := schema.ValidateStreamContext(ctx, &generatedArray{items: 100})
+	assert.Equal(t, context.Canceled, err)
+	assert.Nil(t, result)
+}
+
+func TestValidateStreamScore(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{
+		"type" : "object",
+		"properties" : { "a" : { "type" : "array", "items" : { "minimum" : 0 } } },
+		"required" : ["a"]
+	}`))
+	assert.Nil(t, err)
+
+	// The score ranks the branches of oneOf and anyOf, it must not depend on the API
+	for _, document := range []string{`{"a" : [1, 2]}`, `{"a" : [-1, {}], "b" : null}`, `{}`} {
+		expected, err := schema.Validate(NewStringLoader(document))
+		assert.Nil(t, err)
+		result, err := schema.ValidateStream(strings.NewReader(document))
+		assert.Nil(t, err)
+		assert.Equal(t, expected.score, result.score, document)
+	}
+}
+
+func TestValidateStreamRefMaxDepth(t *testing.T) {
+	sl := NewSchemaLoader()
+	sl.MaxDepth = 12
+	schema, err := sl.Compile(NewStringLoader(`{
+		"$ref" : "#/definitions/a",
+		"definitions" : {
+			"a" : { "$ref" : "#/definitions/b" },
+			"b" : { "items" : { "$ref" : "#/definitions/a" } }
+		}
+	}`))
+	assert.Nil(t, err)
+
+	// Every level of the document takes three levels of schemas, two of them being $refs
+	for nesting := 1; nesting <= 6; nesting++ {
+		document := strings.Repeat("[", nesting) + strings.Repeat("]", nesting)
+		expected, err := schema.Validate(NewStringLoader(document))
+		assert.Nil(t, err)
+		result, err := schema.ValidateStream(strings.NewReader(document))
+		assert.Nil(t, err)
+		assert.Equal(t, expected.Valid(), result.Valid(), document)
+		assert.Equal(t, errorStrings(expected), errorStrings(result), document)
+	}
+}
+
+func TestValidateStreamDuplicateKeys(t *testing.T) {
+	schema, err := NewSchema(NewStringLoader(`{"properties" : {"a" : {"type" : "integer"}}}`))
+	assert.Nil(t, err)
+
+	// Validate only sees the last value of a, the stream validator sees both
+	document := `{"a" : "x", "a" : 1}`
+	result, err := schema.Validate(NewStringLoader(document))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid())
+
+	result, err = schema.ValidateStream(strings.NewReader(document))
+	assert.Nil(t, err)
+	if assert.Len(t, result.Errors(), 1) {
+		assert.Equal(t, "(root).a", result.Errors()[0].Context().String())
+	}
+}
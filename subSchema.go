@@ -40,6 +40,8 @@ const (
 	KEY_REF                   = "$ref"
 	KEY_TITLE                 = "title"
 	KEY_DESCRIPTION           = "description"
+	KEY_DEFAULT               = "default"
+	KEY_EXAMPLES              = "examples"
 	KEY_TYPE                  = "type"
 	KEY_ITEMS                 = "items"
 	KEY_ADDITIONAL_ITEMS      = "additionalItems"
@@ -147,3 +149,52 @@ type subSchema struct {
 	_then *subSchema
 	_else *subSchema
 }
+
+// sameInstanceChildren returns the subSchemas that are applied to the same instance as v
+func (v *subSchema) sameInstanceChildren() []*subSchema {
+	var children []*subSchema
+
+	if v.refSchema != nil {
+		children = append(children, v.refSchema)
+	}
+	children = append(children, v.allOf...)
+	children = append(children, v.anyOf...)
+	children = append(children, v.oneOf...)
+	for _, s := range []*subSchema{v.not, v._if, v._then, v._else} {
+		if s != nil {
+			children = append(children, s)
+		}
+	}
+	for _, dependency := range v.dependencies {
+		if s, ok := dependency.(*subSchema); ok {
+			children = append(children, s)
+		}
+	}
+
+	return children
+}
+
+// childInstanceChildren returns the subSchemas that are applied to the children
+// (properties, items, property names) of the instance v is applied to
+func (v *subSchema) childInstanceChildren() []*subSchema {
+	var children []*subSchema
+
+	children = append(children, v.propertiesChildren...)
+	children = append(children, v.itemsChildren...)
+	for _, s := range v.patternProperties {
+		children = append(children, s)
+	}
+	if s, ok := v.additionalProperties.(*subSchema); ok {
+		children = append(children, s)
+	}
+	if s, ok := v.additionalItems.(*subSchema); ok {
+		children = append(children, s)
+	}
+	for _, s := range []*subSchema{v.propertyNames, v.contains} {
+		if s != nil {
+			children = append(children, s)
+		}
+	}
+
+	return children
+}
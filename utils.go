@@ -32,12 +32,15 @@ import (
 )
 
 func isKind(what interface{}, kinds ...reflect.Kind) bool {
-	target := what
+	targetKind := reflect.ValueOf(what).Kind()
 	if isJSONNumber(what) {
-		// JSON Numbers are strings!
-		target = *mustBeNumber(what)
+		// JSON Numbers are strings! Valid ones are used as a big.Rat, a struct.
+		// Only their syntax is checked, parsing one just to learn its kind can be arbitrarily expensive
+		targetKind = reflect.Invalid
+		if isValidNumber(string(what.(json.Number))) {
+			targetKind = reflect.Struct
+		}
 	}
-	targetKind := reflect.ValueOf(target).Kind()
 	for _, kind := range kinds {
 		if targetKind == kind {
 			return true
@@ -195,3 +198,22 @@ func convertDocumentNode(val interface{}) interface{} {
 
 	return val
 }
+
+// copyDocument returns a deep copy of a decoded JSON document
+func copyDocument(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			c[key] = copyDocument(item)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = copyDocument(item)
+		}
+		return c
+	}
+	return value
+}
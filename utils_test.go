@@ -27,6 +27,7 @@ package gojsonschema
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -57,3 +58,11 @@ func TestCheckJsonNumber(t *testing.T) {
 	}
 
 }
+
+func TestIsKindJsonNumber(t *testing.T) {
+	assert.True(t, isKind(json.Number("1.5"), reflect.Struct))
+	assert.True(t, isKind(json.Number("1e1000000000"), reflect.Struct))
+	assert.False(t, isKind(json.Number("number"), reflect.Struct))
+	assert.False(t, isKind(json.Number(""), reflect.Struct))
+	assert.False(t, isKind(json.Number("1"), reflect.String))
+}
@@ -26,6 +26,7 @@
 package gojsonschema
 
 import (
+	"context"
 	"encoding/json"
 	"math/big"
 	"reflect"
@@ -33,6 +34,8 @@ import (
 	"strconv"
 	"strings"
 	"unicode/utf8"
+
+	"github.com/xeipuuv/gojsonpointer"
 )
 
 // Validate loads and validates a JSON schema
@@ -47,34 +50,157 @@ func Validate(ls JSONLoader, ld JSONLoader) (*Result, error) {
 
 // Validate loads and validates a JSON document
 func (v *Schema) Validate(l JSONLoader) (*Result, error) {
-	root, err := l.LoadJSON()
+	return v.ValidateContext(context.Background(), l)
+}
+
+// ValidateContext loads and validates a JSON document, aborting as soon as ctx is done.
+// When ctx is done before the validation completes, a nil Result is returned along with ctx.Err():
+// the branches of anyOf, oneOf, not and if that were cut short can't tell whether they match
+func (v *Schema) ValidateContext(ctx context.Context, l JSONLoader) (*Result, error) {
+	root, err := loadJSONLimited(ctx, l, v.limits)
 	if err != nil {
 		return nil, err
 	}
-	return v.validateDocument(root), nil
+	return v.validateDocumentContext(ctx, root)
+}
+
+// ValidateAt loads a JSON document and validates the value at the JSON Pointer instancePointer,
+// e.g. "/customer/shipping". Errors are reported with their full path in the document
+func (v *Schema) ValidateAt(l JSONLoader, instancePointer string) (*Result, error) {
+	return v.ValidateAtContext(context.Background(), l, instancePointer)
+}
+
+// ValidateAtContext is ValidateAt aborting as soon as ctx is done, see ValidateContext
+func (v *Schema) ValidateAtContext(ctx context.Context, l JSONLoader, instancePointer string) (*Result, error) {
+	root, err := loadJSONLimited(ctx, l, v.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	pointer, err := gojsonpointer.NewJsonPointer(strings.TrimPrefix(instancePointer, "#"))
+	if err != nil {
+		return nil, err
+	}
+	node, _, err := pointer.Get(root)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonContext := NewJsonContext(STRING_CONTEXT_ROOT, nil)
+	if path := pointer.String(); path != "" {
+		for _, token := range strings.Split(path[1:], "/") {
+			token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+			jsonContext = NewJsonContext(token, jsonContext)
+		}
+	}
+
+	return v.validateNodeContext(ctx, node, jsonContext)
 }
 
 func (v *Schema) validateDocument(root interface{}) *Result {
-	result := &Result{}
-	context := NewJsonContext(STRING_CONTEXT_ROOT, nil)
-	v.rootSchema.validateRecursive(v.rootSchema, root, result, context)
+	result, _ := v.validateDocumentContext(context.Background(), root)
 	return result
 }
 
-func (v *subSchema) subValidateWithContext(document interface{}, context *JsonContext) *Result {
+func (v *Schema) validateDocumentContext(ctx context.Context, root interface{}) (*Result, error) {
+	return v.validateNodeContext(ctx, root, NewJsonContext(STRING_CONTEXT_ROOT, nil))
+}
+
+// validateNodeContext validates node, which is found at jsonContext in its document.
+// The error is ctx.Err() if the validation was cancelled before it completed, with a nil Result
+func (v *Schema) validateNodeContext(ctx context.Context, node interface{}, jsonContext *JsonContext) (*Result, error) {
 	result := &Result{}
-	v.validateRecursive(v, document, result, context)
+	validator := newValidator(ctx, v)
+	validator.validateRecursive(v.rootSchema, node, result, jsonContext)
+	if err := validator.err(); err != nil {
+		return nil, err
+	}
+
+	// The depth error may have been swallowed by anyOf or oneOf, it must always fail the validation
+	if validator.depthError != nil {
+		result.errors = append(result.errors, validator.depthError)
+	}
+	return result, nil
+}
+
+// validator holds the state of a single validation run.
+// A compiled Schema is never modified while validating, everything that is
+// specific to one call of Validate lives here instead
+type validator struct {
+	ctx context.Context
+
+	// maxDepth limits depth, the number of nested validateRecursive calls. 0 means no limit
+	maxDepth   int
+	depth      int
+	depthError ResultError
+
+	// interrupted is set when the walk stopped early because ctx was done
+	interrupted bool
+
+	// Arrays of at least arrayWorkersMinItems items are validated by arrayWorkers goroutines
+	arrayWorkers         int
+	arrayWorkersMinItems int
+}
+
+func newValidator(ctx context.Context, schema *Schema) *validator {
+	return &validator{
+		ctx:                  ctx,
+		maxDepth:             schema.maxDepth,
+		arrayWorkers:         schema.arrayWorkers,
+		arrayWorkersMinItems: schema.arrayWorkersMinItems,
+	}
+}
+
+// cancelled reports whether the validation should stop early
+func (v *validator) cancelled() bool {
+	if v.depthError != nil {
+		return true
+	}
+	select {
+	case <-v.ctx.Done():
+		v.interrupted = true
+		return true
+	default:
+		return false
+	}
+}
+
+// err returns the error of ctx if the walk was cancelled, nil if it completed
+func (v *validator) err() error {
+	if v.interrupted {
+		return v.ctx.Err()
+	}
+	return nil
+}
+
+func (v *validator) subValidateWithContext(currentSubSchema *subSchema, document interface{}, context *JsonContext) *Result {
+	result := &Result{}
+	v.validateRecursive(currentSubSchema, document, result, context)
 	return result
 }
 
 // Walker function to validate the json recursively against the subSchema
-func (v *subSchema) validateRecursive(currentSubSchema *subSchema, currentNode interface{}, result *Result, context *JsonContext) {
+func (v *validator) validateRecursive(currentSubSchema *subSchema, currentNode interface{}, result *Result, context *JsonContext) {
 
 	if internalLogEnabled {
 		internalLog("validateRecursive %s", context.String())
 		internalLog(" %v", currentNode)
 	}
 
+	// Stop walking the document once the caller has given up
+	if v.cancelled() {
+		return
+	}
+
+	v.depth++
+	defer func() { v.depth-- }()
+
+	if v.maxDepth > 0 && v.depth > v.maxDepth {
+		v.depthError = new(MaxDepthError)
+		newError(v.depthError, context, currentNode, Locale, ErrorDetails{"max": v.maxDepth})
+		return
+	}
+
 	// Handle true/false schema as early as possible as all other fields will be nil
 	if currentSubSchema.pass != nil {
 		if !*currentSubSchema.pass {
@@ -109,7 +235,7 @@ func (v *subSchema) validateRecursive(currentSubSchema *subSchema, currentNode i
 			return
 		}
 
-		currentSubSchema.validateSchema(currentSubSchema, currentNode, result, context)
+		v.validateSchema(currentSubSchema, currentNode, result, context)
 		v.validateCommon(currentSubSchema, currentNode, result, context)
 
 	} else { // Not a null value
@@ -141,7 +267,7 @@ func (v *subSchema) validateRecursive(currentSubSchema *subSchema, currentNode i
 				return
 			}
 
-			currentSubSchema.validateSchema(currentSubSchema, value, result, context)
+			v.validateSchema(currentSubSchema, value, result, context)
 			v.validateNumber(currentSubSchema, value, result, context)
 			v.validateCommon(currentSubSchema, value, result, context)
 			v.validateString(currentSubSchema, value, result, context)
@@ -172,7 +298,7 @@ func (v *subSchema) validateRecursive(currentSubSchema *subSchema, currentNode i
 
 				castCurrentNode := currentNode.([]interface{})
 
-				currentSubSchema.validateSchema(currentSubSchema, castCurrentNode, result, context)
+				v.validateSchema(currentSubSchema, castCurrentNode, result, context)
 
 				v.validateArray(currentSubSchema, castCurrentNode, result, context)
 				v.validateCommon(currentSubSchema, castCurrentNode, result, context)
@@ -198,7 +324,7 @@ func (v *subSchema) validateRecursive(currentSubSchema *subSchema, currentNode i
 					castCurrentNode = convertDocumentNode(currentNode).(map[string]interface{})
 				}
 
-				currentSubSchema.validateSchema(currentSubSchema, castCurrentNode, result, context)
+				v.validateSchema(currentSubSchema, castCurrentNode, result, context)
 
 				v.validateObject(currentSubSchema, castCurrentNode, result, context)
 				v.validateCommon(currentSubSchema, castCurrentNode, result, context)
@@ -230,7 +356,7 @@ func (v *subSchema) validateRecursive(currentSubSchema *subSchema, currentNode i
 
 				value := currentNode.(bool)
 
-				currentSubSchema.validateSchema(currentSubSchema, value, result, context)
+				v.validateSchema(currentSubSchema, value, result, context)
 				v.validateNumber(currentSubSchema, value, result, context)
 				v.validateCommon(currentSubSchema, value, result, context)
 				v.validateString(currentSubSchema, value, result, context)
@@ -252,7 +378,7 @@ func (v *subSchema) validateRecursive(currentSubSchema *subSchema, currentNode i
 
 				value := currentNode.(string)
 
-				currentSubSchema.validateSchema(currentSubSchema, value, result, context)
+				v.validateSchema(currentSubSchema, value, result, context)
 				v.validateNumber(currentSubSchema, value, result, context)
 				v.validateCommon(currentSubSchema, value, result, context)
 				v.validateString(currentSubSchema, value, result, context)
@@ -266,8 +392,12 @@ func (v *subSchema) validateRecursive(currentSubSchema *subSchema, currentNode i
 	result.incrementScore()
 }
 
+// containerScore is what validateRecursive, validateCommon and validateArray or validateObject
+// each add to the score of an array or object. The stream validator adds it at once
+const containerScore = 3
+
 // Different kinds of validation there, subSchema / common / array / object / string...
-func (v *subSchema) validateSchema(currentSubSchema *subSchema, currentNode interface{}, result *Result, context *JsonContext) {
+func (v *validator) validateSchema(currentSubSchema *subSchema, currentNode interface{}, result *Result, context *JsonContext) {
 
 	if internalLogEnabled {
 		internalLog("validateSchema %s", context.String())
@@ -281,7 +411,7 @@ func (v *subSchema) validateSchema(currentSubSchema *subSchema, currentNode inte
 
 		for _, anyOfSchema := range currentSubSchema.anyOf {
 			if !validatedAnyOf {
-				validationResult := anyOfSchema.subValidateWithContext(currentNode, context)
+				validationResult := v.subValidateWithContext(anyOfSchema, currentNode, context)
 				validatedAnyOf = validationResult.Valid()
 
 				if !validatedAnyOf && (bestValidationResult == nil || validationResult.score > bestValidationResult.score) {
@@ -307,7 +437,7 @@ func (v *subSchema) validateSchema(currentSubSchema *subSchema, currentNode inte
 		var bestValidationResult *Result
 
 		for _, oneOfSchema := range currentSubSchema.oneOf {
-			validationResult := oneOfSchema.subValidateWithContext(currentNode, context)
+			validationResult := v.subValidateWithContext(oneOfSchema, currentNode, context)
 			if validationResult.Valid() {
 				nbValidated++
 			} else if nbValidated == 0 && (bestValidationResult == nil || validationResult.score > bestValidationResult.score) {
@@ -332,7 +462,7 @@ func (v *subSchema) validateSchema(currentSubSchema *subSchema, currentNode inte
 		nbValidated := 0
 
 		for _, allOfSchema := range currentSubSchema.allOf {
-			validationResult := allOfSchema.subValidateWithContext(currentNode, context)
+			validationResult := v.subValidateWithContext(allOfSchema, currentNode, context)
 			if validationResult.Valid() {
 				nbValidated++
 			}
@@ -345,7 +475,7 @@ func (v *subSchema) validateSchema(currentSubSchema *subSchema, currentNode inte
 	}
 
 	if currentSubSchema.not != nil {
-		validationResult := currentSubSchema.not.subValidateWithContext(currentNode, context)
+		validationResult := v.subValidateWithContext(currentSubSchema.not, currentNode, context)
 		if validationResult.Valid() {
 			result.addInternalError(new(NumberNotError), context, currentNode, ErrorDetails{})
 		}
@@ -370,7 +500,7 @@ func (v *subSchema) validateSchema(currentSubSchema *subSchema, currentNode inte
 						}
 
 					case *subSchema:
-						dependency.validateRecursive(dependency, currentNode, result, context)
+						v.validateRecursive(dependency, currentNode, result, context)
 					}
 				}
 			}
@@ -378,16 +508,16 @@ func (v *subSchema) validateSchema(currentSubSchema *subSchema, currentNode inte
 	}
 
 	if currentSubSchema._if != nil {
-		validationResultIf := currentSubSchema._if.subValidateWithContext(currentNode, context)
+		validationResultIf := v.subValidateWithContext(currentSubSchema._if, currentNode, context)
 		if currentSubSchema._then != nil && validationResultIf.Valid() {
-			validationResultThen := currentSubSchema._then.subValidateWithContext(currentNode, context)
+			validationResultThen := v.subValidateWithContext(currentSubSchema._then, currentNode, context)
 			if !validationResultThen.Valid() {
 				result.addInternalError(new(ConditionThenError), context, currentNode, ErrorDetails{})
 				result.mergeErrors(validationResultThen)
 			}
 		}
 		if currentSubSchema._else != nil && !validationResultIf.Valid() {
-			validationResultElse := currentSubSchema._else.subValidateWithContext(currentNode, context)
+			validationResultElse := v.subValidateWithContext(currentSubSchema._else, currentNode, context)
 			if !validationResultElse.Valid() {
 				result.addInternalError(new(ConditionElseError), context, currentNode, ErrorDetails{})
 				result.mergeErrors(validationResultElse)
@@ -398,7 +528,7 @@ func (v *subSchema) validateSchema(currentSubSchema *subSchema, currentNode inte
 	result.incrementScore()
 }
 
-func (v *subSchema) validateCommon(currentSubSchema *subSchema, value interface{}, result *Result, context *JsonContext) {
+func (v *validator) validateCommon(currentSubSchema *subSchema, value interface{}, result *Result, context *JsonContext) {
 
 	if internalLogEnabled {
 		internalLog("validateCommon %s", context.String())
@@ -455,7 +585,7 @@ func (v *subSchema) validateCommon(currentSubSchema *subSchema, value interface{
 	result.incrementScore()
 }
 
-func (v *subSchema) validateArray(currentSubSchema *subSchema, value []interface{}, result *Result, context *JsonContext) {
+func (v *validator) validateArray(currentSubSchema *subSchema, value []interface{}, result *Result, context *JsonContext) {
 
 	if internalLogEnabled {
 		internalLog("validateArray %s", context.String())
@@ -466,11 +596,7 @@ func (v *subSchema) validateArray(currentSubSchema *subSchema, value []interface
 
 	// TODO explain
 	if currentSubSchema.itemsChildrenIsSingleSchema {
-		for i := range value {
-			subContext := NewJsonContext(strconv.Itoa(i), context)
-			validationResult := currentSubSchema.itemsChildren[0].subValidateWithContext(value[i], subContext)
-			result.mergeErrors(validationResult)
-		}
+		v.validateItems(currentSubSchema.itemsChildren[0], value, 0, result, context)
 	} else {
 		if currentSubSchema.itemsChildren != nil && len(currentSubSchema.itemsChildren) > 0 {
 
@@ -479,7 +605,7 @@ func (v *subSchema) validateArray(currentSubSchema *subSchema, value []interface
 			// while we have both schemas and values, check them against each other
 			for i := 0; i != nbItems && i != nbValues; i++ {
 				subContext := NewJsonContext(strconv.Itoa(i), context)
-				validationResult := currentSubSchema.itemsChildren[i].subValidateWithContext(value[i], subContext)
+				validationResult := v.subValidateWithContext(currentSubSchema.itemsChildren[i], value[i], subContext)
 				result.mergeErrors(validationResult)
 			}
 
@@ -494,11 +620,7 @@ func (v *subSchema) validateArray(currentSubSchema *subSchema, value []interface
 					}
 				case *subSchema:
 					additionalItemSchema := currentSubSchema.additionalItems.(*subSchema)
-					for i := nbItems; i != nbValues; i++ {
-						subContext := NewJsonContext(strconv.Itoa(i), context)
-						validationResult := additionalItemSchema.subValidateWithContext(value[i], subContext)
-						result.mergeErrors(validationResult)
-					}
+					v.validateItems(additionalItemSchema, value, nbItems, result, context)
 				}
 			}
 		}
@@ -552,10 +674,10 @@ func (v *subSchema) validateArray(currentSubSchema *subSchema, value []interface
 		validatedOne := false
 		var bestValidationResult *Result
 
-		for i, v := range value {
+		for i, item := range value {
 			subContext := NewJsonContext(strconv.Itoa(i), context)
 
-			validationResult := currentSubSchema.contains.subValidateWithContext(v, subContext)
+			validationResult := v.subValidateWithContext(currentSubSchema.contains, item, subContext)
 			if validationResult.Valid() {
 				validatedOne = true
 				break
@@ -581,7 +703,21 @@ func (v *subSchema) validateArray(currentSubSchema *subSchema, value []interface
 	result.incrementScore()
 }
 
-func (v *subSchema) validateObject(currentSubSchema *subSchema, value map[string]interface{}, result *Result, context *JsonContext) {
+// validateItems validates the items of value starting at index from against itemSchema
+func (v *validator) validateItems(itemSchema *subSchema, value []interface{}, from int, result *Result, context *JsonContext) {
+	if v.arrayWorkers > 1 && len(value)-from >= v.arrayWorkersMinItems {
+		v.validateItemsParallel(itemSchema, value, from, result, context)
+		return
+	}
+
+	for i := from; i < len(value); i++ {
+		subContext := NewJsonContext(strconv.Itoa(i), context)
+		validationResult := v.subValidateWithContext(itemSchema, value[i], subContext)
+		result.mergeErrors(validationResult)
+	}
+}
+
+func (v *validator) validateObject(currentSubSchema *subSchema, value map[string]interface{}, result *Result, context *JsonContext) {
 
 	if internalLogEnabled {
 		internalLog("validateObject %s", context.String())
@@ -654,7 +790,7 @@ func (v *subSchema) validateObject(currentSubSchema *subSchema, value map[string
 
 				}
 			case *subSchema:
-				validationResult := ap.subValidateWithContext(value[pk], NewJsonContext(pk, context))
+				validationResult := v.subValidateWithContext(ap, value[pk], NewJsonContext(pk, context))
 				result.mergeErrors(validationResult)
 			}
 		}
@@ -663,7 +799,7 @@ func (v *subSchema) validateObject(currentSubSchema *subSchema, value map[string
 	// propertyNames:
 	if currentSubSchema.propertyNames != nil {
 		for pk := range value {
-			validationResult := currentSubSchema.propertyNames.subValidateWithContext(pk, context)
+			validationResult := v.subValidateWithContext(currentSubSchema.propertyNames, pk, context)
 			if !validationResult.Valid() {
 				result.addInternalError(new(InvalidPropertyNameError),
 					context,
@@ -678,7 +814,7 @@ func (v *subSchema) validateObject(currentSubSchema *subSchema, value map[string
 	result.incrementScore()
 }
 
-func (v *subSchema) validatePatternProperty(currentSubSchema *subSchema, key string, value interface{}, result *Result, context *JsonContext) bool {
+func (v *validator) validatePatternProperty(currentSubSchema *subSchema, key string, value interface{}, result *Result, context *JsonContext) bool {
 
 	if internalLogEnabled {
 		internalLog("validatePatternProperty %s", context.String())
@@ -691,7 +827,7 @@ func (v *subSchema) validatePatternProperty(currentSubSchema *subSchema, key str
 		if matches, _ := regexp.MatchString(pk, key); matches {
 			validated = true
 			subContext := NewJsonContext(key, context)
-			validationResult := pv.subValidateWithContext(value, subContext)
+			validationResult := v.subValidateWithContext(pv, value, subContext)
 			result.mergeErrors(validationResult)
 		}
 	}
@@ -704,7 +840,7 @@ func (v *subSchema) validatePatternProperty(currentSubSchema *subSchema, key str
 	return true
 }
 
-func (v *subSchema) validateString(currentSubSchema *subSchema, value interface{}, result *Result, context *JsonContext) {
+func (v *validator) validateString(currentSubSchema *subSchema, value interface{}, result *Result, context *JsonContext) {
 
 	// Ignore JSON numbers
 	if isJSONNumber(value) {
@@ -761,7 +897,7 @@ func (v *subSchema) validateString(currentSubSchema *subSchema, value interface{
 	result.incrementScore()
 }
 
-func (v *subSchema) validateNumber(currentSubSchema *subSchema, value interface{}, result *Result, context *JsonContext) {
+func (v *validator) validateNumber(currentSubSchema *subSchema, value interface{}, result *Result, context *JsonContext) {
 
 	// Ignore non numbers
 	if !isJSONNumber(value) {
@@ -0,0 +1,1229 @@
+package gojsonschema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/xeipuuv/gojsonreference"
+)
+
+// YAML loader
+
+type yamlStringLoader struct {
+	source string
+}
+
+func (l *yamlStringLoader) JsonSource() interface{} {
+	return l.source
+}
+
+func (l *yamlStringLoader) JsonReference() (gojsonreference.JsonReference, error) {
+	return gojsonreference.NewJsonReference("#")
+}
+
+func (l *yamlStringLoader) LoaderFactory() JSONLoaderFactory {
+	return &DefaultJSONLoaderFactory{}
+}
+
+// NewYAMLLoader creates a new JSONLoader, taking a YAML 1.2 string as source.
+// Scalars are resolved with the YAML core schema, to the values the same document written in JSON would have.
+// Mapping keys must be strings, and YAML that has no JSON equivalent such as .inf is an error.
+// Referenced files and URLs ending in .yaml or .yml, or served as YAML, are decoded as YAML as well
+func NewYAMLLoader(source string) JSONLoader {
+	return &yamlStringLoader{source: source}
+}
+
+func (l *yamlStringLoader) LoadJSON() (interface{}, error) {
+	return decodeYAML(strings.NewReader(l.source))
+}
+
+func (l *yamlStringLoader) openJSON(ctx context.Context) (io.ReadCloser, error) {
+	return &documentReader{ReadCloser: ioutil.NopCloser(strings.NewReader(l.source)), decode: decodeYAML}, nil
+}
+
+// isYAMLPath reports whether the file name or URL path name has a YAML extension
+func isYAMLPath(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// isYAMLMediaType reports whether the Content-Type contentType is YAML
+func isYAMLMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+yaml")
+}
+
+// YAMLError is returned for YAML text that can't be decoded, or that has no JSON equivalent
+type YAMLError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *YAMLError) Error() string {
+	return fmt.Sprintf("yaml: line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+const (
+	// yamlMaxDepth bounds the nesting of collections, like encoding/json does
+	yamlMaxDepth = 10000
+
+	// yamlMaxAliasNodes bounds the nodes added by aliases, so that a few nested
+	// aliases can't expand to an enormous document
+	yamlMaxAliasNodes = 1 << 20
+)
+
+// decodeYAML decodes a single YAML document like decodeJSONUsingNumber decodes JSON
+func decodeYAML(r io.Reader) (interface{}, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &yamlParser{src: strings.TrimPrefix(string(b), "\ufeff"), line: 1, anchors: map[string]yamlAnchor{}}
+	if err := p.checkCharacters(); err != nil {
+		return nil, err
+	}
+	return p.parseStream()
+}
+
+type yamlParser struct {
+	src       string
+	pos       int
+	line      int
+	lineStart int
+	depth     int
+
+	// nodes counts the nodes decoded so far, aliased the nodes copied by aliases
+	nodes   int
+	aliased int
+	anchors map[string]yamlAnchor
+}
+
+type yamlAnchor struct {
+	value interface{}
+	nodes int
+}
+
+// yamlProperties are the anchor and tag of a node
+type yamlProperties struct {
+	anchor string
+	tag    string
+}
+
+type yamlMark struct {
+	pos, line, lineStart int
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	return &YAMLError{Line: p.line, Column: p.pos - p.lineStart + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *yamlParser) mark() yamlMark {
+	return yamlMark{p.pos, p.line, p.lineStart}
+}
+
+func (p *yamlParser) reset(m yamlMark) {
+	p.pos, p.line, p.lineStart = m.pos, m.line, m.lineStart
+}
+
+func (p *yamlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+// peekAt returns the byte i bytes ahead, 0 past the end. The text has no NUL bytes
+func (p *yamlParser) peekAt(i int) byte {
+	if p.pos+i >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+i]
+}
+
+func (p *yamlParser) peek() byte {
+	return p.peekAt(0)
+}
+
+func (p *yamlParser) column() int {
+	return p.pos - p.lineStart
+}
+
+func isYAMLBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isYAMLBreak(c byte) bool {
+	return c == '\n' || c == '\r'
+}
+
+func isYAMLBlankOrEnd(c byte) bool {
+	return c == 0 || isYAMLBlank(c) || isYAMLBreak(c)
+}
+
+func isYAMLFlowIndicator(c byte) bool {
+	return c == ',' || c == '[' || c == ']' || c == '{' || c == '}'
+}
+
+// checkCharacters rejects text that isn't UTF-8, or has control characters YAML doesn't allow
+func (p *yamlParser) checkCharacters() error {
+	for p.pos = 0; p.pos < len(p.src); p.pos++ {
+		c := p.src[p.pos]
+		if c == '\n' {
+			p.line++
+			p.lineStart = p.pos + 1
+		} else if c < 0x20 && c != '\t' && c != '\r' || c == 0x7f {
+			return p.errorf("control characters are not allowed")
+		}
+	}
+	p.pos, p.line, p.lineStart = 0, 1, 0
+	if !utf8.ValidString(p.src) {
+		return p.errorf("invalid UTF-8")
+	}
+	return nil
+}
+
+func (p *yamlParser) newline() {
+	if p.peek() == '\r' && p.peekAt(1) == '\n' {
+		p.pos++
+	}
+	p.pos++
+	p.line++
+	p.lineStart = p.pos
+}
+
+func (p *yamlParser) skipSpaces() {
+	for isYAMLBlank(p.peek()) {
+		p.pos++
+	}
+}
+
+// skipBlank skips spaces, comments and line breaks
+func (p *yamlParser) skipBlank() {
+	for {
+		p.skipSpaces()
+		if p.peek() == '#' {
+			for !p.eof() && !isYAMLBreak(p.peek()) {
+				p.pos++
+			}
+		}
+		if !isYAMLBreak(p.peek()) {
+			return
+		}
+		p.newline()
+	}
+}
+
+// checkIndentation rejects tabs in the indentation of block content at the current position
+func (p *yamlParser) checkIndentation() error {
+	indentation := p.src[p.lineStart:p.pos]
+	if strings.TrimLeft(indentation, " \t") == "" && strings.IndexByte(indentation, '\t') >= 0 {
+		return p.errorf("tabs can't be used for indentation")
+	}
+	return nil
+}
+
+// atDocumentMarker reports whether a --- or ... line starts at the current position
+func (p *yamlParser) atDocumentMarker() bool {
+	if p.column() != 0 || !isYAMLBlankOrEnd(p.peekAt(3)) {
+		return false
+	}
+	marker := p.src[p.pos:]
+	return strings.HasPrefix(marker, "---") || strings.HasPrefix(marker, "...")
+}
+
+func (p *yamlParser) atSequenceEntry() bool {
+	return p.peek() == '-' && isYAMLBlankOrEnd(p.peekAt(1))
+}
+
+// parseStream parses a stream of a single document
+func (p *yamlParser) parseStream() (interface{}, error) {
+	p.skipBlank()
+	directives := false
+	for p.column() == 0 && p.peek() == '%' {
+		directives = true
+		for !p.eof() && !isYAMLBreak(p.peek()) {
+			p.pos++
+		}
+		p.skipBlank()
+	}
+
+	explicit := p.atDocumentMarker() && p.peek() == '-'
+	if explicit {
+		p.pos += 3
+	} else if directives {
+		return nil, p.errorf("expected --- after directives")
+	} else if p.eof() {
+		return nil, p.errorf("no document")
+	}
+
+	document, err := p.parseNode(-1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+	if p.atDocumentMarker() && p.peek() == '.' {
+		p.pos += 3
+		p.skipBlank()
+	}
+	if p.atDocumentMarker() {
+		return nil, p.errorf("only a single document is supported")
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected content after the document")
+	}
+	return document, nil
+}
+
+// parseNode parses a block node after a mapping key, a sequence entry or the start of the document.
+// indent is the indentation of the parent collection, content on the following lines must be indented more.
+// Block collections may start on the same line in sequence entries and documents, but not in mapping values,
+// where a block sequence may have the indentation of the mapping instead
+func (p *yamlParser) parseNode(indent int, mappingValue bool) (interface{}, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > yamlMaxDepth {
+		return nil, p.errorf("exceeded max depth of %d", yamlMaxDepth)
+	}
+
+	nodes := p.nodes
+	p.skipSpaces()
+	column, line := p.column(), p.line
+	props, err := p.parseProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	inline := !mappingValue
+	if p.eof() || p.peek() == '#' || isYAMLBreak(p.peek()) {
+		p.skipBlank()
+		if p.eof() || p.atDocumentMarker() ||
+			p.column() <= indent && !(mappingValue && p.column() == indent && p.atSequenceEntry()) {
+			value, err := p.resolve(props.tag, "", true)
+			if err != nil {
+				return nil, err
+			}
+			p.nodes++
+			p.setAnchor(props.anchor, value, nodes)
+			return value, nil
+		}
+		if err := p.checkIndentation(); err != nil {
+			return nil, err
+		}
+		inline = true
+		column = p.column()
+	}
+
+	// Properties on the line of a mapping's first key belong to the key
+	keyProps := p.line == line && props != yamlProperties{}
+	value, isMapping, err := p.parseContent(indent, column, inline, props, keyProps)
+	if err != nil {
+		return nil, err
+	}
+	if !isMapping || !keyProps {
+		p.setAnchor(props.anchor, value, nodes)
+	}
+	return value, nil
+}
+
+// parseContent parses the node at the current position, or a block mapping if it is followed by ':'.
+// column is where the node starts, including its properties
+func (p *yamlParser) parseContent(indent int, column int, inline bool, props yamlProperties, keyProps bool) (interface{}, bool, error) {
+	switch c := p.peek(); {
+	case c == '*':
+		if props != (yamlProperties{}) {
+			return nil, false, p.errorf("an alias can't have an anchor or a tag")
+		}
+		value, err := p.parseAlias()
+		if err != nil {
+			return nil, false, err
+		}
+		if p.skipSpaces(); p.peek() == ':' {
+			return nil, false, p.errorf("aliases can't be used as mapping keys")
+		}
+		return value, false, nil
+
+	case c == '|' || c == '>':
+		value, err := p.parseBlockScalar(indent, props.tag)
+		p.nodes++
+		return value, false, err
+
+	case c == '[' || c == '{':
+		value, err := p.parseFlowCollection(props.tag)
+		if err != nil {
+			return nil, false, err
+		}
+		if p.skipSpaces(); p.peek() == ':' {
+			return nil, false, p.errorf("mapping keys must be strings")
+		}
+		return value, false, nil
+
+	case p.atSequenceEntry():
+		if !inline {
+			return nil, false, p.errorf("a block sequence can't start on the line of its key")
+		}
+		if err := p.checkCollectionTag(props.tag, "!!seq"); err != nil {
+			return nil, false, err
+		}
+		p.nodes++
+		value, err := p.parseBlockSequence(p.column())
+		return value, false, err
+
+	case c == '?' && isYAMLBlankOrEnd(p.peekAt(1)):
+		return nil, false, p.errorf("explicit mapping keys are not supported")
+	}
+
+	line := p.line
+	text, plain, err := p.parseScalar(false)
+	if err != nil {
+		return nil, false, err
+	}
+
+	m := p.mark()
+	if p.skipSpaces(); p.line == line && p.atKeyColon(plain) {
+		if !inline {
+			return nil, false, p.errorf("mapping values are not allowed here")
+		}
+		tag := ""
+		if keyProps {
+			tag = props.tag
+		}
+		key, err := p.resolveKey(tag, text, plain)
+		if err != nil {
+			return nil, false, err
+		}
+		if keyProps {
+			p.setAnchor(props.anchor, key, p.nodes)
+		} else if err := p.checkCollectionTag(props.tag, "!!map"); err != nil {
+			return nil, false, err
+		}
+		p.nodes++
+		value, err := p.parseBlockMapping(column, key)
+		return value, true, err
+	}
+	p.reset(m)
+
+	if plain {
+		text = p.continuePlain(text, indent, false)
+	}
+	value, err := p.resolve(props.tag, text, plain)
+	p.nodes++
+	return value, false, err
+}
+
+// atKeyColon reports whether the current position is the ':' after a mapping key.
+// It must be followed by a space unless the key is quoted, like in JSON
+func (p *yamlParser) atKeyColon(plain bool) bool {
+	return p.peek() == ':' && (!plain || isYAMLBlankOrEnd(p.peekAt(1)))
+}
+
+func (p *yamlParser) parseBlockMapping(indent int, key string) (interface{}, error) {
+	object := map[string]interface{}{}
+	for {
+		if _, ok := object[key]; ok {
+			return nil, p.errorf("duplicate mapping key %q", key)
+		}
+		p.pos++ // ':'
+		value, err := p.parseNode(indent, true)
+		if err != nil {
+			return nil, err
+		}
+		object[key] = value
+
+		p.skipBlank()
+		if p.eof() || p.atDocumentMarker() || p.column() < indent {
+			return object, nil
+		}
+		if err := p.checkIndentation(); err != nil {
+			return nil, err
+		}
+		if p.column() > indent {
+			return nil, p.errorf("bad indentation of a mapping entry")
+		}
+		if key, err = p.parseKey(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseKey parses the key of a block mapping entry, up to its ':'
+func (p *yamlParser) parseKey() (string, error) {
+	line := p.line
+	props, err := p.parseProperties()
+	if err != nil {
+		return "", err
+	}
+
+	switch c := p.peek(); {
+	case c == '?' && isYAMLBlankOrEnd(p.peekAt(1)):
+		return "", p.errorf("explicit mapping keys are not supported")
+	case p.atSequenceEntry():
+		return "", p.errorf("expected a mapping key, found a sequence entry")
+	case c == '[' || c == '{':
+		return "", p.errorf("mapping keys must be strings")
+	case c == '*':
+		return "", p.errorf("aliases can't be used as mapping keys")
+	}
+
+	text, plain, err := p.parseScalar(false)
+	if err != nil {
+		return "", err
+	}
+	if p.skipSpaces(); p.line != line {
+		return "", p.errorf("mapping keys must be on a single line")
+	}
+	if !p.atKeyColon(plain) {
+		return "", p.errorf("expected ':' after a mapping key")
+	}
+
+	key, err := p.resolveKey(props.tag, text, plain)
+	if err != nil {
+		return "", err
+	}
+	p.setAnchor(props.anchor, key, p.nodes)
+	p.nodes++
+	return key, nil
+}
+
+func (p *yamlParser) resolveKey(tag string, text string, plain bool) (string, error) {
+	value, err := p.resolve(tag, text, plain)
+	if err != nil {
+		return "", err
+	}
+	key, ok := value.(string)
+	if !ok {
+		return "", p.errorf("mapping keys must be strings, %q is not", text)
+	}
+	return key, nil
+}
+
+func (p *yamlParser) parseBlockSequence(indent int) (interface{}, error) {
+	list := []interface{}{}
+	for {
+		p.pos++ // '-'
+		item, err := p.parseNode(indent, false)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+
+		p.skipBlank()
+		if p.eof() || p.atDocumentMarker() || p.column() < indent {
+			return list, nil
+		}
+		if err := p.checkIndentation(); err != nil {
+			return nil, err
+		}
+		if p.column() > indent {
+			return nil, p.errorf("bad indentation of a sequence entry")
+		}
+		if !p.atSequenceEntry() {
+			// a key of the mapping this sequence is a value of
+			return list, nil
+		}
+	}
+}
+
+// parseProperties parses the anchor and tag of a node, in any order
+func (p *yamlParser) parseProperties() (yamlProperties, error) {
+	var props yamlProperties
+	for {
+		switch p.peek() {
+		case '&':
+			if props.anchor != "" {
+				return props, p.errorf("a node can only have one anchor")
+			}
+			p.pos++
+			if props.anchor = p.scanName(); props.anchor == "" {
+				return props, p.errorf("expected an anchor name")
+			}
+		case '!':
+			if props.tag != "" {
+				return props, p.errorf("a node can only have one tag")
+			}
+			tag, err := p.scanTag()
+			if err != nil {
+				return props, err
+			}
+			props.tag = tag
+		default:
+			return props, nil
+		}
+		p.skipSpaces()
+	}
+}
+
+// scanName scans the name of an anchor or alias
+func (p *yamlParser) scanName() string {
+	start := p.pos
+	for !isYAMLBlankOrEnd(p.peek()) && !isYAMLFlowIndicator(p.peek()) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// yamlTags are the tags of the core schema, the only ones with a JSON equivalent
+var yamlTags = map[string]bool{"!": true, "!!str": true, "!!null": true, "!!bool": true, "!!int": true, "!!float": true, "!!map": true, "!!seq": true}
+
+func (p *yamlParser) scanTag() (string, error) {
+	start := p.pos
+	var tag string
+	if p.peekAt(1) == '<' {
+		end := strings.IndexByte(p.src[p.pos:], '>')
+		if end < 0 {
+			return "", p.errorf("unterminated verbatim tag")
+		}
+		tag = p.src[p.pos+2 : p.pos+end]
+		p.pos += end + 1
+		if strings.HasPrefix(tag, "tag:yaml.org,2002:") {
+			tag = "!!" + strings.TrimPrefix(tag, "tag:yaml.org,2002:")
+		}
+	} else {
+		p.pos++
+		for !isYAMLBlankOrEnd(p.peek()) && !isYAMLFlowIndicator(p.peek()) {
+			p.pos++
+		}
+		tag = p.src[start:p.pos]
+	}
+	if !yamlTags[tag] {
+		p.pos = start
+		return "", p.errorf("unsupported tag %s", tag)
+	}
+	return tag, nil
+}
+
+func (p *yamlParser) checkCollectionTag(tag string, expected string) error {
+	if tag != "" && tag != "!" && tag != expected {
+		return p.errorf("a %s can't have the tag %s", map[string]string{"!!map": "mapping", "!!seq": "sequence"}[expected], tag)
+	}
+	return nil
+}
+
+func (p *yamlParser) setAnchor(name string, value interface{}, nodes int) {
+	if name != "" {
+		p.anchors[name] = yamlAnchor{value: value, nodes: p.nodes - nodes}
+	}
+}
+
+// parseAlias returns a copy of the node of an anchor, so that the document is a tree like decoded JSON
+func (p *yamlParser) parseAlias() (interface{}, error) {
+	p.pos++ // '*'
+	name := p.scanName()
+	anchor, ok := p.anchors[name]
+	if !ok {
+		return nil, p.errorf("unknown anchor %q", name)
+	}
+	p.aliased += anchor.nodes
+	if p.aliased > yamlMaxAliasNodes {
+		return nil, p.errorf("aliases expand to more than %d nodes", yamlMaxAliasNodes)
+	}
+	p.nodes += anchor.nodes
+	return copyDocument(anchor.value), nil
+}
+
+// parseScalar parses a quoted scalar, or the first line of a plain scalar
+func (p *yamlParser) parseScalar(flow bool) (text string, plain bool, err error) {
+	switch c := p.peek(); c {
+	case '\'':
+		text, err = p.parseSingleQuoted()
+		return text, false, err
+	case '"':
+		text, err = p.parseDoubleQuoted()
+		return text, false, err
+	case '-', '?', ':':
+		if isYAMLBlankOrEnd(p.peekAt(1)) || flow && isYAMLFlowIndicator(p.peekAt(1)) {
+			return "", false, p.errorf("unexpected '%c'", c)
+		}
+	case ',', '[', ']', '{', '}', '#', '&', '*', '!', '|', '>', '%', '@', '`':
+		return "", false, p.errorf("unexpected '%c'", c)
+	}
+	return p.scanPlainLine(flow), true, nil
+}
+
+// scanPlainLine scans a plain scalar up to the end of the line, leaving out trailing spaces
+func (p *yamlParser) scanPlainLine(flow bool) string {
+	start, end := p.pos, p.pos
+	for !p.eof() {
+		c := p.peek()
+		if isYAMLBreak(c) ||
+			c == ':' && (isYAMLBlankOrEnd(p.peekAt(1)) || flow && isYAMLFlowIndicator(p.peekAt(1))) ||
+			c == '#' && p.pos > start && isYAMLBlank(p.src[p.pos-1]) ||
+			flow && isYAMLFlowIndicator(c) {
+			break
+		}
+		p.pos++
+		if !isYAMLBlank(c) {
+			end = p.pos
+		}
+	}
+	p.pos = end
+	return p.src[start:end]
+}
+
+// continuePlain adds the following lines of a plain scalar to text. In block context they must
+// be indented more than the parent collection. A line break is folded into a space, empty lines into line breaks
+func (p *yamlParser) continuePlain(text string, indent int, flow bool) string {
+	var b strings.Builder
+	b.WriteString(text)
+	for {
+		m := p.mark()
+		if p.skipSpaces(); !isYAMLBreak(p.peek()) {
+			p.reset(m)
+			return b.String()
+		}
+
+		breaks := 0
+		for isYAMLBreak(p.peek()) {
+			p.newline()
+			breaks++
+			p.skipSpaces()
+		}
+		if p.eof() || p.peek() == '#' || p.atDocumentMarker() || !flow && p.column() <= indent {
+			p.reset(m)
+			return b.String()
+		}
+		if c := p.peek(); c == ':' && (isYAMLBlankOrEnd(p.peekAt(1)) || flow && isYAMLFlowIndicator(p.peekAt(1))) || flow && isYAMLFlowIndicator(c) {
+			p.reset(m)
+			return b.String()
+		}
+
+		if breaks == 1 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteString(strings.Repeat("\n", breaks-1))
+		}
+		b.WriteString(p.scanPlainLine(flow))
+	}
+}
+
+func (p *yamlParser) parseSingleQuoted() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		switch c := p.peek(); {
+		case p.eof():
+			return "", p.errorf("unterminated quoted scalar")
+		case c == '\'' && p.peekAt(1) == '\'':
+			b.WriteByte('\'')
+			p.pos += 2
+		case c == '\'':
+			p.pos++
+			return b.String(), nil
+		case isYAMLBlank(c) || isYAMLBreak(c):
+			p.quotedWhitespace(&b)
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// yamlEscapes are the escapes of double quoted scalars, but for \x, \u and \U
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f", 'r': "\r", 'e': "\x1b",
+	' ': " ", '"': "\"", '/': "/", '\\': "\\", 'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029",
+}
+
+func (p *yamlParser) parseDoubleQuoted() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		switch c := p.peek(); {
+		case p.eof():
+			return "", p.errorf("unterminated quoted scalar")
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && isYAMLBreak(p.peekAt(1)):
+			// an escaped line break joins the lines
+			p.pos++
+			p.newline()
+			p.skipSpaces()
+		case c == '\\':
+			r, err := p.scanEscape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(r)
+		case isYAMLBlank(c) || isYAMLBreak(c):
+			p.quotedWhitespace(&b)
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *yamlParser) scanEscape() (string, error) {
+	e := p.peekAt(1)
+	if s, ok := yamlEscapes[e]; ok {
+		p.pos += 2
+		return s, nil
+	}
+
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[e]
+	if digits == 0 {
+		return "", p.errorf("unknown escape \\%c", e)
+	}
+	r, ok := p.scanHexRune(digits)
+	if !ok {
+		return "", p.errorf("invalid escape")
+	}
+	// JSON writes characters outside the BMP as UTF-16 surrogate pairs, so YAML meant to be JSON may too
+	if utf16.IsSurrogate(r) && p.peek() == '\\' && p.peekAt(1) == 'u' {
+		m := p.mark()
+		if low, ok := p.scanHexRune(4); ok {
+			if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+				return string(pair), nil
+			}
+		}
+		p.reset(m)
+	}
+	if !utf8.ValidRune(r) {
+		return "", p.errorf("invalid escaped character %U", r)
+	}
+	return string(r), nil
+}
+
+// scanHexRune scans the escape character and digits hexadecimal digits after it
+func (p *yamlParser) scanHexRune(digits int) (rune, bool) {
+	if p.pos+2+digits > len(p.src) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(p.src[p.pos+2:p.pos+2+digits], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	p.pos += 2 + digits
+	return rune(n), true
+}
+
+// quotedWhitespace adds the spaces and line breaks at the current position of a quoted scalar to b.
+// Spaces around line breaks are dropped, a line break is folded into a space, empty lines into line breaks
+func (p *yamlParser) quotedWhitespace(b *strings.Builder) {
+	start := p.pos
+	p.skipSpaces()
+	if !isYAMLBreak(p.peek()) {
+		b.WriteString(p.src[start:p.pos])
+		return
+	}
+
+	breaks := 0
+	for isYAMLBreak(p.peek()) {
+		p.newline()
+		breaks++
+		p.skipSpaces()
+	}
+	if breaks == 1 {
+		b.WriteByte(' ')
+	} else {
+		b.WriteString(strings.Repeat("\n", breaks-1))
+	}
+}
+
+// parseBlockScalar parses a literal (|) or folded (>) scalar, indent is the indentation of the parent collection
+func (p *yamlParser) parseBlockScalar(indent int, tag string) (interface{}, error) {
+	style := p.peek()
+	p.pos++
+
+	var chomping byte
+	explicit := 0
+	for i := 0; i < 2; i++ {
+		switch c := p.peek(); {
+		case (c == '+' || c == '-') && chomping == 0:
+			chomping = c
+			p.pos++
+		case c >= '1' && c <= '9' && explicit == 0:
+			explicit = int(c - '0')
+			p.pos++
+		}
+	}
+	p.skipSpaces()
+	if p.peek() == '#' {
+		for !p.eof() && !isYAMLBreak(p.peek()) {
+			p.pos++
+		}
+	}
+	if !p.eof() && !isYAMLBreak(p.peek()) {
+		return nil, p.errorf("expected a line break after the block scalar header")
+	}
+	if !p.eof() {
+		p.newline()
+	}
+
+	contentIndent := 0
+	if explicit > 0 {
+		if indent > 0 {
+			contentIndent = indent
+		}
+		contentIndent += explicit
+	}
+
+	var lines []string
+	for !p.eof() {
+		spaces := 0
+		for p.peekAt(spaces) == ' ' {
+			spaces++
+		}
+		end := strings.IndexAny(p.src[p.pos:], "\r\n")
+		if end < 0 {
+			end = len(p.src) - p.pos
+		}
+		blank := strings.TrimLeft(p.src[p.pos+spaces:p.pos+end], " \t") == ""
+
+		if p.atDocumentMarker() {
+			break
+		}
+		if contentIndent == 0 && !blank {
+			if spaces <= indent {
+				break
+			}
+			contentIndent = spaces
+		}
+		if spaces < contentIndent || contentIndent == 0 {
+			if !blank {
+				break
+			}
+			lines = append(lines, "")
+		} else {
+			lines = append(lines, p.src[p.pos+contentIndent:p.pos+end])
+		}
+
+		p.pos += end
+		if !p.eof() {
+			p.newline()
+		}
+	}
+
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var b strings.Builder
+	empty, started, previousIndented := 0, false, false
+	for _, line := range lines {
+		if line == "" {
+			empty++
+			continue
+		}
+		indented := line[0] == ' ' || line[0] == '\t'
+		switch {
+		case !started:
+			b.WriteString(strings.Repeat("\n", empty))
+		case style == '>' && !indented && !previousIndented && empty == 0:
+			b.WriteByte(' ')
+		case style == '>' && !indented && !previousIndented:
+			b.WriteString(strings.Repeat("\n", empty))
+		default:
+			b.WriteString(strings.Repeat("\n", empty+1))
+		}
+		b.WriteString(line)
+		empty, started, previousIndented = 0, true, indented
+	}
+
+	switch chomping {
+	case '-':
+	case '+':
+		if started {
+			b.WriteByte('\n')
+		}
+		b.WriteString(strings.Repeat("\n", trailing))
+	default:
+		if started {
+			b.WriteByte('\n')
+		}
+	}
+	return p.resolve(tag, b.String(), false)
+}
+
+// parseFlowCollection parses a [sequence] or {mapping}, tag is the tag of the collection
+func (p *yamlParser) parseFlowCollection(tag string) (interface{}, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > yamlMaxDepth {
+		return nil, p.errorf("exceeded max depth of %d", yamlMaxDepth)
+	}
+	p.nodes++
+
+	if p.peek() == '[' {
+		if err := p.checkCollectionTag(tag, "!!seq"); err != nil {
+			return nil, err
+		}
+		return p.parseFlowSequence()
+	}
+	if err := p.checkCollectionTag(tag, "!!map"); err != nil {
+		return nil, err
+	}
+	return p.parseFlowMapping()
+}
+
+func (p *yamlParser) parseFlowSequence() (interface{}, error) {
+	p.pos++ // '['
+	list := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return list, nil
+		}
+
+		item, err := p.parseFlowNode()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		case ':':
+			return nil, p.errorf("mappings in flow sequences are not supported")
+		default:
+			return nil, p.errorf("expected ',' or ']' in a flow sequence")
+		}
+	}
+}
+
+func (p *yamlParser) parseFlowMapping() (interface{}, error) {
+	p.pos++ // '{'
+	object := map[string]interface{}{}
+	for {
+		p.skipBlank()
+		if p.peek() == '}' {
+			p.pos++
+			return object, nil
+		}
+
+		key, err := p.parseFlowKey()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := object[key]; ok {
+			return nil, p.errorf("duplicate mapping key %q", key)
+		}
+
+		var value interface{}
+		p.skipBlank()
+		if p.peek() == ':' {
+			p.pos++
+			p.skipBlank()
+			if c := p.peek(); c != ',' && c != '}' {
+				if value, err = p.parseFlowNode(); err != nil {
+					return nil, err
+				}
+				p.skipBlank()
+			}
+		}
+		object[key] = value
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("expected ',' or '}' in a flow mapping")
+		}
+	}
+}
+
+func (p *yamlParser) parseFlowKey() (string, error) {
+	props, err := p.parseProperties()
+	if err != nil {
+		return "", err
+	}
+	switch c := p.peek(); {
+	case c == '?' && isYAMLBlankOrEnd(p.peekAt(1)):
+		return "", p.errorf("explicit mapping keys are not supported")
+	case c == '[' || c == '{':
+		return "", p.errorf("mapping keys must be strings")
+	case c == '*':
+		return "", p.errorf("aliases can't be used as mapping keys")
+	}
+
+	text, plain, err := p.parseScalar(true)
+	if err != nil {
+		return "", err
+	}
+	if plain {
+		text = p.continuePlain(text, -1, true)
+	}
+	key, err := p.resolveKey(props.tag, text, plain)
+	if err != nil {
+		return "", err
+	}
+	p.setAnchor(props.anchor, key, p.nodes)
+	p.nodes++
+	return key, nil
+}
+
+func (p *yamlParser) parseFlowNode() (interface{}, error) {
+	nodes := p.nodes
+	props, err := p.parseProperties()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+
+	var value interface{}
+	switch c := p.peek(); c {
+	case '*':
+		if props != (yamlProperties{}) {
+			return nil, p.errorf("an alias can't have an anchor or a tag")
+		}
+		return p.parseAlias()
+	case '[', '{':
+		value, err = p.parseFlowCollection(props.tag)
+	case ',', ']', '}':
+		// a node of just properties
+		value, err = p.resolve(props.tag, "", true)
+		p.nodes++
+	default:
+		var text string
+		var plain bool
+		if text, plain, err = p.parseScalar(true); err != nil {
+			return nil, err
+		}
+		if plain {
+			text = p.continuePlain(text, -1, true)
+		}
+		value, err = p.resolve(props.tag, text, plain)
+		p.nodes++
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.setAnchor(props.anchor, value, nodes)
+	return value, nil
+}
+
+// resolve returns the value of a scalar with the tag. Plain scalars without a tag are
+// resolved with the core schema, quoted scalars are strings
+func (p *yamlParser) resolve(tag string, text string, plain bool) (interface{}, error) {
+	switch tag {
+	case "":
+		if !plain {
+			return text, nil
+		}
+	case "!", "!!str":
+		return text, nil
+	case "!!map", "!!seq":
+		if text == "" && plain {
+			return map[string]interface{}{"!!map": map[string]interface{}{}, "!!seq": []interface{}{}}[tag], nil
+		}
+		return nil, p.errorf("a scalar can't have the tag %s", tag)
+	}
+
+	value, err := resolveYAMLScalar(text)
+	if err != nil {
+		return nil, p.errorf("%s", err.Error())
+	}
+
+	ok := true
+	switch tag {
+	case "!!null":
+		ok = value == nil
+	case "!!bool":
+		_, ok = value.(bool)
+	case "!!int":
+		n, isNumber := value.(json.Number)
+		ok = isNumber && yamlInteger.MatchString(string(n))
+	case "!!float":
+		_, ok = value.(json.Number)
+	}
+	if !ok {
+		return nil, p.errorf("%q is not a valid %s", text, tag)
+	}
+	return value, nil
+}
+
+var (
+	yamlInteger = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlOctal   = regexp.MustCompile(`^0o[0-7]+$`)
+	yamlHex     = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+	yamlFloat   = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+	yamlSpecial = regexp.MustCompile(`^([-+]?\.(inf|Inf|INF)|\.(nan|NaN|NAN))$`)
+)
+
+// resolveYAMLScalar resolves a plain scalar with the YAML 1.2 core schema.
+// Numbers are written as JSON numbers, keeping the text when it is a JSON number already
+func resolveYAMLScalar(text string) (interface{}, error) {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+
+	switch {
+	case yamlInteger.MatchString(text):
+		return json.Number(jsonIntegerText(text)), nil
+	case yamlOctal.MatchString(text), yamlHex.MatchString(text):
+		n, _ := new(big.Int).SetString(text, 0)
+		return json.Number(n.String()), nil
+	case yamlFloat.MatchString(text):
+		return json.Number(jsonFloatText(text)), nil
+	case yamlSpecial.MatchString(text):
+		return nil, fmt.Errorf("%s has no JSON equivalent", text)
+	}
+	return text, nil
+}
+
+// jsonIntegerText writes a decimal integer without a + sign or leading zeros
+func jsonIntegerText(text string) string {
+	sign := ""
+	if text[0] == '-' || text[0] == '+' {
+		if text[0] == '-' {
+			sign = "-"
+		}
+		text = text[1:]
+	}
+	if text = strings.TrimLeft(text, "0"); text == "" {
+		text = "0"
+	}
+	return sign + text
+}
+
+// jsonFloatText writes a YAML float, like +.5 or 1., as a JSON number
+func jsonFloatText(text string) string {
+	exponent := ""
+	if e := strings.IndexAny(text, "eE"); e >= 0 {
+		text, exponent = text[:e], text[e:]
+	}
+	fraction := ""
+	if dot := strings.IndexByte(text, '.'); dot >= 0 {
+		text, fraction = text[:dot], text[dot:]
+		if fraction == "." {
+			fraction = ""
+		}
+	}
+	if text == "" || text == "-" || text == "+" {
+		text += "0"
+	}
+	return jsonIntegerText(text) + fraction + exponent
+}
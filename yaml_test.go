@@ -0,0 +1,296 @@
+package gojsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	cases := []struct {
+		yaml string
+		json string
+	}{
+		// scalars
+		{`a`, `"a"`},
+		{`42`, `42`},
+		{`-0012`, `-12`},
+		{`+1.50`, `1.50`},
+		{`.5`, `0.5`},
+		{`1.`, `1`},
+		{`-1.e3`, `-1e3`},
+		{`0x1F`, `31`},
+		{`0o17`, `15`},
+		{`1_000`, `"1_000"`},
+		{`true`, `true`},
+		{`False`, `false`},
+		{`on`, `"on"`},
+		{`~`, `null`},
+		{`---`, `null`},
+		{`"42"`, `"42"`},
+		{`'it''s'`, `"it's"`},
+		{`"a\tb\u00e9\x41\ud83d\ude00"`, `"a\tbéA😀"`},
+		{`!!str 42`, `"42"`},
+		{`!!float 1`, `1`},
+		{`!!null`, `null`},
+		{"# comment\n--- plain text # comment\n...\n", `"plain text"`},
+		{"%YAML 1.2\n---\nx\n", `"x"`},
+		{"\ufeffa: 1", `{"a" : 1}`},
+
+		// multi-line scalars
+		{"a: one\n  two\n\n  three\n", `{"a" : "one two\nthree"}`},
+		{"a: 'one\n  two'\n", `{"a" : "one two"}`},
+		{"a: \"one \\\n  two\"\n", `{"a" : "one two"}`},
+		{"a: |\n  one\n   two\n\n", `{"a" : "one\n two\n"}`},
+		{"a: |-\n  one\n", `{"a" : "one"}`},
+		{"a: |+\n  one\n\n", `{"a" : "one\n\n"}`},
+		{"a: >\n  one\n  two\n\n  three\n    four\n", `{"a" : "one two\nthree\n  four\n"}`},
+		{"a: |2\n   one\n", `{"a" : " one\n"}`},
+		{"a: |\n  # not a comment\nb: 1", `{"a" : "# not a comment\n", "b" : 1}`},
+
+		// block collections
+		{"a: 1\nb:\n  c: x\n  d: [1, 2]\n", `{"a" : 1, "b" : {"c" : "x", "d" : [1, 2]}}`},
+		{"- a\n- - b\n  - c\n- d: 1\n  e: 2\n-\n", `["a", ["b", "c"], {"d" : 1, "e" : 2}, null]`},
+		{"a:\n- 1\n- 2\nb:\n", `{"a" : [1, 2], "b" : null}`},
+		{"a:\n  - b: 1\n    c: 2\n", `{"a" : [{"b" : 1, "c" : 2}]}`},
+		{"'a b' : 1\n\"c\":2\nd e: 3\nhttp://x: y", `{"a b" : 1, "c" : 2, "d e" : 3, "http://x" : "y"}`},
+		{"!!str 1: one", `{"1" : "one"}`},
+		{"a: !!map\nb: !!seq", `{"a" : {}, "b" : []}`},
+
+		// flow collections, and JSON
+		{`{"a": [1, 2.5, "x", true, null], "b": {}}`, `{"a" : [1, 2.5, "x", true, null], "b" : {}}`},
+		{`{"a":1,"b":[{"c":"d"}]}`, `{"a" : 1, "b" : [{"c" : "d"}]}`},
+		{"{a: 1, b, c: [x, y,],\n d: {e: f}}", `{"a" : 1, "b" : null, "c" : ["x", "y"], "d" : {"e" : "f"}}`},
+		{"[a b, 'c', !!str 1]", `["a b", "c", "1"]`},
+
+		// anchors and aliases
+		{"a: &x {b: 1}\nc: *x\n", `{"a" : {"b" : 1}, "c" : {"b" : 1}}`},
+		{"- &x one\n- *x\n", `["one", "one"]`},
+		{"base: &base\n  a: 1\nother:\n  <<: *base\n", `{"base" : {"a" : 1}, "other" : {"<<" : {"a" : 1}}}`},
+	}
+
+	for _, c := range cases {
+		expected, err := decodeJSONUsingNumber(strings.NewReader(c.json))
+		if !assert.Nil(t, err, c.json) {
+			continue
+		}
+		actual, err := decodeYAML(strings.NewReader(c.yaml))
+		if assert.Nil(t, err, "%q", c.yaml) {
+			assert.Equal(t, expected, actual, "%q", c.yaml)
+		}
+	}
+}
+
+func TestDecodeYAMLErrors(t *testing.T) {
+	cases := []struct {
+		yaml    string
+		message string
+	}{
+		{``, "line 1, column 1: no document"},
+		{"1: a", "mapping keys must be strings"},
+		{"true: a", "mapping keys must be strings"},
+		{"{null: a}", "mapping keys must be strings"},
+		{"[a]: b", "mapping keys must be strings"},
+		{"a: 1\na: 2", "line 2, column 2: duplicate mapping key"},
+		{"a: .inf", ".inf has no JSON equivalent"},
+		{"a: !!int 1.5", "is not a valid !!int"},
+		{"a: !custom x", "unsupported tag !custom"},
+		{"a: *unknown", "unknown anchor"},
+		{"a\n---\nb", "only a single document"},
+		{"a: b: c", "mapping values are not allowed here"},
+		{"a: - b", "a block sequence can't start on the line of its key"},
+		{"a:\n  b: 1\n c: 2", "line 3, column 2: bad indentation"},
+		{"a:\n\tb: 1", "tabs can't be used for indentation"},
+		{"a: 'open", "unterminated quoted scalar"},
+		{"[a, b", "expected ',' or ']'"},
+		{"a: \"\\q\"", "unknown escape"},
+		{"a: \x01", "control characters"},
+		{"? a\n: b", "explicit mapping keys are not supported"},
+	}
+
+	for _, c := range cases {
+		_, err := decodeYAML(strings.NewReader(c.yaml))
+		var yamlErr *YAMLError
+		if assert.True(t, errors.As(err, &yamlErr), "%q: expected a YAMLError, got %v", c.yaml, err) {
+			assert.Contains(t, yamlErr.Error(), c.message, "%q", c.yaml)
+		}
+	}
+
+	// A few nested aliases can't expand to an enormous document
+	laughs := "a: &a [x, x, x, x, x, x, x, x, x, x]\n"
+	for i := 'b'; i <= 'j'; i++ {
+		laughs += string(i) + ": &" + string(i) + " [" + strings.Repeat("*"+string(i-1)+", ", 9) + "*" + string(i-1) + "]\n"
+	}
+	_, err := decodeYAML(strings.NewReader(laughs))
+	assert.Contains(t, err.Error(), "aliases expand to more than")
+}
+
+// TestDecodeYAMLSpecExamples decodes the examples of chapter 2 of the YAML 1.2 specification
+// in testdata/yaml. The JSON file next to an example is its expected document,
+// the examples without one have no JSON equivalent and must be rejected with a YAMLError
+func TestDecodeYAMLSpecExamples(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "yaml", "*.yaml"))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, files)
+
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		actual, err := decodeYAML(bytes.NewReader(source))
+
+		expectedJSON, readErr := os.ReadFile(strings.TrimSuffix(file, ".yaml") + ".json")
+		if os.IsNotExist(readErr) {
+			var yamlErr *YAMLError
+			assert.True(t, errors.As(err, &yamlErr), "%s: expected a YAMLError, got %v", file, err)
+			continue
+		}
+		expected, jsonErr := decodeJSONUsingNumber(bytes.NewReader(expectedJSON))
+		if assert.Nil(t, jsonErr, file) && assert.Nil(t, err, file) {
+			assert.Equal(t, expected, actual, file)
+		}
+	}
+}
+
+// FuzzYAML checks that decodeYAML either fails with a YAMLError or returns a JSON document,
+// and that JSON, which YAML 1.2 is a superset of, decodes to the same document as decodeJSONUsingNumber
+func FuzzYAML(f *testing.F) {
+	files, _ := filepath.Glob(filepath.Join("testdata", "yaml", "*"))
+	for _, file := range files {
+		if source, err := os.ReadFile(file); err == nil {
+			f.Add(source)
+		}
+	}
+	for _, seed := range []string{
+		"a: &x [1, 2]\nb: *x\n",
+		"a: |+\n  one\n\n",
+		"{a: 1, b, c: [x, y,]}",
+		"- - a\n  - b\n- c: d\n",
+		`"a\tb\u00e9\ud83d\ude00"`,
+		`{"a" : [1, 2.5e3, -0, "x\"y", true, null], "b" : {}}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, source []byte) {
+		document, err := decodeYAML(bytes.NewReader(source))
+		if err != nil {
+			var yamlErr *YAMLError
+			if !errors.As(err, &yamlErr) {
+				t.Fatalf("%q: expected a YAMLError, got %v", source, err)
+			}
+		} else {
+			b, err := json.Marshal(document)
+			if err != nil {
+				t.Fatalf("%q: %#v is not a JSON document: %v", source, document, err)
+			}
+			again, err := decodeJSONUsingNumber(bytes.NewReader(b))
+			if err != nil || !reflect.DeepEqual(document, again) {
+				t.Fatalf("%q: %#v changes through JSON to %#v", source, document, again)
+			}
+		}
+
+		if err != nil || !json.Valid(source) {
+			return
+		}
+		expected, err := decodeJSONUsingNumber(bytes.NewReader(source))
+		if err != nil || !reflect.DeepEqual(expected, document) {
+			t.Fatalf("%q: decoded as %#v, JSON is %#v", source, document, expected)
+		}
+	})
+}
+
+func TestYAMLLoader(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, content string) {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	writeFile("schema.yaml", `
+type: object
+required: [name, address]
+properties:
+  name: {type: string}
+  address: {$ref: "address.yml"}
+`)
+	writeFile("address.yml", `
+# referenced from schema.yaml
+type: object
+properties:
+  zip:
+    type: string
+    pattern: ^[0-9]{5}$
+`)
+
+	schema, err := NewSchema(NewReferenceLoader("file://" + filepath.ToSlash(filepath.Join(dir, "schema.yaml"))))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	result, err := schema.Validate(NewYAMLLoader("name: Ada\naddress:\n  zip: '12345'\n"))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid(), "%v", result.Errors())
+
+	result, err = schema.Validate(NewYAMLLoader("name: 1\naddress:\n  zip: 12345\n"))
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"invalid_type (root).name", "invalid_type (root).address.zip"}, errorFields(result))
+
+	_, err = schema.Validate(NewYAMLLoader("name: [unterminated"))
+	var yamlErr *YAMLError
+	assert.True(t, errors.As(err, &yamlErr))
+
+	// Limits apply to YAML documents too
+	sl := NewSchemaLoader()
+	sl.Limits = Limits{MaxArrayLength: 2}
+	schema, err = sl.Compile(NewYAMLLoader("type: array"))
+	assert.Nil(t, err)
+	_, err = schema.Validate(NewYAMLLoader("[1, 2, 3]"))
+	var limitErr *LimitError
+	assert.True(t, errors.As(err, &limitErr))
+}
+
+func TestYAMLReferenceOverHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/schema":
+			w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+			w.Write([]byte("type: integer\nminimum: 1\n"))
+		case "/schema.json":
+			w.Write([]byte(`{"type" : "string"}`))
+		}
+	}))
+	defer server.Close()
+
+	schema, err := NewSchema(NewReferenceLoader(server.URL + "/schema"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	result, err := schema.Validate(NewStringLoader(`0`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"number_gte (root)"}, errorFields(result))
+
+	schema, err = NewSchema(NewReferenceLoader(server.URL + "/schema.json"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	result, err = schema.Validate(NewStringLoader(`"a"`))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid())
+}
+
+func TestIsYAMLMediaType(t *testing.T) {
+	assert.True(t, isYAMLMediaType("application/yaml"))
+	assert.True(t, isYAMLMediaType("text/x-yaml; charset=utf-8"))
+	assert.True(t, isYAMLMediaType("application/schema+yaml"))
+	assert.False(t, isYAMLMediaType("application/json"))
+	assert.False(t, isYAMLMediaType(""))
+}